@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"time"
+
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/api"
+)
+
+// alertList 列出告警
+func alertList(fs *flag.FlagSet) func(c *cli, args []string) error {
+	state := fs.String("state", "", "only alerts in this state: firing or resolved")
+	return func(c *cli, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		var filter alert.State
+		if *state != "" {
+			parsed, err := alert.ParseState(*state)
+			if err != nil {
+				return err
+			}
+			filter = parsed
+		}
+		alerts, err := c.client.ListAlerts(filter)
+		if err != nil {
+			return err
+		}
+		return c.print(alerts, func() error {
+			rows := make([][]string, 0, len(alerts))
+			for _, a := range alerts {
+				rows = append(rows, []string{
+					a.ID,
+					string(a.State),
+					orDash(a.Severity),
+					formatTime(a.StartsAt),
+					formatTime(a.EndsAt),
+					strconv.FormatBool(a.Silenced),
+					a.Summary,
+				})
+			}
+			return c.printTable([]string{"ID", "STATE", "SEVERITY", "STARTED", "RESOLVED", "SILENCED", "SUMMARY"}, rows)
+		})
+	}
+}
+
+// alertRules 列出告警规则
+func alertRules(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		rules, err := c.client.ListAlertRules()
+		if err != nil {
+			return err
+		}
+		return c.print(rules, nil)
+	}
+}
+
+// silenceList 列出静默
+func silenceList(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		silences, err := c.client.ListSilences()
+		if err != nil {
+			return err
+		}
+		return c.print(silences, func() error { return c.printSilences(silences) })
+	}
+}
+
+// silenceAdd 添加静默，默认匹配所有告警
+func silenceAdd(fs *flag.FlagSet) func(c *cli, args []string) error {
+	rule := fs.String("rule", "", "only silence alerts of this rule")
+	taskID := fs.String("task", "", "only silence alerts of this task")
+	duration := fs.Duration("d", time.Hour, "how long the silence lasts")
+	comment := fs.String("comment", "", "reason for the silence")
+	return func(c *cli, args []string) error {
+		if len(args) != 0 || *duration <= 0 {
+			return errUsage
+		}
+		silence, err := c.client.CreateSilence(api.CreateSilenceRequest{
+			Rule:      *rule,
+			TaskID:    *taskID,
+			Comment:   *comment,
+			CreatedBy: os.Getenv("USER"),
+			Duration:  alert.Duration(*duration),
+		})
+		if err != nil {
+			return err
+		}
+		return c.print(silence, func() error { return c.printSilences([]alert.Silence{*silence}) })
+	}
+}
+
+// silenceExpire 立即结束静默
+func silenceExpire(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		silenceID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		silence, err := c.client.ExpireSilence(silenceID)
+		if err != nil {
+			return err
+		}
+		return c.print(silence, func() error { return c.printSilences([]alert.Silence{*silence}) })
+	}
+}
+
+// printSilences 以表格输出静默
+func (c *cli) printSilences(silences []alert.Silence) error {
+	now := time.Now()
+	rows := make([][]string, 0, len(silences))
+	for _, silence := range silences {
+		state := "active"
+		switch {
+		case !now.Before(silence.EndsAt):
+			state = "expired"
+		case now.Before(silence.StartsAt):
+			state = "pending"
+		}
+		rows = append(rows, []string{
+			silence.ID,
+			silence.String(),
+			state,
+			formatTime(silence.StartsAt),
+			formatTime(silence.EndsAt),
+			orDash(silence.CreatedBy),
+			orDash(silence.Comment),
+		})
+	}
+	return c.printTable([]string{"ID", "MATCHERS", "STATE", "STARTS", "ENDS", "CREATED BY", "COMMENT"}, rows)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"task_scheduler/pkg/scheduler"
+)
+
+// cronNext 在本地计算cron表达式接下来的触发时间，不访问服务
+func cronNext(fs *flag.FlagSet) func(c *cli, args []string) error {
+	n := fs.Int("n", 5, "number of fire times to show")
+	return func(c *cli, args []string) error {
+		if len(args) != 1 || *n <= 0 {
+			return errUsage
+		}
+		fireTimes, err := scheduler.NextFireTimes(args[0], time.Now(), *n)
+		if err != nil {
+			return err
+		}
+		return c.print(fireTimes, func() error { return c.printFireTimes(fireTimes) })
+	}
+}
+
+// taskNext 查询任务接下来的计划触发时间，暂停的任务按恢复后的计划计算
+func taskNext(fs *flag.FlagSet) func(c *cli, args []string) error {
+	n := fs.Int("n", 5, "number of fire times to show")
+	from := fs.String("from", "", "compute fire times after this time (RFC3339, default now)")
+	return func(c *cli, args []string) error {
+		taskID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		if *n <= 0 {
+			return errUsage
+		}
+		start, err := parseOptionalTime("from", *from)
+		if err != nil {
+			return err
+		}
+		preview, err := c.client.PreviewTask(taskID, start, *n)
+		if err != nil {
+			return err
+		}
+		return c.print(preview, func() error { return c.printFireTimes(preview.FireTimes) })
+	}
+}
+
+// taskTimeline 查询各任务在一个时间段内合并后的计划触发，用于检查触发是否集中
+func taskTimeline(fs *flag.FlagSet) func(c *cli, args []string) error {
+	from := fs.String("from", "", "window start (RFC3339, default now)")
+	to := fs.String("to", "", "window end (RFC3339, exclusive)")
+	window := fs.Duration("d", 24*time.Hour, "window length when -to is not given")
+	tasks := fs.String("task", "", "comma separated task ids (default all tasks)")
+	paused := fs.Bool("paused", false, "include paused tasks")
+	limit := fs.Int("limit", 100, "maximum number of fires to show")
+	return func(c *cli, args []string) error {
+		if len(args) != 0 || *window <= 0 {
+			return errUsage
+		}
+		start, err := parseOptionalTime("from", *from)
+		if err != nil {
+			return err
+		}
+		end, err := parseOptionalTime("to", *to)
+		if err != nil {
+			return err
+		}
+		if start.IsZero() {
+			start = time.Now()
+		}
+		if end.IsZero() {
+			end = start.Add(*window)
+		}
+		opts := scheduler.TimelineOptions{IncludePaused: *paused, Limit: *limit}
+		if *tasks != "" {
+			opts.TaskIDs = strings.Split(*tasks, ",")
+		}
+
+		timeline, err := c.client.Timeline(start, end, opts)
+		if err != nil {
+			return err
+		}
+		return c.print(timeline, func() error {
+			if len(timeline.Fires) == 0 {
+				_, err := fmt.Fprintln(c.out, "No fires in window")
+				return err
+			}
+			rows := make([][]string, 0, len(timeline.Fires))
+			for _, fire := range timeline.Fires {
+				rows = append(rows, []string{fire.Time.Local().Format(timeLayout + " MST"), fire.TaskID})
+			}
+			if err := c.printTable([]string{"FIRE TIME", "TASK"}, rows); err != nil {
+				return err
+			}
+			if timeline.Truncated {
+				fmt.Fprintf(c.out, "Showing the first %d fires, raise -limit or narrow the window for more\n", len(timeline.Fires))
+			}
+			return nil
+		})
+	}
+}
+
+// printFireTimes 以表格输出触发时间
+func (c *cli) printFireTimes(fireTimes []time.Time) error {
+	if len(fireTimes) == 0 {
+		_, err := fmt.Fprintln(c.out, "Expression never fires")
+		return err
+	}
+	rows := make([][]string, 0, len(fireTimes))
+	for i, t := range fireTimes {
+		rows = append(rows, []string{strconv.Itoa(i + 1), t.Local().Format(timeLayout + " MST")})
+	}
+	return c.printTable([]string{"#", "FIRE TIME"}, rows)
+}
+
+// parseOptionalTime 解析可选的 RFC3339 时间参数，为空时返回零值
+func parseOptionalTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -%s: %v", name, err)
+	}
+	return t, nil
+}
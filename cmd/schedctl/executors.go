@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+
+	"task_scheduler/pkg/api"
+)
+
+// executorList 列出所有执行器
+func executorList(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		infos, err := c.client.ListExecutors()
+		if err != nil {
+			return err
+		}
+		return c.print(infos, func() error { return c.printExecutors(infos) })
+	}
+}
+
+// executorDrain 排空执行器：不再派发新运行，已派发的运行继续执行
+func executorDrain(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		executorID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		info, err := c.client.DrainExecutor(executorID)
+		if err != nil {
+			return err
+		}
+		return c.print(info, func() error { return c.printExecutors([]api.ExecutorInfo{*info}) })
+	}
+}
+
+// printExecutors 以表格输出执行器
+func (c *cli) printExecutors(infos []api.ExecutorInfo) error {
+	rows := make([][]string, 0, len(infos))
+	for _, info := range infos {
+		rows = append(rows, []string{
+			info.ID,
+			info.Address,
+			strconv.FormatBool(info.Healthy),
+			strconv.FormatBool(info.Draining),
+			strconv.FormatInt(info.InFlight, 10),
+			strconv.FormatInt(info.UsageCount, 10),
+			formatTime(info.LastUsedTime),
+		})
+	}
+	return c.printTable([]string{"ID", "ADDRESS", "HEALTHY", "DRAINING", "IN FLIGHT", "USAGE", "LAST USED"}, rows)
+}
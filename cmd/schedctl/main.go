@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"task_scheduler/pkg/client"
+)
+
+// defaultServer 未指定 -server 且未设置 SCHEDCTL_SERVER 时使用的服务地址
+const defaultServer = "http://localhost:8080"
+
+// errUsage 参数错误，退出码为2
+var errUsage = errors.New("usage error")
+
+// 用法：
+//
+//	schedctl [-server url] [-o table|json] <resource> <command> [args]
+//
+//	schedctl task list|get|apply|delete|pause|resume|trigger|backfill|next|timeline
+//	schedctl run list|logs|cancel
+//	schedctl executor list|drain
+//	schedctl alert list|rules
+//	schedctl silence list|add|expire
+//	schedctl cron next "<expr>" [-n 5]
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "schedctl: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(2)
+	}
+}
+
+// globalFlags 所有子命令共用的参数
+type globalFlags struct {
+	server string
+	output string
+}
+
+// register 注册共用参数，子命令中的同名参数覆盖全局位置给出的值
+func (g *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.server, "server", g.server, "scheduler API address (env SCHEDCTL_SERVER)")
+	fs.StringVar(&g.output, "o", g.output, "output format: table or json")
+}
+
+// cli 子命令的执行环境
+type cli struct {
+	client *client.Client
+	output string
+	out    io.Writer
+}
+
+// command 子命令
+type command struct {
+	usage string
+	// setup 注册子命令参数，返回解析完成后执行的函数
+	setup func(fs *flag.FlagSet) func(c *cli, args []string) error
+}
+
+// commands 按“资源 命令”索引的子命令
+var commands = map[string]command{
+	"task list":      {"task list", taskList},
+	"task get":       {"task get <id>", taskGet},
+	"task apply":     {"task apply -f <file|->", taskApply},
+	"task delete":    {"task delete <id>", taskDelete},
+	"task pause":     {"task pause <id>", taskPause},
+	"task resume":    {"task resume <id>", taskResume},
+	"task trigger":   {"task trigger <id>", taskTrigger},
+	"task backfill":  {"task backfill -from t [-to t] [-parallelism n] [-max-runs n] [-wait] <id>", taskBackfill},
+	"task next":      {"task next [-n 5] [-from t] <id>", taskNext},
+	"task timeline":  {"task timeline [-from t] [-to t | -d 24h] [-task id1,id2] [-paused] [-limit n]", taskTimeline},
+	"run list":       {"run list [-task id] [-executor id] [-status s1,s2] [-from t] [-to t] [-limit n]", runList},
+	"run logs":       {"run logs [-tail n] [-f] <id>", runLogs},
+	"run cancel":     {"run cancel <id>", runCancel},
+	"executor list":  {"executor list", executorList},
+	"executor drain": {"executor drain <id>", executorDrain},
+	"alert list":     {"alert list [-state firing|resolved]", alertList},
+	"alert rules":    {"alert rules", alertRules},
+	"silence list":   {"silence list", silenceList},
+	"silence add":    {"silence add [-rule name] [-task id] [-d 1h] [-comment text]", silenceAdd},
+	"silence expire": {"silence expire <id>", silenceExpire},
+	"cron next":      {"cron next <expr> [-n 5]", cronNext},
+}
+
+// run 解析参数并执行子命令
+func run(args []string, out io.Writer) error {
+	global := &globalFlags{server: os.Getenv("SCHEDCTL_SERVER"), output: "table"}
+	if global.server == "" {
+		global.server = defaultServer
+	}
+
+	fs := flag.NewFlagSet("schedctl", flag.ContinueOnError)
+	global.register(fs)
+	fs.Usage = func() { printUsage(fs.Output()) }
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() < 2 {
+		printUsage(os.Stderr)
+		return errUsage
+	}
+
+	name := fs.Arg(0) + " " + fs.Arg(1)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		printUsage(os.Stderr)
+		return errUsage
+	}
+
+	sub := flag.NewFlagSet("schedctl "+name, flag.ContinueOnError)
+	global.register(sub)
+	exec := cmd.setup(sub)
+	sub.Usage = func() {
+		fmt.Fprintf(sub.Output(), "usage: schedctl %s\n", cmd.usage)
+		sub.PrintDefaults()
+	}
+	positional, err := parseInterspersed(sub, fs.Args()[2:])
+	if err != nil {
+		return errUsage
+	}
+	if global.output != "table" && global.output != "json" {
+		fmt.Fprintf(os.Stderr, "invalid output format %q, expected table or json\n", global.output)
+		return errUsage
+	}
+
+	c := &cli{client: client.New(global.server), output: global.output, out: out}
+	if err := exec(c, positional); err != nil {
+		if errors.Is(err, errUsage) {
+			sub.Usage()
+		}
+		return err
+	}
+	return nil
+}
+
+// parseInterspersed 解析参数，允许参数出现在位置参数之后
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// requireID 检查子命令恰好有一个ID参数
+func requireID(args []string) (string, error) {
+	if len(args) != 1 || args[0] == "" {
+		return "", errUsage
+	}
+	return args[0], nil
+}
+
+// printUsage 输出命令列表
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: schedctl [-server url] [-o table|json] <resource> <command> [args]")
+	fmt.Fprintln(w)
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  schedctl %s\n", commands[name].usage)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "The server defaults to $SCHEDCTL_SERVER or %s.\n", defaultServer)
+	fmt.Fprintln(w, "Flags may be given before the resource or after the command.")
+}
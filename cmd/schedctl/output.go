@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// timeLayout 表格中时间的显示格式
+const timeLayout = "2006-01-02 15:04:05"
+
+// printJSON 以缩进的JSON输出
+func (c *cli) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(c.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printTable 以对齐的表格输出，rows中每行的列数与header一致
+func (c *cli) printTable(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// print 按输出格式输出，table为nil时总是输出JSON
+func (c *cli) print(v interface{}, table func() error) error {
+	if c.output == "json" || table == nil {
+		return c.printJSON(v)
+	}
+	return table()
+}
+
+// formatTime 格式化时间，零值显示为 -
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(timeLayout)
+}
+
+// formatDuration 格式化运行耗时，未开始或未结束时显示为 -
+func formatDuration(start, end time.Time) string {
+	if start.IsZero() || end.IsZero() {
+		return "-"
+	}
+	return end.Sub(start).Round(time.Millisecond).String()
+}
+
+// orDash 空字符串显示为 -
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"task_scheduler/pkg/client"
+	"task_scheduler/pkg/types"
+)
+
+// runList 按条件查询运行历史
+func runList(fs *flag.FlagSet) func(c *cli, args []string) error {
+	taskID := fs.String("task", "", "only runs of this task")
+	executorID := fs.String("executor", "", "only runs on this executor")
+	statuses := fs.String("status", "", "comma separated run statuses, e.g. failed,lost")
+	from := fs.String("from", "", "fire time lower bound (RFC3339, inclusive)")
+	to := fs.String("to", "", "fire time upper bound (RFC3339, exclusive)")
+	offset := fs.Int("offset", 0, "number of runs to skip")
+	limit := fs.Int("limit", 20, "maximum number of runs to show")
+	return func(c *cli, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		query := types.RunQuery{TaskID: *taskID, ExecutorID: *executorID, Offset: *offset, Limit: *limit}
+		if *statuses != "" {
+			for _, name := range strings.Split(*statuses, ",") {
+				status, err := types.ParseRunStatus(strings.TrimSpace(name))
+				if err != nil {
+					return err
+				}
+				query.Statuses = append(query.Statuses, status)
+			}
+		}
+		for _, bound := range []struct {
+			name   string
+			value  string
+			target *time.Time
+		}{{"from", *from, &query.From}, {"to", *to, &query.To}} {
+			if bound.value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, bound.value)
+			if err != nil {
+				return fmt.Errorf("invalid -%s: %v", bound.name, err)
+			}
+			*bound.target = t
+		}
+
+		page, err := c.client.ListRuns(query)
+		if err != nil {
+			return err
+		}
+		return c.print(page, func() error {
+			if err := c.printRuns(page.Runs); err != nil {
+				return err
+			}
+			if shown := query.Offset + len(page.Runs); shown < page.Total {
+				fmt.Fprintf(c.out, "Showing %d of %d runs, use -offset %d for more\n", len(page.Runs), page.Total, shown)
+			}
+			return nil
+		})
+	}
+}
+
+// runLogs 输出运行日志
+func runLogs(fs *flag.FlagSet) func(c *cli, args []string) error {
+	tail := fs.Int("tail", 0, "only show the last n lines")
+	follow := fs.Bool("f", false, "keep streaming new lines until the run finishes")
+	return func(c *cli, args []string) error {
+		runID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		return c.client.StreamRunLogs(runID, client.RunLogOptions{Tail: *tail, Follow: *follow}, c.out)
+	}
+}
+
+// runCancel 取消执行中的运行
+func runCancel(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		runID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		run, err := c.client.CancelRun(runID)
+		if err != nil {
+			return err
+		}
+		return c.print(run, func() error {
+			_, err := fmt.Fprintf(c.out, "Cancellation requested for run %s\n", runID)
+			return err
+		})
+	}
+}
+
+// printRuns 以表格输出运行记录
+func (c *cli) printRuns(runs []*types.Run) error {
+	rows := make([][]string, 0, len(runs))
+	for _, run := range runs {
+		rows = append(rows, []string{
+			run.ID,
+			run.TaskID,
+			orDash(run.ExecutorID),
+			run.Status.String(),
+			orDash(string(run.Trigger)),
+			strconv.Itoa(run.Attempt),
+			formatTime(run.FireTime),
+			formatDuration(run.StartTime, run.EndTime),
+			orDash(run.Error),
+		})
+	}
+	return c.printTable([]string{"ID", "TASK", "EXECUTOR", "STATUS", "TRIGGER", "ATTEMPT", "FIRE TIME", "DURATION", "ERROR"}, rows)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"task_scheduler/pkg/api"
+	"task_scheduler/pkg/types"
+)
+
+// taskList 列出所有任务
+func taskList(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		tasks, err := c.client.ListTasks()
+		if err != nil {
+			return err
+		}
+		return c.print(tasks, func() error { return c.printTasks(tasks) })
+	}
+}
+
+// taskGet 查看任务
+func taskGet(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		taskID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		task, err := c.client.GetTask(taskID)
+		if err != nil {
+			return err
+		}
+		// 任务参数不适合表格展示，单个任务总是输出JSON
+		return c.printJSON(task)
+	}
+}
+
+// taskApply 从文件创建或替换任务，文件内容为单个任务或任务数组
+func taskApply(fs *flag.FlagSet) func(c *cli, args []string) error {
+	file := fs.String("f", "", "task definition file in JSON, - for stdin")
+	return func(c *cli, args []string) error {
+		if *file == "" || len(args) != 0 {
+			return errUsage
+		}
+		tasks, err := readTasks(*file)
+		if err != nil {
+			return err
+		}
+
+		applied := make([]*types.Task, 0, len(tasks))
+		for _, task := range tasks {
+			result, err := c.client.ApplyTask(task)
+			if err != nil {
+				return fmt.Errorf("task %s: %w", task.ID, err)
+			}
+			applied = append(applied, result)
+		}
+		return c.print(applied, func() error { return c.printTasks(applied) })
+	}
+}
+
+// taskDelete 删除任务
+func taskDelete(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		taskID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		if err := c.client.DeleteTask(taskID); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Task %s deleted\n", taskID)
+		return nil
+	}
+}
+
+// taskPause 暂停任务
+func taskPause(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		taskID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		task, err := c.client.PauseTask(taskID)
+		if err != nil {
+			return err
+		}
+		return c.print(task, func() error { return c.printTasks([]*types.Task{task}) })
+	}
+}
+
+// taskResume 恢复任务
+func taskResume(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		taskID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		task, err := c.client.ResumeTask(taskID)
+		if err != nil {
+			return err
+		}
+		return c.print(task, func() error { return c.printTasks([]*types.Task{task}) })
+	}
+}
+
+// taskTrigger 立即触发任务一次
+func taskTrigger(fs *flag.FlagSet) func(c *cli, args []string) error {
+	return func(c *cli, args []string) error {
+		taskID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		run, err := c.client.TriggerTask(taskID)
+		if err != nil {
+			return err
+		}
+		return c.print(run, func() error { return c.printRuns([]*types.Run{run}) })
+	}
+}
+
+// taskBackfill 在服务端回填任务在时间段内的每一次cron触发
+func taskBackfill(fs *flag.FlagSet) func(c *cli, args []string) error {
+	from := fs.String("from", "", "window start (RFC3339)")
+	to := fs.String("to", "", "window end (RFC3339, default now)")
+	parallelism := fs.Int("parallelism", 1, "maximum number of backfill runs in flight")
+	maxRuns := fs.Int("max-runs", 0, "refuse windows with more fires than this (0 uses the server default)")
+	wait := fs.Bool("wait", false, "wait for the backfill to finish and print the result")
+	return func(c *cli, args []string) error {
+		taskID, err := requireID(args)
+		if err != nil {
+			return err
+		}
+		if *from == "" {
+			return errUsage
+		}
+		start, err := parseOptionalTime("from", *from)
+		if err != nil {
+			return err
+		}
+		end, err := parseOptionalTime("to", *to)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.client.Backfill(taskID, api.BackfillRequest{
+			From:           start,
+			To:             end,
+			MaxParallelism: *parallelism,
+			MaxRuns:        *maxRuns,
+			Wait:           *wait,
+		})
+		if err != nil {
+			return err
+		}
+		if err := c.print(resp, func() error {
+			if resp.Result == nil {
+				_, err := fmt.Fprintf(c.out, "Backfilling %s: %d runs scheduled, follow with \"schedctl run list -task %s\"\n",
+					taskID, len(resp.FireTimes), taskID)
+				return err
+			}
+			result := resp.Result
+			_, err := fmt.Fprintf(c.out, "Backfilled %s: %d runs, %d succeeded, %d failed, %d skipped\n",
+				taskID, result.Total, result.Succeeded, result.Failed, result.Skipped)
+			return err
+		}); err != nil {
+			return err
+		}
+		if resp.Result != nil && resp.Result.Failed > 0 {
+			return fmt.Errorf("%d of %d backfill runs failed", resp.Result.Failed, resp.Result.Total)
+		}
+		return nil
+	}
+}
+
+// readTasks 读取任务定义文件
+func readTasks(path string) ([]*types.Task, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var tasks []*types.Task
+	if bytes.HasPrefix(data, []byte("[")) {
+		err = decoder.Decode(&tasks)
+	} else {
+		var task types.Task
+		err = decoder.Decode(&task)
+		tasks = append(tasks, &task)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid task definition %s: %v", path, err)
+	}
+	for i, task := range tasks {
+		if task == nil || task.ID == "" {
+			return nil, fmt.Errorf("invalid task definition %s: task %d has no id", path, i)
+		}
+	}
+	return tasks, nil
+}
+
+// printTasks 以表格输出任务
+func (c *cli) printTasks(tasks []*types.Task) error {
+	rows := make([][]string, 0, len(tasks))
+	for _, task := range tasks {
+		concurrency := task.MaxConcurrency
+		if concurrency == 0 {
+			concurrency = 1
+		}
+		rows = append(rows, []string{
+			task.ID,
+			orDash(task.Name),
+			task.Cron,
+			task.Strategy.String(),
+			task.Status.String(),
+			strconv.Itoa(concurrency),
+			formatTime(task.LastRunTime),
+			formatTime(task.NextRunTime),
+		})
+	}
+	return c.printTable([]string{"ID", "NAME", "CRON", "STRATEGY", "STATUS", "CONCURRENCY", "LAST RUN", "NEXT RUN"}, rows)
+}
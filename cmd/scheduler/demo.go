@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"task_scheduler/pkg/executor"
+	"task_scheduler/pkg/types"
+)
+
+// demoExecutors 示例执行器
+func demoExecutors() []types.Executor {
+	return []types.Executor{
+		executor.NewSimpleExecutor("executor-1", "http://localhost:8001"),
+		executor.NewSimpleExecutor("executor-2", "http://localhost:8002"),
+		executor.NewSimpleExecutor("executor-3", "http://localhost:8003"),
+	}
+}
+
+// demoTasks 不同策略的示例任务
+func demoTasks() []*types.Task {
+	return []*types.Task{
+		{
+			ID:       "order-timeout-check",
+			Name:     "订单超时检查",
+			Cron:     "0 */1 * * * *", // 每分钟执行一次
+			Handler:  "orderTimeoutHandler",
+			Params:   map[string]interface{}{"timeout": 30},
+			Strategy: types.RoundRobinTask, // 任务级别轮询
+		},
+		{
+			ID:       "risk-monitoring",
+			Name:     "风险监控",
+			Cron:     "0 */1 * * * *", // 每分钟执行一次
+			Handler:  "riskMonitoringHandler",
+			Params:   map[string]interface{}{"threshold": 100},
+			Strategy: types.Random, // 随机路由
+		},
+		{
+			ID:       "data-sync",
+			Name:     "数据同步",
+			Cron:     "0 0 2 * * *", // 每天凌晨2点执行
+			Handler:  "dataSyncHandler",
+			Params:   map[string]interface{}{"tables": []string{"inventory", "stores"}},
+			Strategy: types.LFU, // 最少使用优先
+		},
+		{
+			ID:       "cache-cleanup",
+			Name:     "缓存清理",
+			Cron:     "0 */30 * * * *", // 每30分钟执行一次
+			Handler:  "cacheCleanupHandler",
+			Params:   map[string]interface{}{"max_age": 3600},
+			Strategy: types.LRU, // 最久未使用优先
+		},
+		{
+			ID:       "health-check",
+			Name:     "健康检查",
+			Cron:     "0 */5 * * * *", // 每5分钟执行一次
+			Handler:  "healthCheckHandler",
+			Params:   map[string]interface{}{"services": []string{"api", "db", "cache"}},
+			Strategy: types.RoundRobinApp, // 应用级别轮询
+		},
+	}
+}
+
+// printStrategyDescription 打印策略说明
+func printStrategyDescription() {
+	log.Println("\n=== 路由策略说明 ===")
+	fmt.Println(`
+根据文章描述，不同路由策略的特点：
+
+1. 任务级别轮询 (RoundRobinTask):
+   - 每个任务维护独立计数器
+   - 适合任务调度时间一致的场景
+   - 初始化时随机，避免首次压力集中
+
+2. 应用级别轮询 (RoundRobinApp):
+   - 所有任务共享一个计数器
+   - 保证所有执行器接收任务次数平均
+   - 适合任务负载和执行时间相近的场景
+
+3. 随机路由 (Random):
+   - 完全随机选择执行器
+   - 长期看负载均衡，短期可能不均
+   - 实现简单，适合对均衡要求不严格的场景
+
+4. 最近最少使用 (LFU):
+   - 优先选择该任务自身使用次数最少的执行器
+   - 只平衡单个任务自身的选择
+   - 混合策略场景请使用全局LFU (GlobalLFU)，基于执行器全局使用次数选择
+
+5. 最近最久未使用 (LRU):
+   - 优先选择最久未使用的执行器
+   - 基于时间的负载均衡
+   - 适合需要考虑时间因素的调度场景
+
+建议：
+- 任务负载相近：使用应用级别轮询
+- 存在大小任务：大任务用任务级轮询，小任务用其他策略
+- 混合策略场景：新任务使用GlobalLFU策略平衡负载`)
+}
@@ -1,196 +1,115 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"task_scheduler/pkg/executor"
-	"task_scheduler/pkg/scheduler"
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/api"
+	"task_scheduler/pkg/notify"
 	"task_scheduler/pkg/types"
+	"task_scheduler/pkg/web"
 )
 
+// 用法：
+//
+//	scheduler [-addr :8080] [-store dir] [-wal path] [-executors id=addr,...] [-remote] [-trace stdout|url]
+//	          [-log-level info] [-log-format text|json] [-lfu-half-life d] [-run-log-dir dir] [-alert-rules file] [-notify file] [-demo]
+//	scheduler backfill -task <id> -from <time> [-to <time>] [-parallelism n] [-store dir]   (offline, see schedctl task backfill)
+//	scheduler strategies
 func main() {
-	// 创建调度器配置
-	config := &types.SchedulerConfig{
-		MaxConcurrentTasks:  5,
-		HealthCheckInterval: 30 * time.Second,
-		DefaultStrategy:     types.RoundRobinApp,
-	}
-
-	// 创建任务调度器
-	ts := scheduler.New(config)
-
-	// 添加执行器
-	executors := []*executor.SimpleExecutor{
-		executor.NewSimpleExecutor("executor-1", "http://localhost:8001"),
-		executor.NewSimpleExecutor("executor-2", "http://localhost:8002"),
-		executor.NewSimpleExecutor("executor-3", "http://localhost:8003"),
-	}
-
-	for _, exec := range executors {
-		err := ts.AddExecutor(exec)
-		if err != nil {
-			log.Fatalf("Failed to add executor %s: %v", exec.GetID(), err)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backfill":
+			if err := runBackfill(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "strategies":
+			printStrategyDescription()
+			return
 		}
-		log.Printf("Added executor: %s", exec.GetID())
 	}
 
-	// 创建不同策略的任务
-	tasks := []*types.Task{
-		{
-			ID:       "order-timeout-check",
-			Name:     "订单超时检查",
-			Cron:     "0 */1 * * * *", // 每分钟执行一次
-			Handler:  "orderTimeoutHandler",
-			Params:   map[string]interface{}{"timeout": 30},
-			Strategy: types.RoundRobinTask, // 任务级别轮询
-		},
-		{
-			ID:       "risk-monitoring",
-			Name:     "风险监控",
-			Cron:     "0 */1 * * * *", // 每分钟执行一次
-			Handler:  "riskMonitoringHandler",
-			Params:   map[string]interface{}{"threshold": 100},
-			Strategy: types.Random, // 随机路由
-		},
-		{
-			ID:       "data-sync",
-			Name:     "数据同步",
-			Cron:     "0 0 2 * * *", // 每天凌晨2点执行
-			Handler:  "dataSyncHandler",
-			Params:   map[string]interface{}{"tables": []string{"inventory", "stores"}},
-			Strategy: types.LFU, // 最少使用优先
-		},
-		{
-			ID:       "cache-cleanup",
-			Name:     "缓存清理",
-			Cron:     "0 */30 * * * *", // 每30分钟执行一次
-			Handler:  "cacheCleanupHandler",
-			Params:   map[string]interface{}{"max_age": 3600},
-			Strategy: types.LRU, // 最久未使用优先
-		},
-		{
-			ID:       "health-check",
-			Name:     "健康检查",
-			Cron:     "0 */5 * * * *", // 每5分钟执行一次
-			Handler:  "healthCheckHandler",
-			Params:   map[string]interface{}{"services": []string{"api", "db", "cache"}},
-			Strategy: types.RoundRobinApp, // 应用级别轮询
-		},
+	fs := flag.NewFlagSet("scheduler", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "listen address of the management API")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 10*time.Second, "time to wait for in-flight API requests on shutdown")
+	alertRules := fs.String("alert-rules", "", "JSON file with alert rules (alerting disabled when empty)")
+	alertInterval := fs.Duration("alert-interval", alert.DefaultInterval, "how often alert rules are evaluated")
+	notifyConfig := fs.String("notify", "", "JSON file with notification channels and routes (disabled when empty)")
+	opts := registerSchedulerFlags(fs)
+	fs.Parse(os.Args[1:])
+
+	ts, cleanup := opts.newScheduler()
+	defer cleanup()
+	// 在启动前订阅，补跑的运行也会产生通知
+	var dispatcher *notify.Dispatcher
+	if *notifyConfig != "" {
+		dispatcher = newDispatcher(ts, *notifyConfig)
 	}
 
-	// 添加任务到调度器
-	for _, task := range tasks {
-		err := ts.AddTask(task)
-		if err != nil {
-			log.Fatalf("Failed to add task %s: %v", task.ID, err)
-		}
-		log.Printf("Added task: %s with strategy %v", task.Name, task.Strategy)
-	}
-
-	// 启动调度器
-	err := ts.Start()
-	if err != nil {
+	if err := ts.Start(); err != nil {
+		// log.Fatalf 不执行延迟调用，先关闭存储以释放目录锁
+		cleanup()
 		log.Fatalf("Failed to start scheduler: %v", err)
 	}
 
-	log.Println("=== 任务调度器启动成功 ===")
-	log.Println("演示不同的路由策略效果...")
-
-	// 运行一段时间来观察调度效果
-	time.Sleep(2 * time.Minute)
-
-	// 打印统计信息
-	printStats(ts)
-
-	// 演示手动执行任务
-	log.Println("\n=== 演示手动任务执行 ===")
-	manualTask := &types.Task{
-		ID:       "manual-task",
-		Name:     "手动任务",
-		Handler:  "manualHandler",
-		Params:   map[string]interface{}{"type": "manual"},
-		Strategy: types.Random,
+	var serverOpts []api.Option
+	if *opts.remote {
+		serverOpts = append(serverOpts, api.WithExecutorFactory(func(id, address string) (types.Executor, error) {
+			return opts.newExecutor(id, address), nil
+		}))
 	}
-
-	// 手动执行任务几次，观察不同策略的效果
-	for i := 0; i < 10; i++ {
-		executors := ts.GetExecutors()
-		if len(executors) > 0 {
-			executor, err := ts.GetRouter().Route(manualTask, executors)
-			if err == nil {
-				log.Printf("Manual task %d routed to executor: %s", i+1, executor.GetID())
-				executor.Execute(manualTask)
-			}
+	var alerts *alert.Manager
+	if *alertRules != "" {
+		alerts = newAlertManager(ts, *alertRules, *alertInterval, dispatcher)
+		alerts.Start()
+		serverOpts = append(serverOpts, api.WithAlerts(alerts))
+	}
+	handler := api.NewServer(ts, serverOpts...)
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// 关闭时结束事件流，否则 Shutdown 会一直等待这些长连接
+	server.RegisterOnShutdown(handler.Close)
+	go func() {
+		log.Printf("Management API listening on %s, dashboard at %s", *addr, web.PathPrefix)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Management API failed: %v", err)
 		}
-		time.Sleep(500 * time.Millisecond)
+	}()
+
+	// 等待退出信号
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-signals
+	log.Printf("Received %v, shutting down", sig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down management API: %v", err)
 	}
-
-	// 再次打印统计信息
-	log.Println("\n=== 最终统计信息 ===")
-	printStats(ts)
-
-	// 停止调度器
-	err = ts.Stop()
-	if err != nil {
-		log.Printf("Error stopping scheduler: %v", err)
-	} else {
-		log.Println("任务调度器已停止")
+	if alerts != nil {
+		alerts.Stop()
 	}
-}
-
-// printStats 打印统计信息
-func printStats(ts *scheduler.TaskScheduler) {
-	log.Println("\n=== 任务统计信息 ===")
-	taskStats := ts.GetTaskStats()
-	for key, value := range taskStats {
-		log.Printf("%s: %v", key, value)
+	if err := ts.Stop(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error stopping scheduler: %v\n", err)
 	}
-
-	log.Println("\n=== 执行器统计信息 ===")
-	executorStats := ts.GetExecutorStats()
-	for _, stat := range executorStats {
-		log.Printf("Executor %s: 使用次数=%d, 最后使用时间=%v, 健康状态=%v",
-			stat.ID, stat.UsageCount, stat.LastUsedTime.Format("15:04:05"), stat.IsHealthy)
+	if dispatcher != nil {
+		// 投递停机前产生的通知，超时后不再重试
+		notifyCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		defer cancel()
+		dispatcher.Close(notifyCtx)
 	}
-
-	printStrategyDescription()
-}
-
-// printStrategyDescription 打印策略说明
-func printStrategyDescription() {
-	log.Println("\n=== 路由策略说明 ===")
-	fmt.Println(`
-根据文章描述，不同路由策略的特点：
-
-1. 任务级别轮询 (RoundRobinTask):
-   - 每个任务维护独立计数器
-   - 适合任务调度时间一致的场景
-   - 初始化时随机，避免首次压力集中
-
-2. 应用级别轮询 (RoundRobinApp):
-   - 所有任务共享一个计数器
-   - 保证所有执行器接收任务次数平均
-   - 适合任务负载和执行时间相近的场景
-
-3. 随机路由 (Random):
-   - 完全随机选择执行器
-   - 长期看负载均衡，短期可能不均
-   - 实现简单，适合对均衡要求不严格的场景
-
-4. 最近最少使用 (LFU):
-   - 优先选择使用次数最少的执行器
-   - 适合混合多种路由策略的场景
-   - 能有效平衡不同策略导致的负载不均
-
-5. 最近最久未使用 (LRU):
-   - 优先选择最久未使用的执行器
-   - 基于时间的负载均衡
-   - 适合需要考虑时间因素的调度场景
-
-建议：
-- 任务负载相近：使用应用级别轮询
-- 存在大小任务：大任务用任务级轮询，小任务用其他策略
-- 混合策略场景：新任务使用LFU策略平衡负载`)
 }
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/executor"
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/notify"
+	"task_scheduler/pkg/runlog"
+	"task_scheduler/pkg/scheduler"
+	"task_scheduler/pkg/store"
+	"task_scheduler/pkg/tracing"
+	"task_scheduler/pkg/types"
+)
+
+// schedulerFlags 服务与子命令共用的调度器配置
+type schedulerFlags struct {
+	storeDir  *string
+	walPath   *string
+	executors *string
+	remote    *bool
+	trace     *string
+	logLevel  *string
+	logFormat *string
+	halfLife  *time.Duration
+	runLogs   runLogFlags
+	demo      *bool
+}
+
+// runLogFlags 运行日志存储的配置
+type runLogFlags struct {
+	dir      *string
+	maxBytes *int
+	maxAge   *time.Duration
+}
+
+// registerSchedulerFlags 注册调度器配置参数
+func registerSchedulerFlags(fs *flag.FlagSet) *schedulerFlags {
+	return &schedulerFlags{
+		storeDir:  fs.String("store", "", "file store directory (in-memory when empty)"),
+		walPath:   fs.String("wal", "", "write-ahead log path (disabled when empty)"),
+		executors: fs.String("executors", "", "comma separated executors as id=address"),
+		remote:    fs.Bool("remote", false, "dispatch runs to executors over HTTP instead of simulating them"),
+		trace:     fs.String("trace", "", `export run traces to "stdout" or an OTLP/HTTP collector URL (disabled when empty)`),
+		logLevel:  fs.String("log-level", "info", "minimum log level: debug, info, warn or error"),
+		logFormat: fs.String("log-format", "text", "log output format: text or json"),
+		halfLife:  fs.Duration("lfu-half-life", 0, "half-life of the global LFU usage frequency (no decay when 0)"),
+		runLogs: runLogFlags{
+			dir:      fs.String("run-log-dir", "", "directory for logs of finished runs (kept in memory when empty)"),
+			maxBytes: fs.Int("run-log-max-bytes", runlog.DefaultMaxBytesPerRun, "log bytes kept per run, oldest lines are dropped first"),
+			maxAge:   fs.Duration("run-log-max-age", 7*24*time.Hour, "how long logs of finished runs are kept (0 keeps the newest runs only)"),
+		},
+		demo: fs.Bool("demo", false, "add the demo executors and tasks"),
+	}
+}
+
+// newScheduler 按参数创建调度器，返回关闭存储并导出剩余跨度的函数
+func (f *schedulerFlags) newScheduler() (*scheduler.TaskScheduler, func()) {
+	config := &types.SchedulerConfig{
+		MaxConcurrentTasks:      5,
+		HealthCheckInterval:     30 * time.Second,
+		DefaultStrategy:         types.RoundRobinApp,
+		WALPath:                 *f.walPath,
+		RouterGlobalLFUHalfLife: *f.halfLife,
+		Logger:                  f.newLogger(),
+	}
+	runLogs, err := runlog.NewStore(runlog.Options{
+		MaxBytesPerRun: *f.runLogs.maxBytes,
+		MaxAge:         *f.runLogs.maxAge,
+		Dir:            *f.runLogs.dir,
+	})
+	if err != nil {
+		log.Fatalf("Failed to open run log store: %v", err)
+	}
+
+	closeStore := func() {}
+	if *f.storeDir != "" {
+		fileStore, err := store.NewFileStore(*f.storeDir)
+		if errors.Is(err, store.ErrLocked) {
+			log.Fatalf("Failed to open store: %v; stop the scheduler using it, or backfill through it with \"schedctl task backfill\"", err)
+		}
+		if err != nil {
+			log.Fatalf("Failed to open store: %v", err)
+		}
+		config.Store = fileStore
+		closeStore = func() {
+			if err := fileStore.Close(); err != nil {
+				log.Printf("Failed to close store: %v", err)
+			}
+		}
+	}
+	tracer := f.newTracer()
+	cleanup := func() {
+		tracer.Shutdown()
+		closeStore()
+	}
+	// log.Fatalf 不执行延迟调用，存储打开后出错时先关闭存储以释放目录锁
+	fatalf := func(format string, args ...interface{}) {
+		cleanup()
+		log.Fatalf(format, args...)
+	}
+
+	ts := scheduler.New(config, scheduler.WithTracer(tracer), scheduler.WithRunLogs(runLogs))
+
+	var executors []types.Executor
+	if *f.demo {
+		executors = append(executors, demoExecutors()...)
+	}
+	for _, spec := range strings.Split(*f.executors, ",") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		id, address, ok := strings.Cut(spec, "=")
+		if !ok || id == "" || address == "" {
+			fatalf("Invalid executor %q, expected id=address", spec)
+		}
+		executors = append(executors, f.newExecutor(id, address))
+	}
+	for _, exec := range executors {
+		if err := ts.AddExecutor(exec); err != nil {
+			fatalf("Failed to add executor %s: %v", exec.GetID(), err)
+		}
+		log.Printf("Added executor: %s", exec.GetID())
+	}
+
+	if *f.demo {
+		for _, task := range demoTasks() {
+			if err := ts.AddTask(task); err != nil {
+				fatalf("Failed to add task %s: %v", task.ID, err)
+			}
+			log.Printf("Added task: %s with strategy %v", task.Name, task.Strategy)
+		}
+	}
+	return ts, cleanup
+}
+
+// newAlertManager 加载告警规则文件并创建告警管理器，配置了通知时告警发送到分发器
+func newAlertManager(ts *scheduler.TaskScheduler, rulesPath string, interval time.Duration, dispatcher *notify.Dispatcher) *alert.Manager {
+	file, err := os.Open(rulesPath)
+	if err != nil {
+		log.Fatalf("Failed to open alert rules: %v", err)
+	}
+	defer file.Close()
+	rules, err := alert.ParseRules(file)
+	if err != nil {
+		log.Fatalf("Failed to load alert rules from %s: %v", rulesPath, err)
+	}
+	opts := []alert.Option{alert.WithInterval(interval)}
+	if dispatcher != nil {
+		opts = append(opts, alert.WithHandler(dispatcher.HandleAlert))
+	}
+	manager, err := alert.NewManager(ts, rules, opts...)
+	if err != nil {
+		log.Fatalf("Failed to create alert manager: %v", err)
+	}
+	return manager
+}
+
+// newDispatcher 加载通知配置并创建分发器，按路由投递运行结束通知
+func newDispatcher(ts *scheduler.TaskScheduler, configPath string) *notify.Dispatcher {
+	file, err := os.Open(configPath)
+	if err != nil {
+		log.Fatalf("Failed to open notification config: %v", err)
+	}
+	defer file.Close()
+	config, err := notify.ParseConfig(file)
+	if err != nil {
+		log.Fatalf("Failed to load notification config from %s: %v", configPath, err)
+	}
+	dispatcher, err := config.NewDispatcher(nil)
+	if err != nil {
+		log.Fatalf("Failed to create notification dispatcher: %v", err)
+	}
+	dispatcher.WatchRuns(ts)
+	return dispatcher
+}
+
+// newExecutor 按参数创建执行器：-remote 时通过 HTTP 派发，否则模拟执行
+func (f *schedulerFlags) newExecutor(id, address string) types.Executor {
+	if *f.remote {
+		return executor.NewHTTPExecutor(id, address)
+	}
+	return executor.NewSimpleExecutor(id, address)
+}
+
+// newLogger 按 -log-level 和 -log-format 创建日志记录器，并设为默认记录器，
+// 使 log 包和其他组件的日志使用相同的格式
+func (f *schedulerFlags) newLogger() *slog.Logger {
+	level, err := logging.ParseLevel(*f.logLevel)
+	if err != nil {
+		log.Fatalf("Invalid -log-level: %v", err)
+	}
+	format, err := logging.ParseFormat(*f.logFormat)
+	if err != nil {
+		log.Fatalf("Invalid -log-format: %v", err)
+	}
+	logger := logging.New(os.Stderr, format, level)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// newTracer 按 -trace 参数创建追踪器，未配置时返回nil
+func (f *schedulerFlags) newTracer() *tracing.Tracer {
+	switch *f.trace {
+	case "":
+		return nil
+	case "stdout":
+		return tracing.NewTracer(tracing.NewStdoutExporter(os.Stdout))
+	default:
+		return tracing.NewTracer(tracing.NewOTLPExporter(*f.trace))
+	}
+}
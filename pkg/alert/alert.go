@@ -0,0 +1,241 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidRule 告警规则不合法
+	ErrInvalidRule = errors.New("invalid alert rule")
+	// ErrInvalidSilence 静默不合法
+	ErrInvalidSilence = errors.New("invalid silence")
+	// ErrSilenceNotFound 静默不存在
+	ErrSilenceNotFound = errors.New("silence not found")
+)
+
+// RuleType 告警规则类型
+type RuleType string
+
+const (
+	// RuleConsecutiveFailures 最近 Threshold 次结束的运行全部失败
+	RuleConsecutiveFailures RuleType = "consecutive_failures"
+	// RuleFailureRate Window 内结束的运行失败率超过 Threshold（百分比）
+	RuleFailureRate RuleType = "failure_rate"
+	// RuleNoSuccess Window 内没有成功的运行
+	RuleNoSuccess RuleType = "no_success"
+	// RuleDurationExceeded 进行中的运行或最近一次结束的运行耗时超过 MaxDuration
+	RuleDurationExceeded RuleType = "duration_exceeded"
+)
+
+// RuleTypes 所有规则类型
+var RuleTypes = []RuleType{RuleConsecutiveFailures, RuleFailureRate, RuleNoSuccess, RuleDurationExceeded}
+
+// Duration 规则配置中的时长，JSON 中写作 "15m" 这样的字符串，也接受纳秒数
+type Duration time.Duration
+
+// MarshalJSON 编码为时长字符串
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON 解析时长字符串或纳秒数
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case float64:
+		*d = Duration(v)
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	return nil
+}
+
+// Rule 告警规则，对每个适用的任务分别评估
+type Rule struct {
+	// Name 规则名称，唯一
+	Name string   `json:"name"`
+	Type RuleType `json:"type"`
+	// TaskIDs 适用的任务，为空时适用于所有任务
+	TaskIDs  []string `json:"task_ids,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+	// Threshold consecutive_failures 为连续失败次数，failure_rate 为失败率百分比
+	Threshold float64 `json:"threshold,omitempty"`
+	// Window failure_rate 的统计窗口（按触发时间），no_success 要求窗口内至少有一次成功
+	Window Duration `json:"window,omitempty"`
+	// MinRuns failure_rate 窗口内结束的运行少于该数时不告警，0表示1
+	MinRuns int `json:"min_runs,omitempty"`
+	// MaxDuration duration_exceeded 的预期最长耗时
+	MaxDuration Duration `json:"max_duration,omitempty"`
+}
+
+// Validate 检查规则的必填参数
+func (r Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule name is required: %w", ErrInvalidRule)
+	}
+	switch r.Type {
+	case RuleConsecutiveFailures:
+		if r.Threshold < 1 || r.Threshold != float64(int(r.Threshold)) {
+			return fmt.Errorf("rule %s: threshold must be a positive integer: %w", r.Name, ErrInvalidRule)
+		}
+	case RuleFailureRate:
+		if r.Threshold < 0 || r.Threshold >= 100 {
+			return fmt.Errorf("rule %s: threshold must be a percentage in [0, 100): %w", r.Name, ErrInvalidRule)
+		}
+		if r.Window <= 0 {
+			return fmt.Errorf("rule %s: window is required: %w", r.Name, ErrInvalidRule)
+		}
+	case RuleNoSuccess:
+		if r.Window <= 0 {
+			return fmt.Errorf("rule %s: window is required: %w", r.Name, ErrInvalidRule)
+		}
+	case RuleDurationExceeded:
+		if r.MaxDuration <= 0 {
+			return fmt.Errorf("rule %s: max_duration is required: %w", r.Name, ErrInvalidRule)
+		}
+	default:
+		return fmt.Errorf("rule %s: unknown type %q: %w", r.Name, r.Type, ErrInvalidRule)
+	}
+	return nil
+}
+
+// appliesTo 规则是否适用于任务
+func (r Rule) appliesTo(taskID string) bool {
+	if len(r.TaskIDs) == 0 {
+		return true
+	}
+	for _, id := range r.TaskIDs {
+		if id == taskID {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRules 解析 JSON 数组形式的规则列表并校验，规则名称不得重复
+func ParseRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid alert rules: %v", err)
+	}
+	if err := validateRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// validateRules 校验每条规则并检查名称重复
+func validateRules(rules []Rule) error {
+	names := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name %s: %w", rule.Name, ErrInvalidRule)
+		}
+		names[rule.Name] = true
+	}
+	return nil
+}
+
+// State 告警状态
+type State string
+
+const (
+	// StateFiring 告警中
+	StateFiring State = "firing"
+	// StateResolved 已恢复
+	StateResolved State = "resolved"
+)
+
+// ParseState 解析告警状态名称
+func ParseState(value string) (State, error) {
+	switch State(value) {
+	case StateFiring, StateResolved:
+		return State(value), nil
+	}
+	return "", fmt.Errorf("unknown alert state %q", value)
+}
+
+// Alert 一条规则在一个任务上的告警，ID 为 {规则名}/{任务ID}，同一ID告警期间只通知一次
+type Alert struct {
+	ID       string   `json:"id"`
+	Rule     string   `json:"rule"`
+	Type     RuleType `json:"type"`
+	Severity string   `json:"severity,omitempty"`
+	TaskID   string   `json:"task_id"`
+	State    State    `json:"state"`
+	// Summary 触发原因的描述
+	Summary string `json:"summary"`
+	// Value 最近一次评估的观测值：连续失败次数、失败率百分比、距上次成功的秒数或耗时秒数
+	Value float64 `json:"value"`
+	// RunID 最近一次评估涉及的运行
+	RunID    string    `json:"run_id,omitempty"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"` // 恢复时间，告警中为零值
+	// UpdatedAt 最近一次评估时间
+	UpdatedAt time.Time `json:"updated_at"`
+	// NotifiedAt 最近一次发送告警通知的时间，零值表示被静默未通知
+	NotifiedAt time.Time `json:"notified_at"`
+	Silenced   bool      `json:"silenced"`
+	SilenceID  string    `json:"silence_id,omitempty"`
+}
+
+// alertID 告警的去重键
+func alertID(rule, taskID string) string {
+	return rule + "/" + taskID
+}
+
+// Silence 静默：生效期间匹配的告警不发送通知，Rule 和 TaskID 为空表示匹配所有
+type Silence struct {
+	ID        string    `json:"id"`
+	Rule      string    `json:"rule,omitempty"`
+	TaskID    string    `json:"task_id,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	StartsAt  time.Time `json:"starts_at"` // 为空时从创建时开始
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Active 静默在给定时间是否生效
+func (s *Silence) Active(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// Matches 静默是否匹配告警
+func (s *Silence) Matches(alert *Alert) bool {
+	return (s.Rule == "" || s.Rule == alert.Rule) && (s.TaskID == "" || s.TaskID == alert.TaskID)
+}
+
+// String 静默的匹配条件
+func (s *Silence) String() string {
+	var matchers []string
+	if s.Rule != "" {
+		matchers = append(matchers, "rule="+s.Rule)
+	}
+	if s.TaskID != "" {
+		matchers = append(matchers, "task="+s.TaskID)
+	}
+	if len(matchers) == 0 {
+		return "all alerts"
+	}
+	return strings.Join(matchers, ",")
+}
@@ -0,0 +1,230 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"task_scheduler/pkg/store"
+	"task_scheduler/pkg/types"
+)
+
+// fakeSource 基于内存存储的运行历史
+type fakeSource struct {
+	store *store.MemoryStore
+	tasks []*types.Task
+	runs  int
+}
+
+func newFakeSource(tasks ...*types.Task) *fakeSource {
+	return &fakeSource{store: store.NewMemoryStore(), tasks: tasks}
+}
+
+func (s *fakeSource) GetTasks() []*types.Task {
+	return s.tasks
+}
+
+func (s *fakeSource) GetRunHistory(query types.RunQuery) (*types.RunPage, error) {
+	return s.store.QueryRuns(query)
+}
+
+// addRun 记录一次运行，耗时为 took
+func (s *fakeSource) addRun(taskID string, status types.RunStatus, fireTime time.Time, took time.Duration) *types.Run {
+	s.runs++
+	run := &types.Run{
+		ID:        fmt.Sprintf("run-%d", s.runs),
+		TaskID:    taskID,
+		Status:    status,
+		FireTime:  fireTime,
+		StartTime: fireTime,
+	}
+	if status.Finished() {
+		run.EndTime = fireTime.Add(took)
+	}
+	s.store.CreateRun(run)
+	return run
+}
+
+// recorder 记录收到的通知
+type recorder struct {
+	alerts []Alert
+}
+
+func (r *recorder) handle(alert Alert) {
+	r.alerts = append(r.alerts, alert)
+}
+
+func (r *recorder) states() string {
+	var parts []string
+	for _, alert := range r.alerts {
+		parts = append(parts, alert.ID+":"+string(alert.State))
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestRules(t *testing.T) {
+	now := time.Now()
+	task := &types.Task{ID: "task-1", CreatedAt: now.Add(-48 * time.Hour)}
+	source := newFakeSource(task)
+	for i := 0; i < 10; i++ {
+		status := types.RunStatusSucceeded
+		if i >= 7 {
+			status = types.RunStatusFailed
+		}
+		source.addRun("task-1", status, now.Add(time.Duration(i-10)*time.Minute), time.Second)
+	}
+	source.addRun("task-1", types.RunStatusRunning, now.Add(-20*time.Minute), 0)
+
+	tests := []struct {
+		rule   Rule
+		firing bool
+		value  float64
+	}{
+		{Rule{Type: RuleConsecutiveFailures, Threshold: 3}, true, 3},
+		{Rule{Type: RuleConsecutiveFailures, Threshold: 4}, false, 3},
+		{Rule{Type: RuleFailureRate, Threshold: 25, Window: Duration(time.Hour)}, true, 30},
+		{Rule{Type: RuleFailureRate, Threshold: 25, Window: Duration(time.Hour), MinRuns: 20}, false, 30},
+		{Rule{Type: RuleNoSuccess, Window: Duration(time.Hour)}, false, 0},
+		{Rule{Type: RuleNoSuccess, Window: Duration(3 * time.Minute)}, true, 0},
+		{Rule{Type: RuleDurationExceeded, MaxDuration: Duration(10 * time.Minute)}, true, 0},
+		{Rule{Type: RuleDurationExceeded, MaxDuration: Duration(time.Hour)}, false, 0},
+	}
+	for i, test := range tests {
+		test.rule.Name = fmt.Sprintf("rule-%d", i)
+		if err := test.rule.Validate(); err != nil {
+			t.Fatalf("rule %d invalid: %v", i, err)
+		}
+		res, err := test.rule.evaluate(source, task, now)
+		if err != nil {
+			t.Fatalf("rule %d failed: %v", i, err)
+		}
+		if res.firing != test.firing || (test.value != 0 && res.value != test.value) {
+			t.Errorf("rule %d (%s): got firing=%v value=%v (%s)", i, test.rule.Type, res.firing, res.value, res.summary)
+		}
+	}
+
+	// 最近一次结束的运行超时
+	source.addRun("task-1", types.RunStatusSucceeded, now.Add(-time.Minute), 2*time.Minute)
+	res, _ := Rule{Name: "slow", Type: RuleDurationExceeded, MaxDuration: Duration(time.Minute)}.evaluate(source, &types.Task{ID: "task-1"}, now.Add(-25*time.Minute))
+	if !res.firing || res.runID != "run-12" {
+		t.Errorf("expected the last finished run to exceed the limit: %+v", res)
+	}
+
+	invalid := []Rule{
+		{Name: "a", Type: RuleConsecutiveFailures, Threshold: 1.5},
+		{Name: "b", Type: RuleFailureRate, Threshold: 10},
+		{Name: "c", Type: "unknown"},
+		{Type: RuleNoSuccess, Window: Duration(time.Hour)},
+	}
+	for _, rule := range invalid {
+		if err := rule.Validate(); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("expected %+v to be invalid, got %v", rule, err)
+		}
+	}
+
+	rules, err := ParseRules(strings.NewReader(`[{"name": "slow", "type": "duration_exceeded", "max_duration": "90s"}]`))
+	if err != nil || time.Duration(rules[0].MaxDuration) != 90*time.Second {
+		t.Errorf("unexpected parsed rules: %+v %v", rules, err)
+	}
+	if _, err := ParseRules(strings.NewReader(`[{"name": "a", "type": "no_success", "window": "1h"}, {"name": "a", "type": "no_success", "window": "1h"}]`)); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected duplicate names to be rejected, got %v", err)
+	}
+}
+
+func TestDeduplicationAndResolve(t *testing.T) {
+	now := time.Now()
+	source := newFakeSource(&types.Task{ID: "task-1", CreatedAt: now}, &types.Task{ID: "task-2", CreatedAt: now})
+	notified := &recorder{}
+	manager, err := NewManager(source, []Rule{
+		{Name: "failing", Type: RuleConsecutiveFailures, Threshold: 2, TaskIDs: []string{"task-1"}},
+	}, WithHandler(notified.handle))
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	source.addRun("task-1", types.RunStatusFailed, now, time.Second)
+	source.addRun("task-2", types.RunStatusFailed, now, time.Second)
+	source.addRun("task-2", types.RunStatusFailed, now.Add(time.Second), time.Second)
+	manager.Evaluate(now)
+	if len(notified.alerts) != 0 {
+		t.Fatalf("unexpected notifications: %s", notified.states())
+	}
+
+	source.addRun("task-1", types.RunStatusFailed, now.Add(time.Minute), time.Second)
+	manager.Evaluate(now.Add(time.Minute))
+	manager.EvaluateTask("task-1", now.Add(2*time.Minute))
+	source.addRun("task-1", types.RunStatusFailed, now.Add(3*time.Minute), time.Second)
+	manager.Evaluate(now.Add(3 * time.Minute))
+	if notified.states() != "failing/task-1:firing" {
+		t.Fatalf("expected a single firing notification, got %s", notified.states())
+	}
+	alerts := manager.Alerts()
+	if len(alerts) != 1 || alerts[0].Value != 2 || !alerts[0].StartsAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("unexpected alerts: %+v", alerts)
+	}
+
+	source.addRun("task-1", types.RunStatusSucceeded, now.Add(4*time.Minute), time.Second)
+	manager.Evaluate(now.Add(4 * time.Minute))
+	if notified.states() != "failing/task-1:firing,failing/task-1:resolved" {
+		t.Fatalf("expected a resolve notification, got %s", notified.states())
+	}
+	if alerts := manager.Alerts(); alerts[0].State != StateResolved || !alerts[0].EndsAt.Equal(now.Add(4*time.Minute)) {
+		t.Errorf("unexpected resolved alert: %+v", alerts[0])
+	}
+
+	// 重复通知与任务移除
+	repeating, _ := NewManager(source, []Rule{{Name: "failing", Type: RuleConsecutiveFailures, Threshold: 2}},
+		WithHandler(notified.handle), WithRepeatInterval(time.Hour), WithRetention(time.Hour))
+	notified.alerts = nil
+	repeating.Evaluate(now)
+	repeating.Evaluate(now.Add(30 * time.Minute))
+	repeating.Evaluate(now.Add(time.Hour))
+	source.tasks = source.tasks[:1]
+	repeating.Evaluate(now.Add(2 * time.Hour))
+	if notified.states() != "failing/task-2:firing,failing/task-2:firing,failing/task-2:resolved" {
+		t.Errorf("unexpected repeated notifications: %s", notified.states())
+	}
+	repeating.Evaluate(now.Add(4 * time.Hour))
+	if alerts := repeating.Alerts(); len(alerts) != 0 {
+		t.Errorf("resolved alerts should be pruned after the retention: %+v", alerts)
+	}
+}
+
+func TestSilences(t *testing.T) {
+	now := time.Now()
+	source := newFakeSource(&types.Task{ID: "task-1", CreatedAt: now})
+	notified := &recorder{}
+	manager, _ := NewManager(source, []Rule{{Name: "failing", Type: RuleConsecutiveFailures, Threshold: 1}}, WithHandler(notified.handle))
+
+	if _, err := manager.AddSilence(Silence{EndsAt: now.Add(-time.Minute)}); !errors.Is(err, ErrInvalidSilence) {
+		t.Errorf("expected a silence in the past to be rejected, got %v", err)
+	}
+	silence, err := manager.AddSilence(Silence{TaskID: "task-1", EndsAt: time.Now().Add(time.Hour), Comment: "maintenance"})
+	if err != nil || silence.ID == "" {
+		t.Fatalf("AddSilence failed: %+v %v", silence, err)
+	}
+
+	source.addRun("task-1", types.RunStatusFailed, now, time.Second)
+	manager.Evaluate(time.Now())
+	alerts := manager.Alerts()
+	if len(notified.alerts) != 0 || len(alerts) != 1 || !alerts[0].Silenced || alerts[0].SilenceID != silence.ID {
+		t.Fatalf("silenced alert should not be notified: %s %+v", notified.states(), alerts)
+	}
+
+	// 静默结束后仍在告警的发送通知
+	if _, err := manager.ExpireSilence(silence.ID); err != nil {
+		t.Fatalf("ExpireSilence failed: %v", err)
+	}
+	manager.Evaluate(time.Now())
+	if notified.states() != "failing/task-1:firing" || manager.Alerts()[0].Silenced {
+		t.Errorf("expected notification after the silence expired, got %s", notified.states())
+	}
+	if silences := manager.Silences(); len(silences) != 1 || silences[0].Active(time.Now()) {
+		t.Errorf("expired silence should be listed as inactive: %+v", silences)
+	}
+	if _, err := manager.ExpireSilence("missing"); !errors.Is(err, ErrSilenceNotFound) {
+		t.Errorf("expected ErrSilenceNotFound, got %v", err)
+	}
+}
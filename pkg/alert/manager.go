@@ -0,0 +1,402 @@
+package alert
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/types"
+)
+
+const (
+	// DefaultInterval 默认的定期评估间隔
+	DefaultInterval = 30 * time.Second
+	// DefaultRetention 已恢复的告警和已过期的静默默认保留时长
+	DefaultRetention = 24 * time.Hour
+)
+
+// Handler 接收告警通知：告警开始（State 为 firing）和恢复（State 为 resolved）
+// 处理函数在评估协程中依次同步调用，耗时的投递应自行异步处理
+type Handler func(alert Alert)
+
+// Option 告警管理器配置项
+type Option func(*Manager)
+
+// WithInterval 设置定期评估间隔，默认 DefaultInterval
+func WithInterval(interval time.Duration) Option {
+	return func(m *Manager) {
+		m.interval = interval
+	}
+}
+
+// WithRepeatInterval 告警持续期间每隔该时长重复通知，默认0表示只在开始时通知一次
+func WithRepeatInterval(interval time.Duration) Option {
+	return func(m *Manager) {
+		m.repeatInterval = interval
+	}
+}
+
+// WithRetention 设置已恢复告警和已过期静默的保留时长，默认 DefaultRetention
+func WithRetention(retention time.Duration) Option {
+	return func(m *Manager) {
+		m.retention = retention
+	}
+}
+
+// WithHandler 添加通知处理函数
+func WithHandler(handler Handler) Option {
+	return func(m *Manager) {
+		m.handlers = append(m.handlers, handler)
+	}
+}
+
+// WithLogger 设置日志记录器，默认 slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(m *Manager) {
+		m.logger = logger
+	}
+}
+
+// Manager 按运行历史定期评估告警规则，对告警去重并在开始和恢复时通知，静默期间不通知
+// 运行结束时立即评估该任务的规则；告警和静默只保存在内存中
+type Manager struct {
+	source         Source
+	rules          []Rule
+	interval       time.Duration
+	repeatInterval time.Duration
+	retention      time.Duration
+	handlers       []Handler
+	logger         *slog.Logger
+
+	alerts   map[string]*Alert
+	silences map[string]*Silence
+	mutex    sync.Mutex
+
+	evalMutex sync.Mutex // 串行化评估，保证同一告警的通知顺序
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewManager 创建告警管理器，规则不合法或名称重复时返回错误
+func NewManager(source Source, rules []Rule, opts ...Option) (*Manager, error) {
+	if err := validateRules(rules); err != nil {
+		return nil, err
+	}
+	m := &Manager{
+		source:    source,
+		rules:     append([]Rule(nil), rules...),
+		interval:  DefaultInterval,
+		retention: DefaultRetention,
+		logger:    slog.Default(),
+		alerts:    make(map[string]*Alert),
+		silences:  make(map[string]*Silence),
+		stop:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// Start 开始定期评估；数据源支持事件订阅时，运行结束后立即评估该任务
+func (m *Manager) Start() {
+	m.startOnce.Do(func() {
+		var finished <-chan *types.Event
+		cancel := func() {}
+		if events, ok := m.source.(eventSource); ok {
+			finished, cancel = events.SubscribeReliable(types.EventFilter{Types: []types.EventType{types.EventRunFinished}})
+		}
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			defer cancel()
+			ticker := time.NewTicker(m.interval)
+			defer ticker.Stop()
+
+			m.Evaluate(time.Now())
+			for {
+				select {
+				case <-ticker.C:
+					m.Evaluate(time.Now())
+				case event, ok := <-finished:
+					if !ok {
+						finished = nil
+						continue
+					}
+					m.EvaluateTask(event.TaskID, time.Now())
+				case <-m.stop:
+					return
+				}
+			}
+		}()
+		m.logger.Info("Alerting started", "rules", len(m.rules), "interval", m.interval)
+	})
+}
+
+// Stop 停止评估，等待进行中的评估和通知结束
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		m.wg.Wait()
+	})
+}
+
+// Rules 返回告警规则
+func (m *Manager) Rules() []Rule {
+	return append([]Rule(nil), m.rules...)
+}
+
+// Evaluate 评估所有任务上的规则；已移除任务的告警随之恢复
+func (m *Manager) Evaluate(now time.Time) {
+	m.evaluate(m.source.GetTasks(), true, now)
+}
+
+// EvaluateTask 只评估一个任务上的规则，任务不存在时忽略
+func (m *Manager) EvaluateTask(taskID string, now time.Time) {
+	for _, task := range m.source.GetTasks() {
+		if task.ID == taskID {
+			m.evaluate([]*types.Task{task}, false, now)
+			return
+		}
+	}
+}
+
+// evaluate 评估规则并更新告警状态，full 为真时恢复不再存在的任务上的告警
+func (m *Manager) evaluate(tasks []*types.Task, full bool, now time.Time) {
+	m.evalMutex.Lock()
+	defer m.evalMutex.Unlock()
+
+	type evaluated struct {
+		rule   Rule
+		taskID string
+		result result
+	}
+	var results []evaluated
+	seen := make(map[string]bool)
+	for _, task := range tasks {
+		for _, rule := range m.rules {
+			if !rule.appliesTo(task.ID) {
+				continue
+			}
+			seen[alertID(rule.Name, task.ID)] = true
+			res, err := rule.evaluate(m.source, task, now)
+			if err != nil {
+				// 查询失败时保持原有状态
+				m.logger.Warn("Failed to evaluate alert rule", "rule", rule.Name, logging.KeyTaskID, task.ID, logging.Err(err))
+				continue
+			}
+			results = append(results, evaluated{rule, task.ID, res})
+		}
+	}
+
+	m.mutex.Lock()
+	var notifications []Alert
+	for _, e := range results {
+		if alert := m.updateLocked(e.rule, e.taskID, e.result, now); alert != nil {
+			notifications = append(notifications, *alert)
+		}
+	}
+	if full {
+		for id, alert := range m.alerts {
+			if alert.State == StateFiring && !seen[id] {
+				if notify := m.resolveLocked(alert, now); notify {
+					notifications = append(notifications, *alert)
+				}
+			}
+		}
+	}
+	m.pruneLocked(now)
+	m.mutex.Unlock()
+
+	m.notify(notifications)
+}
+
+// updateLocked 按评估结果更新告警，返回需要通知的告警
+func (m *Manager) updateLocked(rule Rule, taskID string, res result, now time.Time) *Alert {
+	id := alertID(rule.Name, taskID)
+	alert := m.alerts[id]
+	if !res.firing {
+		if alert != nil && alert.State == StateFiring {
+			alert.Value, alert.RunID = res.value, res.runID
+			if m.resolveLocked(alert, now) {
+				return alert
+			}
+		}
+		return nil
+	}
+
+	if alert == nil || alert.State == StateResolved {
+		alert = &Alert{
+			ID:       id,
+			Rule:     rule.Name,
+			Type:     rule.Type,
+			Severity: rule.Severity,
+			TaskID:   taskID,
+			State:    StateFiring,
+			StartsAt: now,
+		}
+		m.alerts[id] = alert
+	}
+	alert.Summary, alert.Value, alert.RunID, alert.UpdatedAt = res.summary, res.value, res.runID, now
+	m.silenceLocked(alert, now)
+	if alert.Silenced {
+		return nil
+	}
+	// 去重：告警期间只通知一次，配置了重复间隔时按间隔重复通知
+	if alert.NotifiedAt.IsZero() || (m.repeatInterval > 0 && now.Sub(alert.NotifiedAt) >= m.repeatInterval) {
+		alert.NotifiedAt = now
+		return alert
+	}
+	return nil
+}
+
+// resolveLocked 把告警标记为恢复，发送过告警通知的才需要发送恢复通知
+func (m *Manager) resolveLocked(alert *Alert, now time.Time) bool {
+	alert.State = StateResolved
+	alert.EndsAt = now
+	alert.UpdatedAt = now
+	return !alert.NotifiedAt.IsZero()
+}
+
+// silenceLocked 按生效的静默更新告警的静默标记
+func (m *Manager) silenceLocked(alert *Alert, now time.Time) {
+	alert.Silenced, alert.SilenceID = false, ""
+	for _, silence := range m.silences {
+		if silence.Active(now) && silence.Matches(alert) {
+			alert.Silenced, alert.SilenceID = true, silence.ID
+			return
+		}
+	}
+}
+
+// pruneLocked 清理超过保留时长的已恢复告警和已过期静默
+func (m *Manager) pruneLocked(now time.Time) {
+	for id, alert := range m.alerts {
+		if alert.State == StateResolved && now.Sub(alert.EndsAt) > m.retention {
+			delete(m.alerts, id)
+		}
+	}
+	for id, silence := range m.silences {
+		if now.Sub(silence.EndsAt) > m.retention {
+			delete(m.silences, id)
+		}
+	}
+}
+
+// notify 记录日志并调用通知处理函数
+func (m *Manager) notify(alerts []Alert) {
+	for _, alert := range alerts {
+		logger := m.logger.With("alert_id", alert.ID, "rule", alert.Rule, logging.KeyTaskID, alert.TaskID)
+		if alert.State == StateFiring {
+			logger.Warn("Alert firing", "severity", alert.Severity, "summary", alert.Summary)
+		} else {
+			logger.Info("Alert resolved", "duration", alert.EndsAt.Sub(alert.StartsAt).Round(time.Second))
+		}
+		for _, handler := range m.handlers {
+			handler(alert)
+		}
+	}
+}
+
+// Alerts 返回告警中和保留期内已恢复的告警，告警中的排在前面，同状态按开始时间倒序
+func (m *Manager) Alerts() []Alert {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	alerts := make([]Alert, 0, len(m.alerts))
+	for _, alert := range m.alerts {
+		alerts = append(alerts, *alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].State != alerts[j].State {
+			return alerts[i].State == StateFiring
+		}
+		if !alerts[i].StartsAt.Equal(alerts[j].StartsAt) {
+			return alerts[i].StartsAt.After(alerts[j].StartsAt)
+		}
+		return alerts[i].ID < alerts[j].ID
+	})
+	return alerts
+}
+
+// AddSilence 添加静默，StartsAt 为空时立即生效；返回带ID的静默
+// 匹配的告警立即标记为静默，不会再发送通知
+func (m *Manager) AddSilence(silence Silence) (*Silence, error) {
+	now := time.Now()
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = now
+	}
+	if !silence.EndsAt.After(silence.StartsAt) || !silence.EndsAt.After(now) {
+		return nil, fmt.Errorf("silence must end in the future and after it starts: %w", ErrInvalidSilence)
+	}
+	silence.ID = newSilenceID()
+	silence.CreatedAt = now
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.silences[silence.ID] = &silence
+	for _, alert := range m.alerts {
+		if alert.State == StateFiring {
+			m.silenceLocked(alert, now)
+		}
+	}
+	m.logger.Info("Silence added", "silence_id", silence.ID, "matchers", silence.String(), "ends_at", silence.EndsAt)
+	added := silence
+	return &added, nil
+}
+
+// ExpireSilence 立即结束静默；仍在告警中且未通知过的告警在下一次评估时发送通知
+func (m *Manager) ExpireSilence(silenceID string) (*Silence, error) {
+	now := time.Now()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	silence, exists := m.silences[silenceID]
+	if !exists {
+		return nil, fmt.Errorf("silence %s: %w", silenceID, ErrSilenceNotFound)
+	}
+	if silence.EndsAt.After(now) {
+		silence.EndsAt = now
+		if silence.StartsAt.After(now) {
+			silence.StartsAt = now
+		}
+		m.logger.Info("Silence expired", "silence_id", silence.ID, "matchers", silence.String())
+	}
+	expired := *silence
+	return &expired, nil
+}
+
+// Silences 返回生效中、未开始和保留期内已过期的静默，按结束时间倒序
+func (m *Manager) Silences() []Silence {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	silences := make([]Silence, 0, len(m.silences))
+	for _, silence := range m.silences {
+		silences = append(silences, *silence)
+	}
+	sort.Slice(silences, func(i, j int) bool {
+		if !silences[i].EndsAt.Equal(silences[j].EndsAt) {
+			return silences[i].EndsAt.After(silences[j].EndsAt)
+		}
+		return silences[i].ID < silences[j].ID
+	})
+	return silences
+}
+
+// newSilenceID 生成静默ID
+func newSilenceID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,172 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+// Source 告警评估所需的任务与运行历史，*scheduler.TaskScheduler 实现了该接口
+type Source interface {
+	GetTasks() []*types.Task
+	GetRunHistory(query types.RunQuery) (*types.RunPage, error)
+}
+
+// eventSource 可以订阅运行结束事件的数据源，运行结束后立即评估该任务的规则；订阅不会丢弃事件
+type eventSource interface {
+	SubscribeReliable(filter types.EventFilter) (<-chan *types.Event, func())
+}
+
+// outcomeStatuses 参与失败统计的运行状态，取消的运行不计入
+var outcomeStatuses = []types.RunStatus{types.RunStatusSucceeded, types.RunStatusFailed, types.RunStatusLost}
+
+// failed 运行是否计为失败，结果未知的丢失运行也计为失败
+func failed(run *types.Run) bool {
+	return run.Status == types.RunStatusFailed || run.Status == types.RunStatusLost
+}
+
+// result 一次规则评估的结果
+type result struct {
+	firing  bool
+	value   float64
+	summary string
+	runID   string
+}
+
+// evaluate 按运行历史评估规则在任务上是否触发
+func (r Rule) evaluate(source Source, task *types.Task, now time.Time) (result, error) {
+	switch r.Type {
+	case RuleConsecutiveFailures:
+		return r.evaluateConsecutiveFailures(source, task)
+	case RuleFailureRate:
+		return r.evaluateFailureRate(source, task, now)
+	case RuleNoSuccess:
+		return r.evaluateNoSuccess(source, task, now)
+	case RuleDurationExceeded:
+		return r.evaluateDuration(source, task, now)
+	}
+	return result{}, fmt.Errorf("rule %s: unknown type %q: %w", r.Name, r.Type, ErrInvalidRule)
+}
+
+// evaluateConsecutiveFailures 最近 Threshold 次结束的运行是否全部失败
+func (r Rule) evaluateConsecutiveFailures(source Source, task *types.Task) (result, error) {
+	threshold := int(r.Threshold)
+	page, err := source.GetRunHistory(types.RunQuery{TaskID: task.ID, Statuses: outcomeStatuses, Limit: threshold})
+	if err != nil {
+		return result{}, err
+	}
+	var res result
+	for _, run := range page.Runs {
+		if !failed(run) {
+			break
+		}
+		res.value++
+	}
+	if len(page.Runs) > 0 {
+		res.runID = page.Runs[0].ID
+	}
+	res.firing = int(res.value) >= threshold
+	res.summary = fmt.Sprintf("%d consecutive failed runs", int(res.value))
+	if res.firing && page.Runs[0].Error != "" {
+		res.summary += ", last error: " + page.Runs[0].Error
+	}
+	return res, nil
+}
+
+// evaluateFailureRate Window 内结束的运行失败率是否超过 Threshold
+func (r Rule) evaluateFailureRate(source Source, task *types.Task, now time.Time) (result, error) {
+	window := time.Duration(r.Window)
+	page, err := source.GetRunHistory(types.RunQuery{TaskID: task.ID, Statuses: outcomeStatuses, From: now.Add(-window)})
+	if err != nil {
+		return result{}, err
+	}
+	minRuns := r.MinRuns
+	if minRuns <= 0 {
+		minRuns = 1
+	}
+	failures := 0
+	for _, run := range page.Runs {
+		if failed(run) {
+			failures++
+		}
+	}
+	var res result
+	if len(page.Runs) > 0 {
+		res.value = float64(failures) * 100 / float64(len(page.Runs))
+		res.runID = page.Runs[0].ID
+	}
+	res.firing = len(page.Runs) >= minRuns && res.value > r.Threshold
+	res.summary = fmt.Sprintf("failure rate %.1f%% (%d of %d runs) over the last %s", res.value, failures, len(page.Runs), window)
+	return res, nil
+}
+
+// evaluateNoSuccess Window 内是否没有成功的运行
+// 暂停的任务和创建不足一个窗口的任务不会触发
+func (r Rule) evaluateNoSuccess(source Source, task *types.Task, now time.Time) (result, error) {
+	window := time.Duration(r.Window)
+	if task.Status == types.TaskStatusPaused || now.Sub(task.CreatedAt) < window {
+		return result{}, nil
+	}
+	page, err := source.GetRunHistory(types.RunQuery{TaskID: task.ID, Statuses: []types.RunStatus{types.RunStatusSucceeded}, Limit: 1})
+	if err != nil {
+		return result{}, err
+	}
+	lastSuccess := task.CreatedAt
+	var res result
+	if len(page.Runs) > 0 {
+		lastSuccess = page.Runs[0].FireTime
+		res.runID = page.Runs[0].ID
+	}
+	since := now.Sub(lastSuccess)
+	res.value = since.Seconds()
+	res.firing = since >= window
+	if len(page.Runs) > 0 {
+		res.summary = fmt.Sprintf("no successful run in %s, last success fired at %s", window, lastSuccess.Format(time.RFC3339))
+	} else {
+		res.summary = fmt.Sprintf("no successful run in %s, the task never succeeded", window)
+	}
+	return res, nil
+}
+
+// evaluateDuration 进行中的运行已执行的时间或最近一次结束的运行耗时是否超过 MaxDuration
+func (r Rule) evaluateDuration(source Source, task *types.Task, now time.Time) (result, error) {
+	limit := time.Duration(r.MaxDuration)
+	running, err := source.GetRunHistory(types.RunQuery{TaskID: task.ID, Statuses: []types.RunStatus{types.RunStatusRunning}})
+	if err != nil {
+		return result{}, err
+	}
+	var res result
+	for _, run := range running.Runs {
+		if run.StartTime.IsZero() {
+			continue
+		}
+		if elapsed := now.Sub(run.StartTime); elapsed > limit && elapsed.Seconds() > res.value {
+			res = result{
+				firing:  true,
+				value:   elapsed.Seconds(),
+				runID:   run.ID,
+				summary: fmt.Sprintf("run %s has been running for %s, expected at most %s", run.ID, elapsed.Round(time.Second), limit),
+			}
+		}
+	}
+	if res.firing {
+		return res, nil
+	}
+
+	last, err := source.GetRunHistory(types.RunQuery{TaskID: task.ID, Statuses: []types.RunStatus{types.RunStatusSucceeded, types.RunStatusFailed}, Limit: 1})
+	if err != nil {
+		return result{}, err
+	}
+	if len(last.Runs) > 0 && !last.Runs[0].StartTime.IsZero() {
+		run := last.Runs[0]
+		took := run.EndTime.Sub(run.StartTime)
+		res = result{
+			firing:  took > limit,
+			value:   took.Seconds(),
+			runID:   run.ID,
+			summary: fmt.Sprintf("run %s took %s, expected at most %s", run.ID, took.Round(time.Millisecond), limit),
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"task_scheduler/pkg/alert"
+)
+
+// errAlertingDisabled 未配置告警管理器
+var errAlertingDisabled = errors.New("alerting is not enabled")
+
+// routeAlerts 告警相关路由
+func (s *Server) routeAlerts(w http.ResponseWriter, r *http.Request, segments []string) {
+	if s.alerts == nil {
+		writeError(w, http.StatusNotFound, errAlertingDisabled)
+		return
+	}
+	switch {
+	case len(segments) == 0 || segments[0] == "":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		s.listAlerts(w, r)
+	case len(segments) == 1 && segments[0] == "rules":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		writeJSON(w, http.StatusOK, s.alerts.Rules())
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+	}
+}
+
+// listAlerts 列出告警中和最近恢复的告警
+// 查询参数：state（firing 或 resolved）、task
+func (s *Server) listAlerts(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	var state alert.State
+	if value := values.Get("state"); value != "" {
+		parsed, err := alert.ParseState(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		state = parsed
+	}
+	taskID := values.Get("task")
+
+	alerts := make([]alert.Alert, 0)
+	for _, a := range s.alerts.Alerts() {
+		if (state == "" || a.State == state) && (taskID == "" || a.TaskID == taskID) {
+			alerts = append(alerts, a)
+		}
+	}
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+// routeSilences 静默相关路由
+func (s *Server) routeSilences(w http.ResponseWriter, r *http.Request, segments []string) {
+	if s.alerts == nil {
+		writeError(w, http.StatusNotFound, errAlertingDisabled)
+		return
+	}
+	switch {
+	case len(segments) == 0 || segments[0] == "":
+		if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+			return
+		}
+		if r.Method == http.MethodGet {
+			writeJSON(w, http.StatusOK, s.alerts.Silences())
+		} else {
+			s.addSilence(w, r)
+		}
+	case len(segments) == 1:
+		if !allowMethods(w, r, http.MethodDelete) {
+			return
+		}
+		silence, err := s.alerts.ExpireSilence(segments[0])
+		if err != nil {
+			writeAlertError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, silence)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+	}
+}
+
+// addSilence 添加静默
+func (s *Server) addSilence(w http.ResponseWriter, r *http.Request) {
+	var req CreateSilenceRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	silence := alert.Silence{
+		Rule:      req.Rule,
+		TaskID:    req.TaskID,
+		Comment:   req.Comment,
+		CreatedBy: req.CreatedBy,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+	}
+	if req.Duration > 0 {
+		if !req.EndsAt.IsZero() {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("ends_at and duration are mutually exclusive"))
+			return
+		}
+		if silence.StartsAt.IsZero() {
+			silence.StartsAt = time.Now()
+		}
+		silence.EndsAt = silence.StartsAt.Add(time.Duration(req.Duration))
+	}
+
+	added, err := s.alerts.AddSilence(silence)
+	if err != nil {
+		writeAlertError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, added)
+}
+
+// writeAlertError 按告警错误类型映射状态码
+func writeAlertError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, alert.ErrInvalidSilence):
+		status = http.StatusBadRequest
+	case errors.Is(err, alert.ErrSilenceNotFound):
+		status = http.StatusNotFound
+	}
+	writeError(w, status, err)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"task_scheduler/pkg/scheduler"
+)
+
+// backfillTask 在服务内回填任务，回填的运行与cron触发共享并发名额、执行器和存储
+// 默认立即返回202，请求 Wait 时等待回填结束再返回结果
+func (s *Server) backfillTask(w http.ResponseWriter, r *http.Request, taskID string) {
+	var req BackfillRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.From.IsZero() {
+		writeError(w, http.StatusBadRequest, errors.New("from is required"))
+		return
+	}
+	to := req.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	job, err := s.scheduler.Backfill(taskID, req.From, to, scheduler.BackfillOptions{
+		MaxParallelism: req.MaxParallelism,
+		MaxRuns:        req.MaxRuns,
+	})
+	if err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	resp := BackfillResponse{TaskID: taskID, FireTimes: job.FireTimes}
+	if resp.FireTimes == nil {
+		resp.FireTimes = []time.Time{}
+	}
+	if !req.Wait {
+		writeJSON(w, http.StatusAccepted, resp)
+		return
+	}
+
+	select {
+	case <-job.Done():
+		result := job.Result()
+		resp.Result = &result
+		writeJSON(w, http.StatusOK, resp)
+	case <-r.Context().Done():
+	case <-s.done:
+		writeJSON(w, http.StatusAccepted, resp)
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+// eventHeartbeatInterval 事件流的心跳间隔，避免代理因连接空闲而断开
+const eventHeartbeatInterval = 15 * time.Second
+
+// streamEvents 以 Server-Sent Events 推送调度器事件
+// 查询参数：task（逗号分隔的任务ID）、type（逗号分隔的事件类型）
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEventFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	events, cancel := s.scheduler.Subscribe(filter)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	// 先发送注释行，客户端据此确认订阅已建立
+	fmt.Fprint(w, ": subscribed\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
+
+// parseEventFilter 解析事件订阅条件
+func parseEventFilter(r *http.Request) (types.EventFilter, error) {
+	var filter types.EventFilter
+	values := r.URL.Query()
+	if tasks := values.Get("task"); tasks != "" {
+		for _, taskID := range strings.Split(tasks, ",") {
+			if taskID = strings.TrimSpace(taskID); taskID != "" {
+				filter.TaskIDs = append(filter.TaskIDs, taskID)
+			}
+		}
+	}
+	if eventTypes := values.Get("type"); eventTypes != "" {
+		for _, name := range strings.Split(eventTypes, ",") {
+			eventType, err := types.ParseEventType(strings.TrimSpace(name))
+			if err != nil {
+				return filter, err
+			}
+			filter.Types = append(filter.Types, eventType)
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"task_scheduler/pkg/types"
+)
+
+// routeExecutors 执行器相关路由
+func (s *Server) routeExecutors(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 0 || segments[0] == "":
+		if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+			return
+		}
+		if r.Method == http.MethodGet {
+			s.listExecutors(w)
+		} else {
+			s.addExecutor(w, r)
+		}
+	case len(segments) == 1:
+		if !allowMethods(w, r, http.MethodGet, http.MethodDelete) {
+			return
+		}
+		if r.Method == http.MethodGet {
+			s.getExecutor(w, segments[0])
+		} else {
+			s.removeExecutor(w, segments[0])
+		}
+	case len(segments) == 2 && segments[1] == "drain":
+		if !allowMethods(w, r, http.MethodPost) {
+			return
+		}
+		s.drainExecutor(w, segments[0])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+	}
+}
+
+// listExecutors 列出所有执行器，包括不健康和排空中的
+func (s *Server) listExecutors(w http.ResponseWriter) {
+	executors := s.scheduler.ListExecutors()
+	infos := make([]ExecutorInfo, 0, len(executors))
+	for _, exec := range executors {
+		infos = append(infos, s.executorInfo(exec))
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// getExecutor 获取执行器信息
+func (s *Server) getExecutor(w http.ResponseWriter, executorID string) {
+	for _, exec := range s.scheduler.ListExecutors() {
+		if exec.GetID() == executorID {
+			writeJSON(w, http.StatusOK, s.executorInfo(exec))
+			return
+		}
+	}
+	writeSchedulerError(w, fmt.Errorf("executor %s: %w", executorID, types.ErrExecutorNotFound))
+}
+
+// addExecutor 添加执行器
+func (s *Server) addExecutor(w http.ResponseWriter, r *http.Request) {
+	var req AddExecutorRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.ID == "" || req.Address == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("id and address are required"))
+		return
+	}
+
+	exec, err := s.newExecutor(req.ID, req.Address)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.scheduler.AddExecutor(exec); err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.executorInfo(exec))
+}
+
+// removeExecutor 移除执行器，已派发的运行继续执行
+func (s *Server) removeExecutor(w http.ResponseWriter, executorID string) {
+	if err := s.scheduler.RemoveExecutor(executorID); err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// drainExecutor 排空执行器，返回的信息中包含仍在执行的运行数
+func (s *Server) drainExecutor(w http.ResponseWriter, executorID string) {
+	if _, err := s.scheduler.DrainExecutor(executorID); err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	s.getExecutor(w, executorID)
+}
+
+// executorInfo 汇总执行器信息
+func (s *Server) executorInfo(exec types.Executor) ExecutorInfo {
+	return ExecutorInfo{
+		ID:           exec.GetID(),
+		Address:      exec.GetAddress(),
+		Healthy:      exec.IsHealthy(),
+		Draining:     s.scheduler.IsExecutorDraining(exec.GetID()),
+		InFlight:     s.scheduler.GetRouter().InFlight().Count(exec.GetID()),
+		UsageCount:   exec.GetUsageCount(),
+		LastUsedTime: exec.GetLastUsedTime(),
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"task_scheduler/pkg/runlog"
+)
+
+// runLogs 输出运行日志
+// 查询参数：tail（只输出最后 n 行）、follow（运行结束前持续推送新日志）、format（text 或 json，json 为每行一个对象）
+// 响应头 X-Run-Log-Dropped 为因大小上限或 tail 未输出的最早行数
+func (s *Server) runLogs(w http.ResponseWriter, r *http.Request, runID string) {
+	values := r.URL.Query()
+	tail := 0
+	if value := values.Get("tail"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid tail %q", value))
+			return
+		}
+		tail = n
+	}
+	follow := false
+	if value := values.Get("follow"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid follow %q", value))
+			return
+		}
+		follow = parsed
+	}
+	format := values.Get("format")
+	if format != "" && format != "text" && format != "json" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid format %q (expected text or json)", format))
+		return
+	}
+
+	if _, err := s.scheduler.GetStore().GetRun(runID); err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	logs := s.scheduler.RunLogs()
+	snapshot, err := logs.Read(runID, tail)
+	if errors.Is(err, runlog.ErrNotFound) {
+		// 日志已按保留策略清理
+		snapshot, err = &runlog.Snapshot{Finished: true}, nil
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	write := func(line runlog.Line) {
+		if format == "json" {
+			data, _ := json.Marshal(line)
+			fmt.Fprintf(w, "%s\n", data)
+		} else {
+			io.WriteString(w, line.String()+"\n")
+		}
+	}
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Header().Set("X-Run-Log-Dropped", strconv.FormatInt(snapshot.Dropped, 10))
+	w.WriteHeader(http.StatusOK)
+	var last int64
+	for _, line := range snapshot.Lines {
+		write(line)
+		last = line.Seq
+	}
+	if !follow || snapshot.Finished {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return
+	}
+	flusher.Flush()
+
+	// 服务关闭时结束跟随，与事件流相同
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if len(snapshot.Lines) == 0 && snapshot.Dropped > 0 {
+		// 没有保留的行时从已丢弃的序号之后继续
+		last = snapshot.Dropped
+	}
+	for {
+		lines, finished, err := logs.Follow(ctx, runID, last)
+		if err != nil {
+			return
+		}
+		for _, line := range lines {
+			write(line)
+			last = line.Seq
+		}
+		flusher.Flush()
+		if finished {
+			return
+		}
+	}
+}
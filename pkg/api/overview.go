@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/types"
+)
+
+// finishedStatuses 已结束的运行状态，任务的上次结果只看这些运行
+var finishedStatuses = []types.RunStatus{
+	types.RunStatusSucceeded, types.RunStatusFailed, types.RunStatusLost, types.RunStatusCancelled,
+}
+
+// getOverview 仪表盘概览，一次请求返回任务列表页和执行器页所需的数据
+func (s *Server) getOverview(w http.ResponseWriter) {
+	tasks := s.scheduler.GetTasks()
+	overview := OverviewResponse{
+		Tasks:     make([]TaskOverview, 0, len(tasks)),
+		Executors: s.scheduler.GetExecutorStats(),
+		Time:      time.Now(),
+	}
+	for _, task := range tasks {
+		item := TaskOverview{Task: task}
+		page, err := s.scheduler.GetRunHistory(types.RunQuery{TaskID: task.ID, Statuses: finishedStatuses, Limit: 1})
+		if err != nil {
+			writeSchedulerError(w, err)
+			return
+		}
+		if len(page.Runs) > 0 {
+			item.LastRun = page.Runs[0]
+		}
+		overview.Tasks = append(overview.Tasks, item)
+	}
+	if s.alerts != nil {
+		for _, a := range s.alerts.Alerts() {
+			if a.State == alert.StateFiring && !a.Silenced {
+				overview.FiringAlerts++
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, overview)
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+const (
+	// defaultRunLimit 运行列表默认每页条数
+	defaultRunLimit = 50
+	// maxRunLimit 运行列表每页条数上限
+	maxRunLimit = 1000
+)
+
+// routeRuns 运行记录相关路由
+func (s *Server) routeRuns(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 0 || segments[0] == "":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		s.listRuns(w, r)
+	case len(segments) == 1:
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		run, err := s.scheduler.GetStore().GetRun(segments[0])
+		if err != nil {
+			writeSchedulerError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, run)
+	case len(segments) == 2 && segments[1] == "logs":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		s.runLogs(w, r, segments[0])
+	case len(segments) == 2 && segments[1] == "cancel":
+		if !allowMethods(w, r, http.MethodPost) {
+			return
+		}
+		s.cancelRun(w, segments[0])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+	}
+}
+
+// listRuns 按条件分页查询运行历史
+// 查询参数：task、executor、status（逗号分隔的名称或数值）、from/to（RFC3339）、offset、limit
+func (s *Server) listRuns(w http.ResponseWriter, r *http.Request) {
+	query, err := parseRunQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	page, err := s.scheduler.GetRunHistory(query)
+	if err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// cancelRun 请求取消执行中的运行，取消是异步的，返回当前的运行记录
+func (s *Server) cancelRun(w http.ResponseWriter, runID string) {
+	if err := s.scheduler.CancelRun(runID); err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	run, err := s.scheduler.GetStore().GetRun(runID)
+	if err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// parseRunQuery 解析运行查询参数
+func parseRunQuery(values url.Values) (types.RunQuery, error) {
+	query := types.RunQuery{
+		TaskID:     values.Get("task"),
+		ExecutorID: values.Get("executor"),
+		Limit:      defaultRunLimit,
+	}
+
+	if statuses := values.Get("status"); statuses != "" {
+		for _, name := range strings.Split(statuses, ",") {
+			status, err := types.ParseRunStatus(strings.TrimSpace(name))
+			if err != nil {
+				return query, err
+			}
+			query.Statuses = append(query.Statuses, status)
+		}
+	}
+
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &query.From}, {"to", &query.To}} {
+		if value := values.Get(param.name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s: %v", param.name, err)
+			}
+			*param.target = t
+		}
+	}
+
+	for _, param := range []struct {
+		name   string
+		target *int
+	}{{"offset", &query.Offset}, {"limit", &query.Limit}} {
+		if value := values.Get(param.name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return query, fmt.Errorf("invalid %s %q", param.name, value)
+			}
+			*param.target = n
+		}
+	}
+	if query.Limit == 0 || query.Limit > maxRunLimit {
+		query.Limit = maxRunLimit
+	}
+	return query, nil
+}
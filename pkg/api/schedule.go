@@ -0,0 +1,150 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"task_scheduler/pkg/scheduler"
+)
+
+const (
+	// defaultPreviewCount 预览默认计算的触发次数
+	defaultPreviewCount = 5
+	// defaultTimelineWindow 时间线默认的时间段长度
+	defaultTimelineWindow = 24 * time.Hour
+	// maxTimelineLimit 时间线最多返回的触发数
+	maxTimelineLimit = 10000
+)
+
+// parsePreview 解析预览参数：n（次数，默认5，上限 scheduler.MaxPreviewFireTimes）、from（RFC3339，默认当前时间）
+func parsePreview(values url.Values) (time.Time, int, error) {
+	from := time.Now()
+	if value := values.Get("from"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return from, 0, fmt.Errorf("invalid from: %v", err)
+		}
+		from = t
+	}
+	n := defaultPreviewCount
+	if value := values.Get("n"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > scheduler.MaxPreviewFireTimes {
+			return from, 0, fmt.Errorf("invalid n %q (expected 1-%d)", value, scheduler.MaxPreviewFireTimes)
+		}
+		n = parsed
+	}
+	return from, n, nil
+}
+
+// previewTask 任务接下来的触发时间，暂停的任务按恢复后的计划计算
+func (s *Server) previewTask(w http.ResponseWriter, r *http.Request, taskID string) {
+	from, n, err := parsePreview(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	task, err := s.scheduler.GetTask(taskID)
+	if err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	fireTimes, err := s.scheduler.PreviewTask(taskID, from, n)
+	if err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, FireTimesResponse{TaskID: taskID, Cron: task.Cron, FireTimes: fireTimes})
+}
+
+// routeCron cron表达式工具路由，不涉及任何任务
+func (s *Server) routeCron(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) != 1 || segments[0] != "next" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+		return
+	}
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	values := r.URL.Query()
+	expr := values.Get("expr")
+	if expr == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("expr is required"))
+		return
+	}
+	from, n, err := parsePreview(values)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	fireTimes, err := scheduler.NextFireTimes(expr, from, n)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, FireTimesResponse{Cron: expr, FireTimes: fireTimes})
+}
+
+// getTimeline 各任务在一个时间段内合并后的计划触发
+// 查询参数：from（RFC3339，默认当前时间）、to（默认 from 后24小时）、task（逗号分隔）、paused（是否包含暂停的任务）、limit
+func (s *Server) getTimeline(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	from := time.Now()
+	var to time.Time
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		if value := values.Get(param.name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid %s: %v", param.name, err))
+				return
+			}
+			*param.target = t
+		}
+	}
+	if to.IsZero() {
+		to = from.Add(defaultTimelineWindow)
+	}
+	if !from.Before(to) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("from must be before to"))
+		return
+	}
+
+	var opts scheduler.TimelineOptions
+	if tasks := values.Get("task"); tasks != "" {
+		opts.TaskIDs = strings.Split(tasks, ",")
+	}
+	if value := values.Get("paused"); value != "" {
+		paused, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid paused %q", value))
+			return
+		}
+		opts.IncludePaused = paused
+	}
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", value))
+			return
+		}
+		opts.Limit = limit
+	}
+	if opts.Limit > maxTimelineLimit {
+		opts.Limit = maxTimelineLimit
+	}
+
+	timeline, err := s.scheduler.Timeline(from, to, opts)
+	if err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, timeline)
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/executor"
+	"task_scheduler/pkg/scheduler"
+	"task_scheduler/pkg/types"
+	"task_scheduler/pkg/web"
+)
+
+// PathPrefix 管理接口的路径前缀
+const PathPrefix = "/api/v1/"
+
+// maxBodySize 请求体大小上限
+const maxBodySize = 1 << 20
+
+// ExecutorFactory 根据ID和地址创建执行器，用于通过接口添加执行器
+type ExecutorFactory func(id, address string) (types.Executor, error)
+
+// Option 服务配置项
+type Option func(*Server)
+
+// WithExecutorFactory 设置添加执行器时使用的工厂，默认创建 SimpleExecutor
+func WithExecutorFactory(factory ExecutorFactory) Option {
+	return func(s *Server) {
+		s.newExecutor = factory
+	}
+}
+
+// WithAlerts 提供告警与静默接口，未设置时这些接口返回404
+func WithAlerts(manager *alert.Manager) Option {
+	return func(s *Server) {
+		s.alerts = manager
+	}
+}
+
+// Server 调度器的JSON管理接口
+type Server struct {
+	scheduler   *scheduler.TaskScheduler
+	newExecutor ExecutorFactory
+	alerts      *alert.Manager
+	dashboard   http.Handler
+	done        chan struct{} // 关闭后结束所有事件流
+	closeOnce   sync.Once
+}
+
+// NewServer 创建管理接口
+func NewServer(ts *scheduler.TaskScheduler, opts ...Option) *Server {
+	s := &Server{
+		scheduler: ts,
+		newExecutor: func(id, address string) (types.Executor, error) {
+			return executor.NewSimpleExecutor(id, address), nil
+		},
+		dashboard: http.StripPrefix(strings.TrimSuffix(web.PathPrefix, "/"), web.Handler()),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ServeHTTP 按路径分发请求
+//
+//	GET    /healthz
+//	GET    /metrics                           (Prometheus text format)
+//	GET    /ui/                               (embedded dashboard, / redirects here)
+//	GET    /api/v1/tasks                      POST /api/v1/tasks
+//	GET    /api/v1/tasks/{id}                 PUT  /api/v1/tasks/{id}    DELETE /api/v1/tasks/{id}
+//	POST   /api/v1/tasks/{id}/pause|resume|trigger
+//	POST   /api/v1/tasks/{id}/backfill
+//	GET    /api/v1/tasks/{id}/next            GET  /api/v1/cron/next     GET /api/v1/timeline
+//	GET    /api/v1/runs                       GET  /api/v1/runs/{id}
+//	GET    /api/v1/runs/{id}/logs             POST /api/v1/runs/{id}/cancel
+//	GET    /api/v1/executors                  POST /api/v1/executors
+//	DELETE /api/v1/executors/{id}             POST /api/v1/executors/{id}/drain
+//	GET    /api/v1/stats                      GET  /api/v1/overview
+//	GET    /api/v1/alerts                     GET  /api/v1/alerts/rules
+//	GET    /api/v1/silences                   POST /api/v1/silences      DELETE /api/v1/silences/{id}
+//	GET    /api/v1/events                     (Server-Sent Events)
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/healthz":
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		return
+	case "/metrics":
+		if allowMethods(w, r, http.MethodGet) {
+			s.scheduler.Metrics().ServeHTTP(w, r)
+		}
+		return
+	case "/", strings.TrimSuffix(web.PathPrefix, "/"):
+		http.Redirect(w, r, web.PathPrefix, http.StatusFound)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, web.PathPrefix) {
+		if allowMethods(w, r, http.MethodGet, http.MethodHead) {
+			s.dashboard.ServeHTTP(w, r)
+		}
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, PathPrefix) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+		return
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, PathPrefix), "/"), "/")
+	switch segments[0] {
+	case "tasks":
+		s.routeTasks(w, r, segments[1:])
+	case "runs":
+		s.routeRuns(w, r, segments[1:])
+	case "executors":
+		s.routeExecutors(w, r, segments[1:])
+	case "alerts":
+		s.routeAlerts(w, r, segments[1:])
+	case "silences":
+		s.routeSilences(w, r, segments[1:])
+	case "events":
+		if len(segments) != 1 {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+			return
+		}
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		s.streamEvents(w, r)
+	case "stats":
+		if len(segments) != 1 {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+			return
+		}
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		s.getStats(w, r)
+	case "overview":
+		if len(segments) != 1 {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+			return
+		}
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		s.getOverview(w)
+	case "timeline":
+		if len(segments) != 1 {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+			return
+		}
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		s.getTimeline(w, r)
+	case "cron":
+		s.routeCron(w, r, segments[1:])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+	}
+}
+
+// Close 结束所有事件流；长连接不会因 http.Server.Shutdown 自行结束，
+// 应通过 RegisterOnShutdown 注册本方法
+func (s *Server) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// getStats 任务与执行器统计
+func (s *Server) getStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, StatsResponse{
+		Tasks:     s.scheduler.GetTaskStats(),
+		Executors: s.scheduler.GetExecutorStats(),
+		Fairness:  s.scheduler.GetFairnessStats(),
+	})
+}
+
+// allowMethods 检查请求方法，不允许时返回405
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// decodeBody 解析JSON请求体，拒绝未知字段
+func decodeBody(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// writeJSON 写入JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v == nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write API response: %v", err)
+	}
+}
+
+// writeError 写入错误响应
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+// writeSchedulerError 按错误类型映射状态码
+func writeSchedulerError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, types.ErrInvalidTask), errors.Is(err, types.ErrInvalidBackfill):
+		status = http.StatusBadRequest
+	case errors.Is(err, types.ErrTaskNotFound), errors.Is(err, types.ErrRunNotFound),
+		errors.Is(err, types.ErrExecutorNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, types.ErrTaskExists), errors.Is(err, types.ErrExecutorExists),
+		errors.Is(err, types.ErrTaskBusy), errors.Is(err, types.ErrRunFinished):
+		status = http.StatusConflict
+	}
+	writeError(w, status, err)
+}
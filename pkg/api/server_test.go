@@ -0,0 +1,544 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/executor"
+	"task_scheduler/pkg/scheduler"
+	"task_scheduler/pkg/types"
+)
+
+// 创建带执行器的测试服务
+func createTestServer(t *testing.T) (*scheduler.TaskScheduler, *httptest.Server) {
+	t.Helper()
+	ts := scheduler.New(&types.SchedulerConfig{
+		MaxConcurrentTasks:  10,
+		HealthCheckInterval: time.Hour,
+		DefaultStrategy:     types.RoundRobinApp,
+	})
+	for _, id := range []string{"exec-1", "exec-2"} {
+		ts.AddExecutor(executor.NewSimpleExecutor(id, "http://localhost"))
+	}
+	server := httptest.NewServer(NewServer(ts))
+	t.Cleanup(server.Close)
+	return ts, server
+}
+
+// do 发送请求并解析JSON响应
+func do(t *testing.T, method, url string, body interface{}, out interface{}) int {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, _ := http.NewRequest(method, url, reader)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp.StatusCode
+}
+
+func TestTaskLifecycle(t *testing.T) {
+	ts, server := createTestServer(t)
+	base := server.URL + PathPrefix
+
+	task := map[string]interface{}{"id": "report", "cron": "0 0 2 * * *", "handler": "reportHandler"}
+	var created types.Task
+	if status := do(t, http.MethodPost, base+"tasks", task, &created); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	if created.Strategy != types.RoundRobinApp {
+		t.Errorf("default strategy should be applied, got %v", created.Strategy)
+	}
+	if status := do(t, http.MethodPost, base+"tasks", task, nil); status != http.StatusConflict {
+		t.Errorf("duplicate task should return 409, got %d", status)
+	}
+
+	// 校验失败
+	var errResp ErrorResponse
+	invalid := map[string]interface{}{"id": "bad", "cron": "not a cron", "handler": "h"}
+	if status := do(t, http.MethodPost, base+"tasks", invalid, &errResp); status != http.StatusBadRequest || errResp.Error == "" {
+		t.Errorf("invalid cron should return 400 with a message, got %d %+v", status, errResp)
+	}
+	if status := do(t, http.MethodPost, base+"tasks", map[string]interface{}{"id": "x", "unknown": 1}, nil); status != http.StatusBadRequest {
+		t.Errorf("unknown fields should return 400, got %d", status)
+	}
+
+	// PUT 替换定义
+	task["strategy"] = int(types.LeastOutstanding)
+	var updated types.Task
+	if status := do(t, http.MethodPut, base+"tasks/report", task, &updated); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if updated.Strategy != types.LeastOutstanding || !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("update should replace the definition and keep created_at: %+v", updated)
+	}
+
+	var paused types.Task
+	if status := do(t, http.MethodPost, base+"tasks/report/pause", nil, &paused); status != http.StatusOK || paused.Status != types.TaskStatusPaused {
+		t.Errorf("pause failed: %d %+v", status, paused)
+	}
+	// 暂停的任务可以手动触发
+	var run types.Run
+	if status := do(t, http.MethodPost, base+"tasks/report/trigger", nil, &run); status != http.StatusAccepted || run.ID == "" {
+		t.Fatalf("trigger failed: %d %+v", status, run)
+	}
+	if run.Trigger != types.RunTriggerManual || run.ExecutorID == "" {
+		t.Errorf("unexpected triggered run: %+v", run)
+	}
+	var resumed types.Task
+	do(t, http.MethodPost, base+"tasks/report/resume", nil, &resumed)
+	if resumed.Status == types.TaskStatusPaused {
+		t.Error("resume should clear the paused status")
+	}
+
+	var fetched types.Run
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		do(t, http.MethodGet, base+"runs/"+run.ID, nil, &fetched)
+		if fetched.Status == types.RunStatusSucceeded {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if fetched.Status != types.RunStatusSucceeded {
+		t.Errorf("triggered run should succeed, got %+v", fetched)
+	}
+
+	var page types.RunPage
+	if status := do(t, http.MethodGet, base+"runs?task=report&status=succeeded,failed", nil, &page); status != http.StatusOK || page.Total != 1 {
+		t.Errorf("run query failed: %d %+v", status, page)
+	}
+	if status := do(t, http.MethodGet, base+"runs?status=bogus", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("invalid status filter should return 400, got %d", status)
+	}
+
+	if status := do(t, http.MethodDelete, base+"tasks/report", nil, nil); status != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+	if status := do(t, http.MethodGet, base+"tasks/report", nil, nil); status != http.StatusNotFound {
+		t.Errorf("deleted task should return 404, got %d", status)
+	}
+	if len(ts.GetTasks()) != 0 {
+		t.Error("task should be removed from the scheduler")
+	}
+}
+
+func TestExecutorEndpoints(t *testing.T) {
+	ts, server := createTestServer(t)
+	base := server.URL + PathPrefix
+
+	var info ExecutorInfo
+	if status := do(t, http.MethodPost, base+"executors", AddExecutorRequest{ID: "exec-3", Address: "http://10.0.0.3"}, &info); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	if status := do(t, http.MethodPost, base+"executors", AddExecutorRequest{ID: "exec-3", Address: "x"}, nil); status != http.StatusConflict {
+		t.Errorf("duplicate executor should return 409, got %d", status)
+	}
+	if status := do(t, http.MethodPost, base+"executors", AddExecutorRequest{ID: "exec-4"}, nil); status != http.StatusBadRequest {
+		t.Errorf("missing address should return 400, got %d", status)
+	}
+
+	if status := do(t, http.MethodPost, base+"executors/exec-1/drain", nil, &info); status != http.StatusOK || !info.Draining {
+		t.Fatalf("drain failed: %d %+v", status, info)
+	}
+	for _, exec := range ts.GetExecutors() {
+		if exec.GetID() == "exec-1" {
+			t.Error("draining executor should not receive new runs")
+		}
+	}
+
+	var infos []ExecutorInfo
+	do(t, http.MethodGet, base+"executors", nil, &infos)
+	if len(infos) != 3 || infos[0].ID != "exec-1" || !infos[0].Draining {
+		t.Errorf("list should include draining executors: %+v", infos)
+	}
+
+	if status := do(t, http.MethodDelete, base+"executors/exec-1", nil, nil); status != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+	if status := do(t, http.MethodDelete, base+"executors/exec-1", nil, nil); status != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", status)
+	}
+
+	var stats StatsResponse
+	if status := do(t, http.MethodGet, base+"stats", nil, &stats); status != http.StatusOK || len(stats.Executors) != 2 {
+		t.Errorf("stats failed: %d %+v", status, stats)
+	}
+	if status := do(t, http.MethodDelete, base+"stats", nil, nil); status != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", status)
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	ts, server := createTestServer(t)
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h"})
+	ts.AddTask(&types.Task{ID: "other", Cron: "0 0 2 * * *", Handler: "h"})
+
+	if status := do(t, http.MethodGet, server.URL+PathPrefix+"events?type=bogus", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("unknown event type should return 400, got %d", status)
+	}
+
+	resp, err := http.Get(server.URL + PathPrefix + "events?task=report&type=task_paused,task_resumed")
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+	// 读到订阅确认后再产生事件
+	if line, _ := reader.ReadString('\n'); line != ": subscribed\n" {
+		t.Fatalf("unexpected first line %q", line)
+	}
+
+	ts.PauseTask("other")
+	ts.PauseTask("report")
+	ts.ResumeTask("report")
+
+	var names []string
+	for len(names) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if name, ok := strings.CutPrefix(strings.TrimSpace(line), "event: "); ok {
+			names = append(names, name)
+		}
+		if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: "); ok {
+			var event types.Event
+			if err := json.Unmarshal([]byte(data), &event); err != nil || event.TaskID != "report" || event.Task == nil {
+				t.Errorf("unexpected event data %q", data)
+			}
+		}
+	}
+	if names[0] != "task_paused" || names[1] != "task_resumed" {
+		t.Errorf("unexpected events %v", names)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	ts, server := createTestServer(t)
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h", Strategy: types.RoundRobinApp})
+	run, _ := ts.TriggerTask("report")
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if fetched, _ := ts.GetStore().GetRun(run.ID); fetched.Status.Finished() {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	for _, line := range []string{
+		fmt.Sprintf(`task_scheduler_runs_total{task="report",status="succeeded",executor="%s",strategy="round_robin_app"} 1`, run.ExecutorID),
+		fmt.Sprintf(`task_scheduler_router_selections_total{executor="%s",strategy="round_robin_app"} 1`, run.ExecutorID),
+		`task_scheduler_run_duration_seconds_count{task="report",status="succeeded"} 1`,
+		`task_scheduler_schedule_lag_seconds_count{task="report",trigger="manual"} 1`,
+		`task_scheduler_queue_depth{task="report"} 0`,
+		`task_scheduler_runs_in_flight{executor="exec-1"} 0`,
+		`task_scheduler_executor_healthy{executor="exec-2",draining="false"} 1`,
+		`# TYPE task_scheduler_tasks gauge`,
+		`# TYPE task_scheduler_executor_heartbeat_age_seconds gauge`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("metrics output missing %q", line)
+		}
+	}
+}
+
+func TestRunLogs(t *testing.T) {
+	ts := scheduler.New(&types.SchedulerConfig{HealthCheckInterval: time.Hour})
+	server := httptest.NewServer(NewServer(ts))
+	defer server.Close()
+	base := server.URL + PathPrefix
+
+	// 远程执行器分块返回日志，第二行在测试放行后才返回
+	proceed := make(chan struct{})
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := executor.DecodeRunEnvelope(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stream := executor.NewRunStream(w)
+		stream.Log("stdout", "step 1")
+		<-proceed
+		stream.Log("stderr", "step 2")
+		stream.Finish(fmt.Errorf("exit status 1"))
+	}))
+	defer worker.Close()
+	ts.AddExecutor(executor.NewHTTPExecutor("remote-1", worker.URL))
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h"})
+
+	run, err := ts.TriggerTask("report")
+	if err != nil {
+		t.Fatalf("TriggerTask failed: %v", err)
+	}
+
+	resp, err := http.Get(base + "runs/" + run.ID + "/logs?follow=true")
+	if err != nil {
+		t.Fatalf("follow failed: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended before the first executor line: %v", err)
+		}
+		if strings.HasSuffix(line, "[stdout] step 1\n") {
+			break
+		}
+	}
+	close(proceed)
+	rest, _ := io.ReadAll(reader)
+	if !strings.Contains(string(rest), "[stderr] step 2") || !strings.HasSuffix(string(rest), "[scheduler] failed: exit status 1\n") {
+		t.Errorf("unexpected followed logs: %q", rest)
+	}
+
+	resp, err = http.Get(base + "runs/" + run.ID + "/logs?tail=1&format=json")
+	if err != nil {
+		t.Fatalf("tail failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var last struct {
+		Seq    int64
+		Stream string
+		Text   string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&last); err != nil || last.Stream != "scheduler" || last.Text != "failed: exit status 1" {
+		t.Errorf("unexpected tail: %+v %v", last, err)
+	}
+	if dropped := resp.Header.Get("X-Run-Log-Dropped"); dropped != fmt.Sprint(last.Seq-1) {
+		t.Errorf("unexpected dropped header %q for seq %d", dropped, last.Seq)
+	}
+
+	if status := do(t, http.MethodGet, base+"runs/"+run.ID+"/logs?tail=x", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("invalid tail should be rejected, got %d", status)
+	}
+	if status := do(t, http.MethodGet, base+"runs/missing/logs", nil, nil); status != http.StatusNotFound {
+		t.Errorf("unknown run should return 404, got %d", status)
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	_, plain := createTestServer(t)
+	if status := do(t, http.MethodGet, plain.URL+PathPrefix+"alerts", nil, nil); status != http.StatusNotFound {
+		t.Errorf("alerts without a manager should return 404, got %d", status)
+	}
+
+	ts := scheduler.New(&types.SchedulerConfig{HealthCheckInterval: time.Hour})
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "disk full", http.StatusInternalServerError)
+	}))
+	defer worker.Close()
+	ts.AddExecutor(executor.NewHTTPExecutor("remote-1", worker.URL))
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h"})
+
+	manager, err := alert.NewManager(ts, []alert.Rule{{Name: "failing", Type: alert.RuleConsecutiveFailures, Threshold: 1}},
+		alert.WithInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	manager.Start()
+	defer manager.Stop()
+	server := httptest.NewServer(NewServer(ts, WithAlerts(manager)))
+	defer server.Close()
+	base := server.URL + PathPrefix
+
+	if _, err := ts.TriggerTask("report"); err != nil {
+		t.Fatalf("TriggerTask failed: %v", err)
+	}
+	// 运行结束事件触发评估
+	var alerts []alert.Alert
+	deadline := time.Now().Add(5 * time.Second)
+	for len(alerts) == 0 && time.Now().Before(deadline) {
+		do(t, http.MethodGet, base+"alerts?state=firing&task=report", nil, &alerts)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(alerts) != 1 || alerts[0].ID != "failing/report" || !strings.Contains(alerts[0].Summary, "disk full") {
+		t.Fatalf("unexpected alerts: %+v", alerts)
+	}
+	var rules []alert.Rule
+	if status := do(t, http.MethodGet, base+"alerts/rules", nil, &rules); status != http.StatusOK || len(rules) != 1 {
+		t.Errorf("unexpected rules: %d %+v", status, rules)
+	}
+	if status := do(t, http.MethodGet, base+"alerts?state=pending", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("invalid state should be rejected, got %d", status)
+	}
+
+	var silence alert.Silence
+	status := do(t, http.MethodPost, base+"silences", map[string]string{"task_id": "report", "duration": "2h", "comment": "known issue"}, &silence)
+	if status != http.StatusCreated || silence.ID == "" || silence.EndsAt.Sub(silence.StartsAt) != 2*time.Hour {
+		t.Fatalf("unexpected silence: %d %+v", status, silence)
+	}
+	do(t, http.MethodGet, base+"alerts", nil, &alerts)
+	if len(alerts) != 1 || !alerts[0].Silenced {
+		t.Errorf("alert should be silenced: %+v", alerts)
+	}
+	if status := do(t, http.MethodPost, base+"silences", map[string]string{"rule": "failing"}, nil); status != http.StatusBadRequest {
+		t.Errorf("silence without an end should be rejected, got %d", status)
+	}
+	if status := do(t, http.MethodDelete, base+"silences/"+silence.ID, nil, &silence); status != http.StatusOK || silence.Active(time.Now()) {
+		t.Errorf("unexpected expired silence: %d %+v", status, silence)
+	}
+	if status := do(t, http.MethodDelete, base+"silences/missing", nil, nil); status != http.StatusNotFound {
+		t.Errorf("unknown silence should return 404, got %d", status)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	ts, server := createTestServer(t)
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h"})
+
+	// 根路径重定向到仪表盘
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.Request.URL.Path != "/ui/" || !strings.Contains(string(page), "app.js") {
+		t.Errorf("/ should redirect to the dashboard, got %s", resp.Request.URL)
+	}
+	resp, err = http.Get(server.URL + "/ui/app.js")
+	if err != nil {
+		t.Fatalf("GET app.js failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), "javascript") {
+		t.Errorf("unexpected asset response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if status := do(t, http.MethodPost, server.URL+"/ui/", nil, nil); status != http.StatusMethodNotAllowed {
+		t.Errorf("dashboard should only accept GET, got %d", status)
+	}
+
+	run, err := ts.TriggerTask("report")
+	if err != nil {
+		t.Fatalf("TriggerTask failed: %v", err)
+	}
+	var overview OverviewResponse
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		overview = OverviewResponse{}
+		do(t, http.MethodGet, server.URL+PathPrefix+"overview", nil, &overview)
+		if len(overview.Tasks) == 1 && overview.Tasks[0].LastRun != nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(overview.Tasks) != 1 || overview.Tasks[0].ID != "report" || overview.Tasks[0].LastRun == nil ||
+		overview.Tasks[0].LastRun.ID != run.ID || !overview.Tasks[0].LastRun.Status.Finished() {
+		t.Fatalf("unexpected overview tasks: %+v", overview.Tasks)
+	}
+	if len(overview.Executors) != 2 || overview.FiringAlerts != 0 {
+		t.Errorf("unexpected overview: %+v", overview)
+	}
+}
+
+func TestSchedulePreview(t *testing.T) {
+	ts, server := createTestServer(t)
+	base := server.URL + PathPrefix
+	ts.AddTask(&types.Task{ID: "hourly", Cron: "0 0 * * * *", Handler: "h"})
+	ts.AddTask(&types.Task{ID: "half-past", Cron: "0 30 * * * *", Handler: "h"})
+
+	var task types.Task
+	do(t, http.MethodGet, base+"tasks/hourly", nil, &task)
+	if !task.NextRunTime.Equal(time.Now().Truncate(time.Hour).Add(time.Hour)) {
+		t.Errorf("next run time should be populated, got %v", task.NextRunTime)
+	}
+
+	from := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	var preview FireTimesResponse
+	if status := do(t, http.MethodGet, base+"tasks/half-past/next?n=3&from="+from, nil, &preview); status != http.StatusOK ||
+		preview.Cron != "0 30 * * * *" || len(preview.FireTimes) != 3 || preview.FireTimes[0].Minute() != 30 {
+		t.Errorf("unexpected task preview: %d %+v", status, preview)
+	}
+	if status := do(t, http.MethodGet, base+"tasks/missing/next", nil, nil); status != http.StatusNotFound {
+		t.Errorf("unknown task should return 404, got %d", status)
+	}
+	if status := do(t, http.MethodGet, base+"cron/next?expr=0+0+2+*+*+MON&n=2", nil, &preview); status != http.StatusOK ||
+		len(preview.FireTimes) != 2 || preview.FireTimes[0].Weekday() != time.Monday {
+		t.Errorf("unexpected cron preview: %d %+v", status, preview)
+	}
+	for _, query := range []string{"cron/next?expr=not+a+cron", "cron/next?expr=*+*+*+*+*+*&n=0", "cron/next"} {
+		if status := do(t, http.MethodGet, base+query, nil, nil); status != http.StatusBadRequest {
+			t.Errorf("%s should return 400, got %d", query, status)
+		}
+	}
+
+	var timeline scheduler.Timeline
+	status := do(t, http.MethodGet, base+"timeline?from="+from+"&to="+time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC).Format(time.RFC3339), nil, &timeline)
+	if status != http.StatusOK || len(timeline.Fires) != 4 || timeline.Fires[0].TaskID != "hourly" || timeline.Fires[1].TaskID != "half-past" {
+		t.Errorf("unexpected timeline: %d %+v", status, timeline)
+	}
+	do(t, http.MethodGet, base+"timeline?task=hourly&limit=3", nil, &timeline)
+	if len(timeline.Fires) != 3 || !timeline.Truncated || !timeline.To.Equal(timeline.From.Add(24*time.Hour)) {
+		t.Errorf("unexpected limited timeline: %+v", timeline)
+	}
+	if status := do(t, http.MethodGet, base+"timeline?from="+from+"&to="+from, nil, nil); status != http.StatusBadRequest {
+		t.Errorf("empty window should return 400, got %d", status)
+	}
+}
+
+func TestBackfillEndpoint(t *testing.T) {
+	ts, server := createTestServer(t)
+	base := server.URL + PathPrefix
+	ts.AddTask(&types.Task{ID: "daily", Cron: "0 0 2 * * *", Handler: "h", MaxConcurrency: 2})
+	ts.AddTask(&types.Task{ID: "manual", Handler: "h"})
+
+	to := time.Now().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, -5)
+	var resp BackfillResponse
+	status := do(t, http.MethodPost, base+"tasks/daily/backfill", BackfillRequest{From: from, To: to, MaxParallelism: 2, Wait: true}, &resp)
+	if status != http.StatusOK || len(resp.FireTimes) != 5 || resp.Result == nil || resp.Result.Succeeded != 5 {
+		t.Fatalf("unexpected backfill response: %d %+v", status, resp)
+	}
+	page, _ := ts.GetRunHistory(types.RunQuery{TaskID: "daily"})
+	if page.Total != 5 || page.Runs[0].Trigger != types.RunTriggerBackfill {
+		t.Errorf("backfill runs should be recorded by the serving scheduler, got %+v", page)
+	}
+
+	var accepted BackfillResponse
+	if status := do(t, http.MethodPost, base+"tasks/daily/backfill", BackfillRequest{From: from, To: to}, &accepted); status != http.StatusAccepted || accepted.Result != nil {
+		t.Errorf("backfill without wait should return 202, got %d %+v", status, accepted)
+	}
+	for path, req := range map[string]BackfillRequest{
+		"tasks/daily/backfill":  {To: to},
+		"tasks/manual/backfill": {From: from, To: to},
+	} {
+		if status := do(t, http.MethodPost, base+path, req, nil); status != http.StatusBadRequest {
+			t.Errorf("%s %+v should return 400, got %d", path, req, status)
+		}
+	}
+	if status := do(t, http.MethodPost, base+"tasks/missing/backfill", BackfillRequest{From: from}, nil); status != http.StatusNotFound {
+		t.Errorf("unknown task should return 404, got %d", status)
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"task_scheduler/pkg/types"
+)
+
+// routeTasks 任务相关路由
+func (s *Server) routeTasks(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 0 || segments[0] == "":
+		if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+			return
+		}
+		if r.Method == http.MethodGet {
+			writeJSON(w, http.StatusOK, s.scheduler.GetTasks())
+		} else {
+			s.createTask(w, r)
+		}
+	case len(segments) == 1:
+		if !allowMethods(w, r, http.MethodGet, http.MethodPut, http.MethodDelete) {
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			s.getTask(w, segments[0])
+		case http.MethodPut:
+			s.putTask(w, r, segments[0])
+		case http.MethodDelete:
+			s.deleteTask(w, segments[0])
+		}
+	case len(segments) == 2 && segments[1] == "next":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		s.previewTask(w, r, segments[0])
+	case len(segments) == 2 && segments[1] == "backfill":
+		if !allowMethods(w, r, http.MethodPost) {
+			return
+		}
+		s.backfillTask(w, r, segments[0])
+	case len(segments) == 2:
+		if !allowMethods(w, r, http.MethodPost) {
+			return
+		}
+		s.taskAction(w, segments[0], segments[1])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s", r.URL.Path))
+	}
+}
+
+// createTask 创建任务，任务已存在时返回409
+func (s *Server) createTask(w http.ResponseWriter, r *http.Request) {
+	var task types.Task
+	if err := decodeBody(r, &task); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.scheduler.AddTask(&task); err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	s.getTaskWithStatus(w, task.ID, http.StatusCreated)
+}
+
+// putTask 创建或替换任务定义
+func (s *Server) putTask(w http.ResponseWriter, r *http.Request, taskID string) {
+	var task types.Task
+	if err := decodeBody(r, &task); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if task.ID == "" {
+		task.ID = taskID
+	}
+	if task.ID != taskID {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("task id %q does not match path %q", task.ID, taskID))
+		return
+	}
+
+	err := s.scheduler.UpdateTask(&task)
+	if errors.Is(err, types.ErrTaskNotFound) {
+		if err := s.scheduler.AddTask(&task); err != nil {
+			writeSchedulerError(w, err)
+			return
+		}
+		s.getTaskWithStatus(w, taskID, http.StatusCreated)
+		return
+	}
+	if err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	s.getTaskWithStatus(w, taskID, http.StatusOK)
+}
+
+// getTask 获取任务
+func (s *Server) getTask(w http.ResponseWriter, taskID string) {
+	s.getTaskWithStatus(w, taskID, http.StatusOK)
+}
+
+// getTaskWithStatus 以给定状态码返回任务的当前定义
+func (s *Server) getTaskWithStatus(w http.ResponseWriter, taskID string, status int) {
+	task, err := s.scheduler.GetTask(taskID)
+	if err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	writeJSON(w, status, task)
+}
+
+// deleteTask 删除任务
+func (s *Server) deleteTask(w http.ResponseWriter, taskID string) {
+	if err := s.scheduler.RemoveTask(taskID); err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// taskAction 暂停、恢复或手动触发任务
+func (s *Server) taskAction(w http.ResponseWriter, taskID, action string) {
+	var err error
+	switch action {
+	case "pause":
+		err = s.scheduler.PauseTask(taskID)
+	case "resume":
+		err = s.scheduler.ResumeTask(taskID)
+	case "trigger":
+		run, err := s.scheduler.TriggerTask(taskID)
+		if err != nil {
+			writeSchedulerError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, run)
+		return
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown task action %q", action))
+		return
+	}
+
+	if err != nil {
+		writeSchedulerError(w, err)
+		return
+	}
+	s.getTask(w, taskID)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"time"
+
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/scheduler"
+	"task_scheduler/pkg/types"
+)
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// StatsResponse 统计信息响应
+type StatsResponse struct {
+	Tasks     *types.TaskStatsReport `json:"tasks"`
+	Executors []*types.ExecutorStats `json:"executors"`
+	// Fairness 各路由策略在各窗口内的负载均衡程度
+	Fairness []*types.StrategyFairness `json:"fairness"`
+}
+
+// ExecutorInfo 执行器信息
+type ExecutorInfo struct {
+	ID           string    `json:"id"`
+	Address      string    `json:"address"`
+	Healthy      bool      `json:"healthy"`
+	Draining     bool      `json:"draining"`
+	InFlight     int64     `json:"in_flight"`
+	UsageCount   int64     `json:"usage_count"`
+	LastUsedTime time.Time `json:"last_used_time"`
+}
+
+// AddExecutorRequest 添加执行器请求
+type AddExecutorRequest struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// CreateSilenceRequest 添加静默请求，Rule 和 TaskID 为空表示匹配所有告警
+// 结束时间由 EndsAt 或 Duration（从生效时起的时长，如 "2h"）给出
+type CreateSilenceRequest struct {
+	Rule      string         `json:"rule,omitempty"`
+	TaskID    string         `json:"task_id,omitempty"`
+	Comment   string         `json:"comment,omitempty"`
+	CreatedBy string         `json:"created_by,omitempty"`
+	StartsAt  time.Time      `json:"starts_at,omitempty"`
+	EndsAt    time.Time      `json:"ends_at,omitempty"`
+	Duration  alert.Duration `json:"duration,omitempty"`
+}
+
+// TaskOverview 任务及其最近一次结束的运行
+type TaskOverview struct {
+	*types.Task
+	LastRun *types.Run `json:"last_run,omitempty"`
+}
+
+// OverviewResponse 仪表盘概览：任务、执行器统计和告警中的告警数
+type OverviewResponse struct {
+	Tasks     []TaskOverview         `json:"tasks"`
+	Executors []*types.ExecutorStats `json:"executors"`
+	// FiringAlerts 告警中的告警数，未启用告警时为0
+	FiringAlerts int       `json:"firing_alerts"`
+	Time         time.Time `json:"time"`
+}
+
+// FireTimesResponse cron表达式或任务接下来的触发时间
+type FireTimesResponse struct {
+	TaskID    string      `json:"task_id,omitempty"`
+	Cron      string      `json:"cron"`
+	FireTimes []time.Time `json:"fire_times"`
+}
+
+// BackfillRequest 回填请求，回填 [From, To] 内的每一次cron触发，To 为空表示到当前时间
+type BackfillRequest struct {
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to,omitempty"`
+	MaxParallelism int       `json:"max_parallelism,omitempty"`
+	MaxRuns        int       `json:"max_runs,omitempty"`
+	// Wait 等待回填结束后返回结果；客户端断开时回填继续进行
+	Wait bool `json:"wait,omitempty"`
+}
+
+// BackfillResponse 回填作业，Result 只在等待回填结束时给出
+type BackfillResponse struct {
+	TaskID    string                    `json:"task_id"`
+	FireTimes []time.Time               `json:"fire_times"`
+	Result    *scheduler.BackfillResult `json:"result,omitempty"`
+}
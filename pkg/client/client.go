@@ -0,0 +1,407 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/api"
+	"task_scheduler/pkg/scheduler"
+	"task_scheduler/pkg/types"
+)
+
+// APIError 管理接口返回的错误
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+// Client 管理接口的HTTP客户端
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Option 客户端配置项
+type Option func(*Client)
+
+// WithHTTPClient 使用自定义的HTTP客户端
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New 创建客户端，server为调度器服务地址，如 http://localhost:8080
+func New(server string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(server, "/") + api.PathPrefix,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ListTasks 列出所有任务
+func (c *Client) ListTasks() ([]*types.Task, error) {
+	var tasks []*types.Task
+	err := c.do(http.MethodGet, "tasks", nil, &tasks)
+	return tasks, err
+}
+
+// GetTask 获取任务
+func (c *Client) GetTask(taskID string) (*types.Task, error) {
+	var task types.Task
+	if err := c.do(http.MethodGet, "tasks/"+url.PathEscape(taskID), nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ApplyTask 创建或替换任务定义
+func (c *Client) ApplyTask(task *types.Task) (*types.Task, error) {
+	var applied types.Task
+	if err := c.do(http.MethodPut, "tasks/"+url.PathEscape(task.ID), task, &applied); err != nil {
+		return nil, err
+	}
+	return &applied, nil
+}
+
+// DeleteTask 删除任务
+func (c *Client) DeleteTask(taskID string) error {
+	return c.do(http.MethodDelete, "tasks/"+url.PathEscape(taskID), nil, nil)
+}
+
+// PauseTask 暂停任务
+func (c *Client) PauseTask(taskID string) (*types.Task, error) {
+	return c.taskAction(taskID, "pause")
+}
+
+// ResumeTask 恢复任务
+func (c *Client) ResumeTask(taskID string) (*types.Task, error) {
+	return c.taskAction(taskID, "resume")
+}
+
+// TriggerTask 立即触发任务一次，返回派发的运行
+func (c *Client) TriggerTask(taskID string) (*types.Run, error) {
+	var run types.Run
+	if err := c.do(http.MethodPost, "tasks/"+url.PathEscape(taskID)+"/trigger", nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Backfill 在服务端回填任务在 [req.From, req.To] 内的每一次cron触发
+// req.Wait 为 true 时等待回填结束才返回，不受客户端超时限制
+func (c *Client) Backfill(taskID string, req api.BackfillRequest) (*api.BackfillResponse, error) {
+	waiter := *c
+	if req.Wait {
+		httpClient := *c.httpClient
+		httpClient.Timeout = 0
+		waiter.httpClient = &httpClient
+	}
+	var resp api.BackfillResponse
+	if err := waiter.do(http.MethodPost, "tasks/"+url.PathEscape(taskID)+"/backfill", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListRuns 按条件分页查询运行历史
+func (c *Client) ListRuns(query types.RunQuery) (*types.RunPage, error) {
+	values := url.Values{}
+	if query.TaskID != "" {
+		values.Set("task", query.TaskID)
+	}
+	if query.ExecutorID != "" {
+		values.Set("executor", query.ExecutorID)
+	}
+	if len(query.Statuses) > 0 {
+		names := make([]string, len(query.Statuses))
+		for i, status := range query.Statuses {
+			names[i] = status.String()
+		}
+		values.Set("status", strings.Join(names, ","))
+	}
+	if !query.From.IsZero() {
+		values.Set("from", query.From.Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		values.Set("to", query.To.Format(time.RFC3339))
+	}
+	if query.Offset > 0 {
+		values.Set("offset", strconv.Itoa(query.Offset))
+	}
+	if query.Limit > 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	path := "runs"
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+	var page types.RunPage
+	if err := c.do(http.MethodGet, path, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetRun 获取运行记录
+func (c *Client) GetRun(runID string) (*types.Run, error) {
+	var run types.Run
+	if err := c.do(http.MethodGet, "runs/"+url.PathEscape(runID), nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// RunLogs 获取运行日志
+func (c *Client) RunLogs(runID string) (string, error) {
+	resp, err := c.send(http.MethodGet, "runs/"+url.PathEscape(runID)+"/logs", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read logs: %v", err)
+	}
+	return string(data), nil
+}
+
+// RunLogOptions 读取运行日志的选项
+type RunLogOptions struct {
+	Tail   int  // 只输出最后 Tail 行，0表示全部
+	Follow bool // 运行结束前持续输出新日志
+}
+
+// StreamRunLogs 把运行日志写入 w；跟随时直到运行结束或连接断开才返回，不受客户端超时限制
+func (c *Client) StreamRunLogs(runID string, opts RunLogOptions, w io.Writer) error {
+	query := url.Values{}
+	if opts.Tail > 0 {
+		query.Set("tail", strconv.Itoa(opts.Tail))
+	}
+	if opts.Follow {
+		query.Set("follow", "true")
+	}
+	path := "runs/" + url.PathEscape(runID) + "/logs"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	streamer := *c
+	if opts.Follow {
+		httpClient := *c.httpClient
+		httpClient.Timeout = 0
+		streamer.httpClient = &httpClient
+	}
+	resp, err := streamer.send(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read logs: %v", err)
+	}
+	return nil
+}
+
+// CancelRun 取消执行中的运行
+func (c *Client) CancelRun(runID string) (*types.Run, error) {
+	var run types.Run
+	if err := c.do(http.MethodPost, "runs/"+url.PathEscape(runID)+"/cancel", nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListExecutors 列出所有执行器
+func (c *Client) ListExecutors() ([]api.ExecutorInfo, error) {
+	var infos []api.ExecutorInfo
+	err := c.do(http.MethodGet, "executors", nil, &infos)
+	return infos, err
+}
+
+// DrainExecutor 排空执行器
+func (c *Client) DrainExecutor(executorID string) (*api.ExecutorInfo, error) {
+	var info api.ExecutorInfo
+	if err := c.do(http.MethodPost, "executors/"+url.PathEscape(executorID)+"/drain", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// PreviewTask 任务在 from 之后的 n 次计划触发时间，from 为零值时从当前时间算起
+func (c *Client) PreviewTask(taskID string, from time.Time, n int) (*api.FireTimesResponse, error) {
+	return c.preview("tasks/"+url.PathEscape(taskID)+"/next", url.Values{}, from, n)
+}
+
+// PreviewCron 由服务端计算cron表达式在 from 之后的 n 次触发时间（使用服务端的时区）
+func (c *Client) PreviewCron(expr string, from time.Time, n int) (*api.FireTimesResponse, error) {
+	return c.preview("cron/next", url.Values{"expr": {expr}}, from, n)
+}
+
+// preview 请求触发时间预览
+func (c *Client) preview(path string, values url.Values, from time.Time, n int) (*api.FireTimesResponse, error) {
+	if !from.IsZero() {
+		values.Set("from", from.Format(time.RFC3339))
+	}
+	if n > 0 {
+		values.Set("n", strconv.Itoa(n))
+	}
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+	var preview api.FireTimesResponse
+	if err := c.do(http.MethodGet, path, nil, &preview); err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
+
+// Timeline 各任务在 [from, to) 内合并后的计划触发，from、to 为零值时使用服务端默认值
+func (c *Client) Timeline(from, to time.Time, opts scheduler.TimelineOptions) (*scheduler.Timeline, error) {
+	values := url.Values{}
+	if !from.IsZero() {
+		values.Set("from", from.Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		values.Set("to", to.Format(time.RFC3339))
+	}
+	if len(opts.TaskIDs) > 0 {
+		values.Set("task", strings.Join(opts.TaskIDs, ","))
+	}
+	if opts.IncludePaused {
+		values.Set("paused", "true")
+	}
+	if opts.Limit > 0 {
+		values.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	path := "timeline"
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+	var timeline scheduler.Timeline
+	if err := c.do(http.MethodGet, path, nil, &timeline); err != nil {
+		return nil, err
+	}
+	return &timeline, nil
+}
+
+// ListAlerts 列出告警，state 为空时包括最近恢复的告警
+func (c *Client) ListAlerts(state alert.State) ([]alert.Alert, error) {
+	path := "alerts"
+	if state != "" {
+		path += "?state=" + url.QueryEscape(string(state))
+	}
+	var alerts []alert.Alert
+	err := c.do(http.MethodGet, path, nil, &alerts)
+	return alerts, err
+}
+
+// ListAlertRules 列出告警规则
+func (c *Client) ListAlertRules() ([]alert.Rule, error) {
+	var rules []alert.Rule
+	err := c.do(http.MethodGet, "alerts/rules", nil, &rules)
+	return rules, err
+}
+
+// ListSilences 列出静默
+func (c *Client) ListSilences() ([]alert.Silence, error) {
+	var silences []alert.Silence
+	err := c.do(http.MethodGet, "silences", nil, &silences)
+	return silences, err
+}
+
+// CreateSilence 添加静默
+func (c *Client) CreateSilence(req api.CreateSilenceRequest) (*alert.Silence, error) {
+	var silence alert.Silence
+	if err := c.do(http.MethodPost, "silences", req, &silence); err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+// ExpireSilence 立即结束静默
+func (c *Client) ExpireSilence(silenceID string) (*alert.Silence, error) {
+	var silence alert.Silence
+	if err := c.do(http.MethodDelete, "silences/"+url.PathEscape(silenceID), nil, &silence); err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+// taskAction 执行任务的暂停、恢复操作
+func (c *Client) taskAction(taskID, action string) (*types.Task, error) {
+	var task types.Task
+	if err := c.do(http.MethodPost, "tasks/"+url.PathEscape(taskID)+"/"+action, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// do 发送请求并把JSON响应解析到out，out为nil时丢弃响应体
+func (c *Client) do(method, path string, body, out interface{}) error {
+	resp, err := c.send(method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response from %s %s: %v", method, path, err)
+	}
+	return nil
+}
+
+// send 发送请求，非2xx响应转换为APIError
+func (c *Client) send(method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	var errResp api.ErrorResponse
+	if data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10)); json.Unmarshal(data, &errResp) == nil && errResp.Error != "" {
+		apiErr.Message = errResp.Error
+	}
+	return nil, apiErr
+}
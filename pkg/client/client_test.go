@@ -0,0 +1,98 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"task_scheduler/pkg/api"
+	"task_scheduler/pkg/executor"
+	"task_scheduler/pkg/scheduler"
+	"task_scheduler/pkg/types"
+)
+
+func TestClient(t *testing.T) {
+	ts := scheduler.New(&types.SchedulerConfig{
+		MaxConcurrentTasks:  10,
+		HealthCheckInterval: time.Hour,
+		DefaultStrategy:     types.RoundRobinApp,
+	})
+	ts.AddExecutor(executor.NewSimpleExecutor("exec-1", "http://localhost"))
+	server := httptest.NewServer(api.NewServer(ts))
+	defer server.Close()
+	c := New(server.URL)
+
+	applied, err := c.ApplyTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "reportHandler"})
+	if err != nil || applied.Strategy != types.RoundRobinApp {
+		t.Fatalf("ApplyTask failed: %v %+v", err, applied)
+	}
+	// 再次apply替换定义
+	if applied, err = c.ApplyTask(&types.Task{ID: "report", Cron: "0 0 3 * * *", Handler: "reportHandler"}); err != nil || applied.Cron != "0 0 3 * * *" {
+		t.Fatalf("re-apply failed: %v %+v", err, applied)
+	}
+	if tasks, err := c.ListTasks(); err != nil || len(tasks) != 1 {
+		t.Errorf("ListTasks failed: %v %+v", err, tasks)
+	}
+	if task, err := c.PauseTask("report"); err != nil || task.Status != types.TaskStatusPaused {
+		t.Errorf("PauseTask failed: %v %+v", err, task)
+	}
+
+	run, err := c.TriggerTask("report")
+	if err != nil {
+		t.Fatalf("TriggerTask failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for run.Status != types.RunStatusSucceeded && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		if run, err = c.GetRun(run.ID); err != nil {
+			t.Fatalf("GetRun failed: %v", err)
+		}
+	}
+	page, err := c.ListRuns(types.RunQuery{TaskID: "report", Statuses: []types.RunStatus{types.RunStatusSucceeded}})
+	if err != nil || page.Total != 1 {
+		t.Errorf("ListRuns failed: %v %+v", err, page)
+	}
+	logs, err := c.RunLogs(run.ID)
+	if err != nil || !strings.Contains(logs, "started on executor exec-1") || !strings.Contains(logs, "succeeded") {
+		t.Errorf("unexpected run logs: %v %q", err, logs)
+	}
+
+	// 错误响应转换为APIError
+	var apiErr *APIError
+	if _, err := c.CancelRun(run.ID); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("cancelling a finished run should return 409, got %v", err)
+	}
+	if _, err := c.GetTask("missing"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message == "" {
+		t.Errorf("missing task should return 404 with a message, got %v", err)
+	}
+
+	end := time.Now().Truncate(24 * time.Hour)
+	backfill, err := c.Backfill("report", api.BackfillRequest{From: end.AddDate(0, 0, -2), To: end, Wait: true})
+	if err != nil || len(backfill.FireTimes) != 2 || backfill.Result == nil || backfill.Result.Succeeded != 2 {
+		t.Errorf("Backfill failed: %v %+v", err, backfill)
+	}
+
+	if info, err := c.DrainExecutor("exec-1"); err != nil || !info.Draining {
+		t.Errorf("DrainExecutor failed: %v %+v", err, info)
+	}
+	// 暂停的任务仍可预览，时间线默认不包含
+	from := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	if preview, err := c.PreviewTask("report", from, 2); err != nil || len(preview.FireTimes) != 2 || preview.FireTimes[0].Hour() != 3 {
+		t.Errorf("PreviewTask failed: %v %+v", err, preview)
+	}
+	if preview, err := c.PreviewCron("0 */15 * * * *", from, 3); err != nil || len(preview.FireTimes) != 3 || preview.FireTimes[2].Minute() != 45 {
+		t.Errorf("PreviewCron failed: %v %+v", err, preview)
+	}
+	if timeline, err := c.Timeline(from, from.Add(48*time.Hour), scheduler.TimelineOptions{}); err != nil || len(timeline.Fires) != 0 {
+		t.Errorf("paused task should be excluded from the timeline: %v %+v", err, timeline)
+	}
+	if timeline, err := c.Timeline(from, from.Add(48*time.Hour), scheduler.TimelineOptions{IncludePaused: true}); err != nil || len(timeline.Fires) != 2 {
+		t.Errorf("Timeline failed: %v %+v", err, timeline)
+	}
+	if err := c.DeleteTask("report"); err != nil {
+		t.Errorf("DeleteTask failed: %v", err)
+	}
+}
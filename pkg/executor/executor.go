@@ -1,11 +1,15 @@
 package executor
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/runlog"
 	"task_scheduler/pkg/types"
 )
 
@@ -55,6 +59,20 @@ func (e *SimpleExecutor) SetHealthy(healthy bool) {
 
 // Execute 执行任务
 func (e *SimpleExecutor) Execute(task *types.Task) error {
+	return e.ExecuteRun(e.logContext(task), task, nil)
+}
+
+// logContext 不经过调度器直接执行时，日志带上任务和执行器ID
+func (e *SimpleExecutor) logContext(task *types.Task) context.Context {
+	logger := slog.Default().With(logging.KeyTaskID, task.ID, logging.KeyExecutorID, e.id)
+	return logging.WithLogger(context.Background(), logger)
+}
+
+// ExecuteRun 执行任务的一次运行，run为空时按当前时间执行
+func (e *SimpleExecutor) ExecuteRun(ctx context.Context, task *types.Task, run *types.Run) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if !e.IsHealthy() {
 		return fmt.Errorf("executor %s is not healthy", e.id)
 	}
@@ -63,8 +81,17 @@ func (e *SimpleExecutor) Execute(task *types.Task) error {
 	e.IncrementUsage()
 	e.updateLastUsedTime()
 
-	// 模拟任务执行
-	fmt.Printf("Executor %s executing task %s (handler: %s)\n", e.id, task.ID, task.Handler)
+	// 模拟任务执行，调度器传入的上下文中的日志记录器带有运行的关联字段
+	// 模拟的输出写入运行日志
+	logger := logging.FromContext(ctx)
+	if run != nil {
+		logger.Info("Simulating task execution", "handler", task.Handler, "fire_time", run.FireTime)
+		runlog.Log(ctx, runlog.StreamStdout, fmt.Sprintf("executor %s executing task %s (handler: %s, fire time: %s)",
+			e.id, task.ID, task.Handler, run.FireTime.Format(time.RFC3339)))
+	} else {
+		logger.Info("Simulating task execution", "handler", task.Handler)
+		runlog.Log(ctx, runlog.StreamStdout, fmt.Sprintf("executor %s executing task %s (handler: %s)", e.id, task.ID, task.Handler))
+	}
 
 	// 这里可以添加实际的任务执行逻辑
 	// 比如HTTP调用、RPC调用等
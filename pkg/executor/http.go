@@ -0,0 +1,240 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/runlog"
+	"task_scheduler/pkg/tracing"
+	"task_scheduler/pkg/types"
+)
+
+// TraceparentHeader W3C Trace Context 请求头
+const TraceparentHeader = "traceparent"
+
+// RunEnvelope 派发给远程执行器的运行信息
+// Traceparent 为调度器执行跨度的 W3C 追踪上下文，执行器的下游调用应以此为父跨度
+type RunEnvelope struct {
+	Task        *types.Task `json:"task"`
+	Run         *types.Run  `json:"run,omitempty"`
+	Traceparent string      `json:"traceparent,omitempty"`
+}
+
+// RunStreamContentType 流式返回运行日志与结果的响应类型
+const RunStreamContentType = "application/x-ndjson"
+
+// RunMessage 远程执行器流式返回的一条消息：日志行，或 Done 为真的最终结果
+type RunMessage struct {
+	Stream string `json:"stream,omitempty"`
+	Text   string `json:"text,omitempty"`
+	Done   bool   `json:"done,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HTTPExecutor 通过 HTTP 把运行派发给远程执行器
+// 协议：POST {address}/runs，请求体为 RunEnvelope，同时设置 traceparent 请求头。
+// 执行器可以直接返回：2xx 表示成功，其他状态码的响应体作为错误信息；
+// 也可以以 RunStreamContentType 分块返回 RunMessage 流：日志行写入运行日志，最后一条 Done 消息给出结果。
+// 健康检查：GET {address}/healthz，2xx 表示健康
+type HTTPExecutor struct {
+	*SimpleExecutor
+	client *http.Client
+}
+
+// HTTPOption HTTP 执行器配置项
+type HTTPOption func(*HTTPExecutor)
+
+// WithHTTPClient 使用自定义的 HTTP 客户端
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(e *HTTPExecutor) {
+		e.client = client
+	}
+}
+
+// NewHTTPExecutor 创建 HTTP 执行器，address 为执行器的基础URL（如 http://localhost:8001）
+func NewHTTPExecutor(id, address string, opts ...HTTPOption) *HTTPExecutor {
+	e := &HTTPExecutor{
+		SimpleExecutor: NewSimpleExecutor(id, strings.TrimRight(address, "/")),
+		client:         http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Execute 执行任务
+func (e *HTTPExecutor) Execute(task *types.Task) error {
+	return e.ExecuteRun(e.logContext(task), task, nil)
+}
+
+// ExecuteRun 把运行发送给远程执行器并等待执行结果，ctx 取消时中止请求
+func (e *HTTPExecutor) ExecuteRun(ctx context.Context, task *types.Task, run *types.Run) error {
+	if !e.IsHealthy() {
+		return fmt.Errorf("executor %s is not healthy", e.id)
+	}
+	e.IncrementUsage()
+	e.updateLastUsedTime()
+
+	if run == nil {
+		run = &types.Run{TaskID: task.ID, Attempt: 1, FireTime: time.Now()}
+	}
+	envelope := RunEnvelope{
+		Task:        task,
+		Run:         run,
+		Traceparent: tracing.SpanFromContext(ctx).SpanContext().Traceparent(),
+	}
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("executor %s: encode run: %v", e.id, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.address+"/runs", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if envelope.Traceparent != "" {
+		req.Header.Set(TraceparentHeader, envelope.Traceparent)
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Debug("Dispatching run to remote executor", "url", req.URL.String())
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	logger.Debug("Remote executor responded", "status", resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("executor %s: %s: %s", e.id, resp.Status, strings.TrimSpace(string(message)))
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == RunStreamContentType {
+		return e.readRunStream(ctx, resp.Body)
+	}
+	return nil
+}
+
+// Probe 请求远程执行器的健康检查接口，并按结果更新健康状态
+func (e *HTTPExecutor) Probe(ctx context.Context) error {
+	err := e.probe(ctx)
+	e.SetHealthy(err == nil)
+	return err
+}
+
+// probe 请求 GET {address}/healthz，非2xx响应视为不健康
+func (e *HTTPExecutor) probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.address+"/healthz", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("executor %s: health check returned %s", e.id, resp.Status)
+	}
+	return nil
+}
+
+// readRunStream 读取流式响应，日志行写入运行日志，返回最终结果
+func (e *HTTPExecutor) readRunStream(ctx context.Context, body io.Reader) error {
+	decoder := json.NewDecoder(body)
+	for {
+		var message RunMessage
+		if err := decoder.Decode(&message); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err == io.EOF {
+				return fmt.Errorf("executor %s closed the run stream without a result", e.id)
+			}
+			return fmt.Errorf("executor %s: invalid run stream: %v", e.id, err)
+		}
+		if message.Done {
+			if message.Error != "" {
+				return errors.New(message.Error)
+			}
+			return nil
+		}
+		stream := message.Stream
+		if stream == "" {
+			stream = runlog.StreamStdout
+		}
+		runlog.Log(ctx, stream, message.Text)
+	}
+}
+
+// RunStream 执行器端的流式响应：逐行返回运行日志，最后返回结果
+// RunStream 实现 runlog.Sink，可以配合 runlog.Output 捕获子进程输出
+type RunStream struct {
+	writer  http.ResponseWriter
+	encoder *json.Encoder
+	mutex   sync.Mutex
+}
+
+// NewRunStream 开始流式响应
+func NewRunStream(w http.ResponseWriter) *RunStream {
+	w.Header().Set("Content-Type", RunStreamContentType)
+	w.WriteHeader(http.StatusOK)
+	return &RunStream{writer: w, encoder: json.NewEncoder(w)}
+}
+
+// Log 返回一行日志
+func (s *RunStream) Log(stream, text string) {
+	s.send(RunMessage{Stream: stream, Text: text})
+}
+
+// Finish 返回运行结果，err 为空表示成功
+func (s *RunStream) Finish(err error) {
+	message := RunMessage{Done: true}
+	if err != nil {
+		message.Error = err.Error()
+	}
+	s.send(message)
+}
+
+// send 写入一条消息并立即发送
+func (s *RunStream) send(message RunMessage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.encoder.Encode(message)
+	if flusher, ok := s.writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// DecodeRunEnvelope 在执行器端解析派发请求，返回的上下文以调度器的执行跨度为远程父跨度
+func DecodeRunEnvelope(r *http.Request) (context.Context, *RunEnvelope, error) {
+	var envelope RunEnvelope
+	if err := json.NewDecoder(r.Body).Decode(&envelope); err != nil {
+		return r.Context(), nil, fmt.Errorf("invalid run envelope: %v", err)
+	}
+	if envelope.Task == nil {
+		return r.Context(), nil, fmt.Errorf("invalid run envelope: missing task")
+	}
+
+	traceparent := envelope.Traceparent
+	if traceparent == "" {
+		traceparent = r.Header.Get(TraceparentHeader)
+	}
+	ctx := r.Context()
+	if parent, err := tracing.ParseTraceparent(traceparent); err == nil {
+		ctx = tracing.ContextWithRemoteParent(ctx, parent)
+	}
+	return ctx, &envelope, nil
+}
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"task_scheduler/pkg/types"
@@ -10,6 +11,7 @@ import (
 // Manager 执行器管理器
 type Manager struct {
 	executors map[string]types.Executor
+	draining  map[string]bool // 排空中的执行器不再接收新的运行
 	mutex     sync.RWMutex
 }
 
@@ -17,6 +19,7 @@ type Manager struct {
 func NewManager() *Manager {
 	return &Manager{
 		executors: make(map[string]types.Executor),
+		draining:  make(map[string]bool),
 	}
 }
 
@@ -26,7 +29,7 @@ func (em *Manager) AddExecutor(executor types.Executor) error {
 	defer em.mutex.Unlock()
 
 	if _, exists := em.executors[executor.GetID()]; exists {
-		return fmt.Errorf("executor %s already exists", executor.GetID())
+		return fmt.Errorf("executor %s: %w", executor.GetID(), types.ErrExecutorExists)
 	}
 
 	em.executors[executor.GetID()] = executor
@@ -39,25 +42,66 @@ func (em *Manager) RemoveExecutor(executorID string) error {
 	defer em.mutex.Unlock()
 
 	if _, exists := em.executors[executorID]; !exists {
-		return fmt.Errorf("executor %s not found", executorID)
+		return fmt.Errorf("executor %s: %w", executorID, types.ErrExecutorNotFound)
 	}
 
 	delete(em.executors, executorID)
+	delete(em.draining, executorID)
 	return nil
 }
 
-// GetExecutors 获取所有健康的执行器
+// Drain 排空执行器：不再路由新的运行，已派发的运行继续执行
+func (em *Manager) Drain(executorID string) error {
+	em.mutex.Lock()
+	defer em.mutex.Unlock()
+
+	if _, exists := em.executors[executorID]; !exists {
+		return fmt.Errorf("executor %s: %w", executorID, types.ErrExecutorNotFound)
+	}
+
+	em.draining[executorID] = true
+	return nil
+}
+
+// IsDraining 执行器是否正在排空
+func (em *Manager) IsDraining(executorID string) bool {
+	em.mutex.RLock()
+	defer em.mutex.RUnlock()
+	return em.draining[executorID]
+}
+
+// ListExecutors 获取所有执行器，包括不健康和排空中的（按ID排序）
+func (em *Manager) ListExecutors() []types.Executor {
+	em.mutex.RLock()
+	defer em.mutex.RUnlock()
+
+	executors := make([]types.Executor, 0, len(em.executors))
+	for _, executor := range em.executors {
+		executors = append(executors, executor)
+	}
+	sort.Slice(executors, func(i, j int) bool {
+		return executors[i].GetID() < executors[j].GetID()
+	})
+	return executors
+}
+
+// GetExecutors 获取所有可以接收运行的执行器：健康且未在排空（按ID排序）
 func (em *Manager) GetExecutors() []types.Executor {
 	em.mutex.RLock()
 	defer em.mutex.RUnlock()
 
 	var healthyExecutors []types.Executor
 	for _, executor := range em.executors {
-		if executor.IsHealthy() {
+		if executor.IsHealthy() && !em.draining[executor.GetID()] {
 			healthyExecutors = append(healthyExecutors, executor)
 		}
 	}
 
+	// 按ID排序，保证轮询等依赖下标的策略在多次调用（以及重启）间顺序稳定
+	sort.Slice(healthyExecutors, func(i, j int) bool {
+		return healthyExecutors[i].GetID() < healthyExecutors[j].GetID()
+	})
+
 	return healthyExecutors
 }
 
@@ -68,7 +112,7 @@ func (em *Manager) GetExecutor(executorID string) (types.Executor, error) {
 
 	executor, exists := em.executors[executorID]
 	if !exists {
-		return nil, fmt.Errorf("executor %s not found", executorID)
+		return nil, fmt.Errorf("executor %s: %w", executorID, types.ErrExecutorNotFound)
 	}
 
 	return executor, nil
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// 关联字段的键，日志管道可以据此按运行建立索引
+const (
+	KeyTaskID     = "task_id"
+	KeyRunID      = "run_id"
+	KeyExecutorID = "executor_id"
+	KeyStrategy   = "strategy"
+	KeyAttempt    = "attempt"
+	KeyTrigger    = "trigger"
+	KeyTraceID    = "trace_id"
+	KeyError      = "error"
+)
+
+// Format 日志输出格式
+type Format string
+
+const (
+	// FormatText key=value 文本格式
+	FormatText Format = "text"
+	// FormatJSON 每行一个 JSON 对象
+	FormatJSON Format = "json"
+)
+
+// ParseFormat 解析输出格式
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(value))); format {
+	case FormatText, FormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown log format %q (expected text or json)", value)
+}
+
+// ParseLevel 解析日志级别：debug、info、warn、error，也支持 info+2 这样的偏移
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		return level, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", value)
+	}
+	return level, nil
+}
+
+// New 创建结构化日志记录器
+func New(w io.Writer, format Format, level slog.Leveler) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	if format == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// Err 错误字段
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
+
+// loggerKey 上下文中保存日志记录器的键
+type loggerKey struct{}
+
+// WithLogger 返回携带日志记录器的上下文
+// 调度器把带有运行关联字段的记录器放入传给执行器的上下文
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext 返回上下文中的日志记录器，没有时返回 slog.Default()
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
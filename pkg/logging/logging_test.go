@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	level, err := ParseLevel("WARN")
+	if err != nil || level != slog.LevelWarn {
+		t.Fatalf("ParseLevel: %v %v", level, err)
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("expected unknown level to be rejected")
+	}
+	format, err := ParseFormat("json")
+	if err != nil || format != FormatJSON {
+		t.Fatalf("ParseFormat: %v %v", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected unknown format to be rejected")
+	}
+
+	var out strings.Builder
+	logger := New(&out, FormatJSON, level).With(KeyRunID, "run-1")
+	logger.Info("dropped")
+	ctx := WithLogger(context.Background(), logger)
+	FromContext(ctx).Warn("kept", Err(errors.New("boom")))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(out.String()), &entry); err != nil {
+		t.Fatalf("expected a single JSON line, got %q: %v", out.String(), err)
+	}
+	if entry["msg"] != "kept" || entry[KeyRunID] != "run-1" || entry[KeyError] != "boom" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("FromContext should fall back to the default logger")
+	}
+
+	out.Reset()
+	New(&out, FormatText, slog.LevelInfo).Info("hello", KeyTaskID, "report")
+	if !strings.Contains(out.String(), "msg=hello task_id=report") {
+		t.Errorf("unexpected text output: %q", out.String())
+	}
+}
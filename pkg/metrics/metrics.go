@@ -0,0 +1,346 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType Prometheus 文本格式的内容类型
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets 默认的直方图桶上界（秒），覆盖毫秒级到十分钟级的任务
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 600}
+
+// Sample 采集时计算的一个样本，LabelValues 与注册时的标签一一对应
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// collector 一个指标族
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry 指标注册表，以 Prometheus 文本格式输出所有指标
+type Registry struct {
+	collectors map[string]collector
+	mutex      sync.RWMutex
+}
+
+// NewRegistry 创建指标注册表
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// register 注册指标族，名称重复时panic（属于编程错误）
+func (r *Registry) register(c collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, exists := r.collectors[c.name()]; exists {
+		panic(fmt.Sprintf("metrics: %s registered twice", c.name()))
+	}
+	r.collectors[c.name()] = c
+}
+
+// WriteText 按名称顺序以 Prometheus 文本格式输出所有指标
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mutex.RLock()
+	collectors := make([]collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mutex.RUnlock()
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+
+	buffered := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buffered)
+	}
+	return buffered.Flush()
+}
+
+// ServeHTTP 输出指标，可直接挂载为 /metrics
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.WriteText(w)
+}
+
+// desc 指标族的名称、说明和标签
+type desc struct {
+	metricName string
+	help       string
+	labels     []string
+}
+
+func (d *desc) name() string {
+	return d.metricName
+}
+
+// writeHeader 输出 HELP 与 TYPE 行
+func (d *desc) writeHeader(w *bufio.Writer, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.metricName, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.metricName, metricType)
+}
+
+// labelIndex 返回标签在标签列表中的位置
+func (d *desc) labelIndex(label string) int {
+	for i, name := range d.labels {
+		if name == label {
+			return i
+		}
+	}
+	panic(fmt.Sprintf("metrics: %s has no label %q", d.metricName, label))
+}
+
+// checkLabels 检查标签值个数
+func (d *desc) checkLabels(values []string) {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.metricName, len(d.labels), len(values)))
+	}
+}
+
+// seriesKey 标签值拼接成的序列键
+func seriesKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// CounterVec 带标签的计数器
+type CounterVec struct {
+	desc
+	values map[string]*counterSeries
+	mutex  sync.Mutex
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+// NewCounterVec 注册计数器
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name, help, labels}, values: make(map[string]*counterSeries)}
+	r.register(c)
+	return c
+}
+
+// Inc 计数加一
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 计数增加 delta，delta 不能为负
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	c.checkLabels(labelValues)
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.metricName))
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	key := seriesKey(labelValues)
+	series, exists := c.values[key]
+	if !exists {
+		series = &counterSeries{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = series
+	}
+	series.value += delta
+}
+
+// Value 返回计数当前值
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if series, exists := c.values[seriesKey(labelValues)]; exists {
+		return series.value
+	}
+	return 0
+}
+
+// DeletePartialMatch 删除标签 label 取值为 value 的所有序列，返回删除的序列数
+// 用于在任务、执行器等标签对象移除后释放其序列
+func (c *CounterVec) DeletePartialMatch(label, value string) int {
+	i := c.labelIndex(label)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	deleted := 0
+	for key, series := range c.values {
+		if series.labelValues[i] == value {
+			delete(c.values, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.writeHeader(w, "counter")
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		series := c.values[key]
+		writeSample(w, c.metricName, c.labels, series.labelValues, "", "", series.value)
+	}
+}
+
+// GaugeFunc 采集时计算的仪表盘
+type GaugeFunc struct {
+	desc
+	collect func() []Sample
+}
+
+// NewGaugeFunc 注册仪表盘，每次输出时调用 collect 计算当前值
+func (r *Registry) NewGaugeFunc(name, help string, labels []string, collect func() []Sample) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{name, help, labels}, collect: collect}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	g.writeHeader(w, "gauge")
+	samples := g.collect()
+	sort.Slice(samples, func(i, j int) bool {
+		return seriesKey(samples[i].LabelValues) < seriesKey(samples[j].LabelValues)
+	})
+	for _, sample := range samples {
+		g.checkLabels(sample.LabelValues)
+		writeSample(w, g.metricName, g.labels, sample.LabelValues, "", "", sample.Value)
+	}
+}
+
+// HistogramVec 带标签的直方图
+type HistogramVec struct {
+	desc
+	buckets []float64
+	values  map[string]*histogramSeries
+	mutex   sync.Mutex
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // 与 buckets 对应的非累计计数
+	count       uint64
+	sum         float64
+}
+
+// NewHistogramVec 注册直方图，buckets 为递增的桶上界，为空时使用 DefaultBuckets
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: buckets of %s must be sorted", name))
+	}
+	h := &HistogramVec{desc: desc{name, help, labels}, buckets: buckets, values: make(map[string]*histogramSeries)}
+	r.register(h)
+	return h
+}
+
+// Observe 记录一个观测值
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.checkLabels(labelValues)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	key := seriesKey(labelValues)
+	series, exists := h.values[key]
+	if !exists {
+		series = &histogramSeries{labelValues: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = series
+	}
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		series.counts[i]++
+	}
+	series.count++
+	series.sum += value
+}
+
+// DeletePartialMatch 删除标签 label 取值为 value 的所有序列，返回删除的序列数
+func (h *HistogramVec) DeletePartialMatch(label, value string) int {
+	i := h.labelIndex(label)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	deleted := 0
+	for key, series := range h.values {
+		if series.labelValues[i] == value {
+			delete(h.values, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w, "histogram")
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, key := range sortedKeys(h.values) {
+		series := h.values[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += series.counts[i]
+			writeSample(w, h.metricName+"_bucket", h.labels, series.labelValues, "le", formatFloat(bound), float64(cumulative))
+		}
+		writeSample(w, h.metricName+"_bucket", h.labels, series.labelValues, "le", "+Inf", float64(series.count))
+		writeSample(w, h.metricName+"_sum", h.labels, series.labelValues, "", "", series.sum)
+		writeSample(w, h.metricName+"_count", h.labels, series.labelValues, "", "", float64(series.count))
+	}
+}
+
+// writeSample 输出一行样本，extraName 非空时追加一个标签（直方图的 le）
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraName, extraValue string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, label, escapeLabel(values[i]))
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// escapeLabel 转义标签值中的反斜杠、双引号和换行
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatFloat 格式化样本值
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// sortedKeys 排序后的序列键，保证输出顺序稳定
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
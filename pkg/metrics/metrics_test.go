@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	registry := NewRegistry()
+	runs := registry.NewCounterVec("runs_total", "Finished runs.", "task", "status")
+	duration := registry.NewHistogramVec("run_duration_seconds", "Run duration.", []float64{0.1, 1}, "task")
+	registry.NewGaugeFunc("in_flight", "Runs in flight.", []string{"executor"}, func() []Sample {
+		return []Sample{{LabelValues: []string{"exec-2"}, Value: 0}, {LabelValues: []string{"exec-1"}, Value: 3}}
+	})
+
+	runs.Inc("report", "succeeded")
+	runs.Add(2, "report", "succeeded")
+	runs.Inc(`say "hi"`, "failed")
+	duration.Observe(0.05, "report")
+	duration.Observe(0.1, "report")
+	duration.Observe(5, "report")
+
+	var out strings.Builder
+	if err := registry.WriteText(&out); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	expected := `# HELP in_flight Runs in flight.
+# TYPE in_flight gauge
+in_flight{executor="exec-1"} 3
+in_flight{executor="exec-2"} 0
+# HELP run_duration_seconds Run duration.
+# TYPE run_duration_seconds histogram
+run_duration_seconds_bucket{task="report",le="0.1"} 2
+run_duration_seconds_bucket{task="report",le="1"} 2
+run_duration_seconds_bucket{task="report",le="+Inf"} 3
+run_duration_seconds_sum{task="report"} 5.15
+run_duration_seconds_count{task="report"} 3
+# HELP runs_total Finished runs.
+# TYPE runs_total counter
+runs_total{task="report",status="succeeded"} 3
+runs_total{task="say \"hi\"",status="failed"} 1
+`
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	if runs.Value("report", "succeeded") != 3 {
+		t.Errorf("expected counter value 3, got %v", runs.Value("report", "succeeded"))
+	}
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("runs_total", "Finished runs.")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a metric name twice should panic")
+		}
+	}()
+	registry.NewCounterVec("runs_total", "Finished runs.")
+}
+
+func TestDeletePartialMatch(t *testing.T) {
+	registry := NewRegistry()
+	runs := registry.NewCounterVec("runs_total", "Finished runs.", "task", "status")
+	duration := registry.NewHistogramVec("run_duration_seconds", "Run duration.", nil, "task")
+	runs.Inc("report", "succeeded")
+	runs.Inc("report", "failed")
+	runs.Inc("cleanup", "succeeded")
+	duration.Observe(1, "report")
+
+	if deleted := runs.DeletePartialMatch("task", "report"); deleted != 2 {
+		t.Errorf("expected 2 deleted series, got %d", deleted)
+	}
+	if deleted := duration.DeletePartialMatch("task", "report"); deleted != 1 {
+		t.Errorf("expected 1 deleted series, got %d", deleted)
+	}
+	if runs.Value("report", "failed") != 0 || runs.Value("cleanup", "succeeded") != 1 {
+		t.Error("only series of the deleted task should be removed")
+	}
+}
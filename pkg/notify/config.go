@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// 通道类型
+const (
+	ChannelWebhook = "webhook"
+	ChannelChat    = "chat"
+	ChannelEmail   = "email"
+)
+
+// ChannelConfig 配置文件中的一个通道，按 Type 使用对应的字段
+type ChannelConfig struct {
+	Type string `json:"type"`
+	// webhook、chat
+	URL       string            `json:"url,omitempty"`
+	Secret    string            `json:"secret,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	TextField string            `json:"text_field,omitempty"`
+	// email
+	SMTP     string   `json:"smtp,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	// Title 和 Body 为消息模板，为空时使用默认模板
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+// Config 通知配置文件：命名的通道、路由和重试策略
+type Config struct {
+	Channels map[string]ChannelConfig `json:"channels"`
+	Routes   []Route                  `json:"routes"`
+	Retry    RetryPolicy              `json:"retry"`
+}
+
+// ParseConfig 解析 JSON 格式的通知配置
+func ParseConfig(r io.Reader) (*Config, error) {
+	var config Config
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid notification config: %v", err)
+	}
+	return &config, nil
+}
+
+// Notifier 按通道配置创建通知器
+func (c ChannelConfig) Notifier() (Notifier, error) {
+	template, err := NewTemplate(c.Title, c.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch c.Type {
+	case ChannelWebhook:
+		if c.URL == "" {
+			return nil, fmt.Errorf("webhook url is required")
+		}
+		return NewWebhook(WebhookConfig{URL: c.URL, Secret: c.Secret, Headers: c.Headers, Template: template}), nil
+	case ChannelChat:
+		if c.URL == "" {
+			return nil, fmt.Errorf("chat url is required")
+		}
+		return NewChat(ChatConfig{URL: c.URL, TextField: c.TextField, Template: template}), nil
+	case ChannelEmail:
+		if c.SMTP == "" || c.From == "" || len(c.To) == 0 {
+			return nil, fmt.Errorf("email smtp, from and to are required")
+		}
+		return NewEmail(EmailConfig{
+			Addr:     c.SMTP,
+			From:     c.From,
+			To:       c.To,
+			Username: c.Username,
+			Password: c.Password,
+			Template: template,
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown channel type %q", c.Type)
+}
+
+// NewDispatcher 按配置创建分发器，logger 为nil时使用 slog.Default()
+func (c *Config) NewDispatcher(logger *slog.Logger) (*Dispatcher, error) {
+	notifiers := make(map[string]Notifier, len(c.Channels))
+	for name, channel := range c.Channels {
+		notifier, err := channel.Notifier()
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %v", name, err)
+		}
+		notifiers[name] = notifier
+	}
+	opts := []Option{WithRetry(c.Retry)}
+	if logger != nil {
+		opts = append(opts, WithLogger(logger))
+	}
+	return NewDispatcher(notifiers, c.Routes, opts...)
+}
@@ -0,0 +1,298 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/types"
+)
+
+const (
+	// DefaultAttempts 每条通知默认的最多投递次数
+	DefaultAttempts = 3
+	// DefaultBackoff 第一次重试前默认的等待时间，之后每次加倍
+	DefaultBackoff = time.Second
+	// DefaultMaxBackoff 默认的最长重试等待时间
+	DefaultMaxBackoff = 30 * time.Second
+	// DefaultTimeout 每次投递默认的超时时间
+	DefaultTimeout = 10 * time.Second
+	// DefaultQueueSize 每个通道默认的待投递通知数上限，超出时丢弃新通知
+	DefaultQueueSize = 256
+)
+
+// Route 通知路由：匹配的通知投递到 Channels；一条通知匹配多条路由时每个通道只投递一次
+type Route struct {
+	// TaskIDs 匹配的任务，为空时匹配所有任务
+	TaskIDs []string `json:"task_ids,omitempty"`
+	// Kinds 匹配的通知类型，为空时只匹配告警的开始和恢复；运行结束通知需要显式列出
+	Kinds    []Kind   `json:"kinds,omitempty"`
+	Channels []string `json:"channels"`
+}
+
+// Match 路由是否匹配通知
+func (r Route) Match(msg *Message) bool {
+	if len(r.TaskIDs) > 0 {
+		matched := false
+		for _, taskID := range r.TaskIDs {
+			matched = matched || taskID == msg.TaskID
+		}
+		if !matched {
+			return false
+		}
+	}
+	kinds := r.Kinds
+	if len(kinds) == 0 {
+		kinds = alertKinds
+	}
+	for _, kind := range kinds {
+		if kind == msg.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryPolicy 投递失败时的重试策略，零值字段使用默认值
+type RetryPolicy struct {
+	// Attempts 最多投递次数（含第一次）
+	Attempts   int            `json:"attempts,omitempty"`
+	Backoff    alert.Duration `json:"backoff,omitempty"`
+	MaxBackoff alert.Duration `json:"max_backoff,omitempty"`
+	// Timeout 每次投递的超时时间
+	Timeout alert.Duration `json:"timeout,omitempty"`
+}
+
+// withDefaults 填充默认值
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.Attempts <= 0 {
+		p.Attempts = DefaultAttempts
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = alert.Duration(DefaultBackoff)
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = alert.Duration(DefaultMaxBackoff)
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = alert.Duration(DefaultTimeout)
+	}
+	return p
+}
+
+// Option 分发器配置项
+type Option func(*Dispatcher)
+
+// WithRetry 设置重试策略
+func WithRetry(policy RetryPolicy) Option {
+	return func(d *Dispatcher) {
+		d.retry = policy.withDefaults()
+	}
+}
+
+// WithQueueSize 设置每个通道的队列长度，默认 DefaultQueueSize
+func WithQueueSize(size int) Option {
+	return func(d *Dispatcher) {
+		d.queueSize = size
+	}
+}
+
+// WithLogger 设置日志记录器，默认 slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(d *Dispatcher) {
+		d.logger = logger
+	}
+}
+
+// channel 一个命名的通知通道，通知按到达顺序逐条投递
+type channel struct {
+	name     string
+	notifier Notifier
+	queue    chan *Message
+}
+
+// Dispatcher 按路由把通知异步投递到各通道，失败时按重试策略重试
+// 每个通道有独立的队列和投递协程，慢通道不会拖慢其他通道
+type Dispatcher struct {
+	channels  map[string]*channel
+	routes    []Route
+	retry     RetryPolicy
+	queueSize int
+	logger    *slog.Logger
+
+	closed   bool
+	mutex    sync.RWMutex
+	stopping chan struct{} // 关闭超时后关闭，放弃重试
+	wg       sync.WaitGroup
+	unwatch  []func()
+}
+
+// NewDispatcher 创建分发器并开始投递，路由引用了不存在的通道或未知的通知类型时返回错误
+func NewDispatcher(notifiers map[string]Notifier, routes []Route, opts ...Option) (*Dispatcher, error) {
+	d := &Dispatcher{
+		channels:  make(map[string]*channel, len(notifiers)),
+		routes:    append([]Route(nil), routes...),
+		retry:     RetryPolicy{}.withDefaults(),
+		queueSize: DefaultQueueSize,
+		logger:    slog.Default(),
+		stopping:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	for _, route := range routes {
+		if len(route.Channels) == 0 {
+			return nil, fmt.Errorf("notification route has no channels")
+		}
+		for _, name := range route.Channels {
+			if _, exists := notifiers[name]; !exists {
+				return nil, fmt.Errorf("notification route refers to unknown channel %q", name)
+			}
+		}
+		for _, kind := range route.Kinds {
+			if _, err := ParseKind(string(kind)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for name, notifier := range notifiers {
+		ch := &channel{name: name, notifier: notifier, queue: make(chan *Message, d.queueSize)}
+		d.channels[name] = ch
+		d.wg.Add(1)
+		go d.run(ch)
+	}
+	return d, nil
+}
+
+// Dispatch 按路由把通知放入各通道的队列，不阻塞；分发器关闭后忽略
+func (d *Dispatcher) Dispatch(msg *Message) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if d.closed {
+		return
+	}
+
+	queued := make(map[string]bool)
+	for _, route := range d.routes {
+		if !route.Match(msg) {
+			continue
+		}
+		for _, name := range route.Channels {
+			if queued[name] {
+				continue
+			}
+			queued[name] = true
+			select {
+			case d.channels[name].queue <- msg:
+			default:
+				d.logger.Warn("Notification queue full, dropping notification",
+					"channel", name, "kind", msg.Kind, logging.KeyTaskID, msg.TaskID)
+			}
+		}
+	}
+}
+
+// HandleAlert 把告警的开始和恢复作为通知分发，可直接作为 alert.Handler 使用
+func (d *Dispatcher) HandleAlert(a alert.Alert) {
+	d.Dispatch(AlertMessage(a))
+}
+
+// eventSource 可以订阅运行结束事件且不丢弃事件的数据源，*scheduler.TaskScheduler 实现了该接口
+type eventSource interface {
+	SubscribeReliable(filter types.EventFilter) (<-chan *types.Event, func())
+}
+
+// WatchRuns 订阅运行结束事件并分发运行结束通知（需要路由列出 run_* 类型才会投递）
+// 在 Close 时取消订阅
+func (d *Dispatcher) WatchRuns(source eventSource) {
+	events, cancel := source.SubscribeReliable(types.EventFilter{Types: []types.EventType{types.EventRunFinished}})
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		cancel()
+		return
+	}
+	d.unwatch = append(d.unwatch, cancel)
+	d.mutex.Unlock()
+
+	go func() {
+		for event := range events {
+			if msg := RunMessage(event.Run); msg != nil {
+				d.Dispatch(msg)
+			}
+		}
+	}()
+}
+
+// Close 停止接收通知，投递队列中剩余的通知（含重试）后返回；
+// ctx 结束后不再重试，剩余的通知只投递一次
+func (d *Dispatcher) Close(ctx context.Context) {
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		return
+	}
+	d.closed = true
+	for _, cancel := range d.unwatch {
+		cancel()
+	}
+	for _, ch := range d.channels {
+		close(ch.queue)
+	}
+	d.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		close(d.stopping)
+		<-done
+	}
+}
+
+// run 逐条投递通道中的通知
+func (d *Dispatcher) run(ch *channel) {
+	defer d.wg.Done()
+	for msg := range ch.queue {
+		d.deliver(ch, msg)
+	}
+}
+
+// deliver 投递一条通知，失败时按指数退避重试，不可重试的错误立即放弃
+func (d *Dispatcher) deliver(ch *channel, msg *Message) {
+	logger := d.logger.With("channel", ch.name, "kind", msg.Kind, logging.KeyTaskID, msg.TaskID)
+	backoff := time.Duration(d.retry.Backoff)
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(d.retry.Timeout))
+		err := ch.notifier.Notify(ctx, msg)
+		cancel()
+		if err == nil {
+			logger.Debug("Notification delivered", "attempt", attempt)
+			return
+		}
+		if IsPermanent(err) || attempt >= d.retry.Attempts {
+			logger.Error("Notification failed", "attempt", attempt, logging.Err(err))
+			return
+		}
+		logger.Warn("Notification attempt failed, retrying", "attempt", attempt, "backoff", backoff, logging.Err(err))
+		select {
+		case <-time.After(backoff):
+		case <-d.stopping:
+			logger.Error("Notification retry abandoned on shutdown", "attempt", attempt, logging.Err(err))
+			return
+		}
+		backoff *= 2
+		if maxBackoff := time.Duration(d.retry.MaxBackoff); backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// EmailConfig SMTP 邮件配置
+type EmailConfig struct {
+	// Addr SMTP 服务地址，如 smtp.example.com:587
+	Addr string
+	From string
+	To   []string
+	// Username 和 Password 为空时不认证；认证只在 TLS 连接或本机服务上进行
+	Username string
+	Password string
+	// TLSConfig 服务支持 STARTTLS 时使用，为空时按 Addr 的主机名校验证书
+	TLSConfig *tls.Config
+	Template  *Template
+}
+
+// Email 通过 SMTP 发送纯文本邮件，标题为邮件主题；服务支持 STARTTLS 时先升级连接
+type Email struct {
+	config EmailConfig
+}
+
+// NewEmail 创建邮件通知
+func NewEmail(config EmailConfig) *Email {
+	return &Email{config: config}
+}
+
+// Notify 发送通知
+func (e *Email) Notify(ctx context.Context, msg *Message) error {
+	title, text, err := e.config.Template.Render(msg)
+	if err != nil {
+		return Permanent(err)
+	}
+	if len(e.config.To) == 0 {
+		return Permanent(fmt.Errorf("email has no recipients"))
+	}
+	return e.send(ctx, e.compose(title, text))
+}
+
+// compose 生成邮件内容，行以 CRLF 结尾
+func (e *Email) compose(subject, body string) []byte {
+	var buf bytes.Buffer
+	headers := [][2]string{
+		{"From", e.config.From},
+		{"To", strings.Join(e.config.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+		{"Content-Transfer-Encoding", "8bit"},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+	}
+	buf.WriteString("\r\n")
+	for _, line := range strings.Split(body, "\n") {
+		buf.WriteString(strings.TrimSuffix(line, "\r"))
+		buf.WriteString("\r\n")
+	}
+	return buf.Bytes()
+}
+
+// send 连接 SMTP 服务并发送邮件，ctx 的截止时间作用于整个会话
+func (e *Email) send(ctx context.Context, data []byte) error {
+	host, _, err := net.SplitHostPort(e.config.Addr)
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid SMTP address %q: %v", e.config.Addr, err))
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.config.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		config := e.config.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: host}
+		}
+		if err := client.StartTLS(config); err != nil {
+			return fmt.Errorf("STARTTLS failed: %v", err)
+		}
+	}
+	if e.config.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", e.config.Username, e.config.Password, host)); err != nil {
+				return Permanent(fmt.Errorf("SMTP authentication failed: %v", err))
+			}
+		}
+	}
+
+	if err := client.Mail(e.config.From); err != nil {
+		return err
+	}
+	for _, to := range e.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/types"
+)
+
+// Kind 通知类型
+type Kind string
+
+const (
+	// KindAlertFiring 告警开始
+	KindAlertFiring Kind = "alert_firing"
+	// KindAlertResolved 告警恢复
+	KindAlertResolved Kind = "alert_resolved"
+	// KindRunSucceeded 运行成功
+	KindRunSucceeded Kind = "run_succeeded"
+	// KindRunFailed 运行失败，包括结果未知的丢失运行
+	KindRunFailed Kind = "run_failed"
+	// KindRunCancelled 运行被取消
+	KindRunCancelled Kind = "run_cancelled"
+)
+
+// Kinds 所有通知类型
+var Kinds = []Kind{KindAlertFiring, KindAlertResolved, KindRunSucceeded, KindRunFailed, KindRunCancelled}
+
+// alertKinds 路由未指定类型时匹配的通知类型
+var alertKinds = []Kind{KindAlertFiring, KindAlertResolved}
+
+// ParseKind 解析通知类型名称
+func ParseKind(value string) (Kind, error) {
+	for _, kind := range Kinds {
+		if string(kind) == value {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("unknown notification kind %q", value)
+}
+
+// Message 一条通知：告警的开始或恢复，或一次运行的结束
+type Message struct {
+	Kind   Kind         `json:"kind"`
+	Time   time.Time    `json:"time"`
+	TaskID string       `json:"task_id"`
+	Alert  *alert.Alert `json:"alert,omitempty"`
+	Run    *types.Run   `json:"run,omitempty"`
+}
+
+// AlertMessage 由告警生成通知
+func AlertMessage(a alert.Alert) *Message {
+	kind := KindAlertFiring
+	if a.State == alert.StateResolved {
+		kind = KindAlertResolved
+	}
+	return &Message{Kind: kind, Time: time.Now(), TaskID: a.TaskID, Alert: &a}
+}
+
+// RunMessage 由已结束的运行生成通知，运行未结束时返回nil
+func RunMessage(run *types.Run) *Message {
+	var kind Kind
+	switch run.Status {
+	case types.RunStatusSucceeded:
+		kind = KindRunSucceeded
+	case types.RunStatusFailed, types.RunStatusLost:
+		kind = KindRunFailed
+	case types.RunStatusCancelled:
+		kind = KindRunCancelled
+	default:
+		return nil
+	}
+	snapshot := *run
+	return &Message{Kind: kind, Time: time.Now(), TaskID: run.TaskID, Run: &snapshot}
+}
+
+// Notifier 把通知投递到外部系统
+// Notify 应在 ctx 结束时放弃投递；返回 Permanent 包装的错误表示重试无意义
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) error
+}
+
+// permanentError 不应重试的投递错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent 把错误标记为不可重试，如接收方拒绝了请求
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent 错误是否不可重试
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// 默认的消息模板，数据为 *Message
+const (
+	DefaultTitleTemplate = `{{if .Alert}}[{{upper .Alert.State}}] {{.Alert.Rule}} on task {{.TaskID}}` +
+		`{{else}}Run of task {{.TaskID}} {{.Run.Status}}{{end}}`
+	DefaultBodyTemplate = `{{if .Alert}}{{.Alert.Summary}}
+Severity: {{or .Alert.Severity "none"}}
+Started: {{.Alert.StartsAt.Format "2006-01-02 15:04:05 MST"}}
+{{- if eq .Kind "alert_resolved"}}
+Resolved: {{.Alert.EndsAt.Format "2006-01-02 15:04:05 MST"}}{{end}}
+{{- if .Alert.RunID}}
+Run: {{.Alert.RunID}}{{end}}
+{{- else}}Run: {{.Run.ID}}
+Trigger: {{.Run.Trigger}}, attempt {{.Run.Attempt}}
+Executor: {{or .Run.ExecutorID "-"}}
+Duration: {{duration .Run}}
+{{- if .Run.Error}}
+Error: {{.Run.Error}}{{end}}{{end}}`
+)
+
+// templateFuncs 模板中可用的函数
+var templateFuncs = template.FuncMap{
+	"upper": func(v interface{}) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"duration": func(run *types.Run) string {
+		if run.StartTime.IsZero() || run.EndTime.IsZero() {
+			return "-"
+		}
+		return run.EndTime.Sub(run.StartTime).Round(time.Millisecond).String()
+	},
+}
+
+// Template 通知标题和正文的模板（text/template 语法，数据为 *Message）
+type Template struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// NewTemplate 解析模板，为空的部分使用默认模板
+func NewTemplate(title, body string) (*Template, error) {
+	if title == "" {
+		title = DefaultTitleTemplate
+	}
+	if body == "" {
+		body = DefaultBodyTemplate
+	}
+	t := &Template{}
+	var err error
+	if t.title, err = template.New("title").Funcs(templateFuncs).Parse(title); err != nil {
+		return nil, fmt.Errorf("invalid title template: %v", err)
+	}
+	if t.body, err = template.New("body").Funcs(templateFuncs).Parse(body); err != nil {
+		return nil, fmt.Errorf("invalid body template: %v", err)
+	}
+	return t, nil
+}
+
+// defaultTemplate 默认模板
+var defaultTemplate, _ = NewTemplate("", "")
+
+// Render 渲染通知的标题和正文，t 为nil时使用默认模板
+func (t *Template) Render(msg *Message) (title, body string, err error) {
+	if t == nil {
+		t = defaultTemplate
+	}
+	var buf bytes.Buffer
+	if err := t.title.Execute(&buf, msg); err != nil {
+		return "", "", fmt.Errorf("failed to render title: %v", err)
+	}
+	title = strings.TrimSpace(buf.String())
+	buf.Reset()
+	if err := t.body.Execute(&buf, msg); err != nil {
+		return "", "", fmt.Errorf("failed to render body: %v", err)
+	}
+	return title, strings.TrimSpace(buf.String()), nil
+}
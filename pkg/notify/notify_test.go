@@ -0,0 +1,263 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"task_scheduler/pkg/alert"
+	"task_scheduler/pkg/types"
+)
+
+// fastRetry 测试用的重试策略
+var fastRetry = WithRetry(RetryPolicy{Attempts: 3, Backoff: alert.Duration(time.Millisecond)})
+
+// firingAlert 测试用的告警
+func firingAlert(taskID string) alert.Alert {
+	return alert.Alert{
+		ID:       "failing/" + taskID,
+		Rule:     "failing",
+		TaskID:   taskID,
+		State:    alert.StateFiring,
+		Severity: "critical",
+		Summary:  "3 consecutive failed runs",
+		StartsAt: time.Now(),
+	}
+}
+
+// recordingServer 记录请求体的本地服务，前 failures 个请求返回 status
+type recordingServer struct {
+	*httptest.Server
+	failures int
+	status   int
+	bodies   [][]byte
+	headers  []http.Header
+	mutex    sync.Mutex
+}
+
+func newRecordingServer(t *testing.T, failures, status int) *recordingServer {
+	s := &recordingServer{failures: failures, status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.bodies = append(s.bodies, body)
+		s.headers = append(s.headers, r.Header.Clone())
+		if len(s.bodies) <= s.failures {
+			http.Error(w, "try again", s.status)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestWebhookSigningAndRetry(t *testing.T) {
+	server := newRecordingServer(t, 2, http.StatusServiceUnavailable)
+	rejecting := newRecordingServer(t, 10, http.StatusBadRequest)
+	dispatcher, err := NewDispatcher(map[string]Notifier{
+		"ops":      NewWebhook(WebhookConfig{URL: server.URL, Secret: "s3cret", Headers: map[string]string{"Authorization": "Bearer t"}}),
+		"rejected": NewWebhook(WebhookConfig{URL: rejecting.URL}),
+	}, []Route{{Channels: []string{"ops", "rejected"}}}, fastRetry)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	dispatcher.HandleAlert(firingAlert("report"))
+	dispatcher.Close(context.Background())
+
+	// 两次 503 后第三次成功；400 不重试
+	if len(server.bodies) != 3 || len(rejecting.bodies) != 1 {
+		t.Fatalf("unexpected attempts: %d and %d", len(server.bodies), len(rejecting.bodies))
+	}
+	body, header := server.bodies[2], server.headers[2]
+	if !VerifySignature("s3cret", header.Get(TimestampHeader), body, header.Get(SignatureHeader)) {
+		t.Errorf("invalid signature %q", header.Get(SignatureHeader))
+	}
+	if VerifySignature("other", header.Get(TimestampHeader), body, header.Get(SignatureHeader)) {
+		t.Error("signature should not verify with another secret")
+	}
+	if header.Get("Authorization") != "Bearer t" {
+		t.Errorf("custom header missing: %v", header)
+	}
+	var payload struct {
+		Kind  Kind
+		Title string
+		Text  string
+		Alert *alert.Alert
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload.Kind != KindAlertFiring || payload.Title != "[FIRING] failing on task report" ||
+		!strings.Contains(payload.Text, "Severity: critical") || payload.Alert.ID != "failing/report" {
+		t.Errorf("unexpected payload: %s", body)
+	}
+}
+
+func TestChatRouting(t *testing.T) {
+	team := newRecordingServer(t, 0, 0)
+	oncall := newRecordingServer(t, 0, 0)
+	template, err := NewTemplate("{{.TaskID}}: {{.Kind}}", "{{if .Run}}{{.Run.Error}}{{end}}")
+	if err != nil {
+		t.Fatalf("NewTemplate failed: %v", err)
+	}
+	if _, err := NewTemplate("{{.Missing", ""); err == nil {
+		t.Error("invalid template should be rejected")
+	}
+
+	notifiers := map[string]Notifier{
+		"team":   NewChat(ChatConfig{URL: team.URL, TextField: "content", Template: template}),
+		"oncall": NewChat(ChatConfig{URL: oncall.URL}),
+	}
+	routes := []Route{
+		{TaskIDs: []string{"report"}, Kinds: []Kind{KindRunFailed, KindAlertFiring}, Channels: []string{"team"}},
+		{Channels: []string{"oncall", "team"}},
+	}
+	if _, err := NewDispatcher(notifiers, []Route{{Channels: []string{"missing"}}}); err == nil {
+		t.Error("route with an unknown channel should be rejected")
+	}
+	dispatcher, err := NewDispatcher(notifiers, routes, fastRetry)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+
+	dispatcher.Dispatch(RunMessage(&types.Run{ID: "run-1", TaskID: "report", Status: types.RunStatusFailed, Error: "exit status 2"}))
+	dispatcher.Dispatch(RunMessage(&types.Run{ID: "run-2", TaskID: "report", Status: types.RunStatusSucceeded}))
+	dispatcher.Dispatch(RunMessage(&types.Run{ID: "run-3", TaskID: "sync", Status: types.RunStatusFailed}))
+	dispatcher.HandleAlert(firingAlert("report"))
+	resolved := firingAlert("sync")
+	resolved.State = alert.StateResolved
+	dispatcher.HandleAlert(resolved)
+	dispatcher.Close(context.Background())
+	dispatcher.HandleAlert(firingAlert("ignored"))
+
+	texts := func(s *recordingServer, field string) []string {
+		var result []string
+		for _, body := range s.bodies {
+			var message map[string]string
+			json.Unmarshal(body, &message)
+			result = append(result, message[field])
+		}
+		return result
+	}
+	// 匹配两条路由的告警只向 team 投递一次
+	want := []string{"*report: run_failed*\nexit status 2", "*report: alert_firing*", "*sync: alert_resolved*"}
+	if got := texts(team, "content"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected team messages: %q", got)
+	}
+	got := texts(oncall, "text")
+	if len(got) != 2 || !strings.HasPrefix(got[0], "*[FIRING] failing on task report*") || !strings.HasPrefix(got[1], "*[RESOLVED] failing on task sync*") {
+		t.Errorf("unexpected oncall messages: %q", got)
+	}
+}
+
+// fakeSMTP 只接收一封邮件的本地 SMTP 服务
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); verb {
+			case "EHLO", "HELO":
+				text.PrintfLine("250 localhost")
+			case "DATA":
+				text.PrintfLine("354 go ahead")
+				data, _ := text.ReadDotBytes()
+				received <- string(data)
+				text.PrintfLine("250 queued")
+			case "QUIT":
+				text.PrintfLine("221 bye")
+				return
+			default:
+				text.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestEmail(t *testing.T) {
+	addr, received := fakeSMTP(t)
+	dispatcher, err := NewDispatcher(map[string]Notifier{
+		"mail": NewEmail(EmailConfig{Addr: addr, From: "scheduler@example.com", To: []string{"ops@example.com", "dev@example.com"}}),
+	}, []Route{{Channels: []string{"mail"}}}, fastRetry)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	a := firingAlert("报表")
+	dispatcher.HandleAlert(a)
+	dispatcher.Close(context.Background())
+
+	var data string
+	select {
+	case data = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email received")
+	}
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(data)))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("invalid email headers: %v\n%s", err, data)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	body, _ := io.ReadAll(reader.R)
+	if subject != "[FIRING] failing on task 报表" || header.Get("To") != "ops@example.com, dev@example.com" ||
+		!strings.Contains(string(body), "3 consecutive failed runs") {
+		t.Errorf("unexpected email:\n%s", data)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig(strings.NewReader(`{
+		"channels": {
+			"ops": {"type": "webhook", "url": "http://localhost:9/hook", "secret": "s"},
+			"chat": {"type": "chat", "url": "http://localhost:9/chat", "title": "{{.TaskID}}"}
+		},
+		"routes": [{"kinds": ["run_failed"], "channels": ["chat"]}],
+		"retry": {"attempts": 5, "backoff": "2s"}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	if config.Retry.Attempts != 5 || time.Duration(config.Retry.Backoff) != 2*time.Second {
+		t.Errorf("unexpected retry policy: %+v", config.Retry)
+	}
+	dispatcher, err := config.NewDispatcher(nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	dispatcher.Close(context.Background())
+
+	config.Routes[0].Kinds = []Kind{"run_exploded"}
+	if _, err := config.NewDispatcher(nil); err == nil {
+		t.Error("unknown kind should be rejected")
+	}
+	config.Channels["bad"] = ChannelConfig{Type: "pager"}
+	if _, err := config.NewDispatcher(nil); err == nil {
+		t.Error("unknown channel type should be rejected")
+	}
+}
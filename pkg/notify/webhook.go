@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader 请求体签名头，值为 sha256=<hex>
+	SignatureHeader = "X-Scheduler-Signature"
+	// TimestampHeader 签名时间戳头（Unix 秒），接收方可据此拒绝重放的请求
+	TimestampHeader = "X-Scheduler-Timestamp"
+	// signaturePrefix 签名值的算法前缀
+	signaturePrefix = "sha256="
+)
+
+// Sign 计算请求签名：HMAC-SHA256(secret, timestamp + "." + body)
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature 在接收方校验请求签名
+func VerifySignature(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// WebhookPayload 通用 JSON webhook 的请求体：通知数据及渲染后的标题和正文
+type WebhookPayload struct {
+	*Message
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// WebhookConfig 通用 JSON webhook 配置
+type WebhookConfig struct {
+	URL string
+	// Secret 签名密钥，为空时不签名
+	Secret string
+	// Headers 附加的请求头，如认证信息
+	Headers  map[string]string
+	Template *Template
+	// Client 为空时使用 http.DefaultClient
+	Client *http.Client
+}
+
+// Webhook 以 JSON POST 通知，配置了密钥时带 HMAC 签名
+type Webhook struct {
+	config WebhookConfig
+}
+
+// NewWebhook 创建通用 JSON webhook
+func NewWebhook(config WebhookConfig) *Webhook {
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return &Webhook{config: config}
+}
+
+// Notify 发送通知
+func (w *Webhook) Notify(ctx context.Context, msg *Message) error {
+	title, text, err := w.config.Template.Render(msg)
+	if err != nil {
+		return Permanent(err)
+	}
+	body, err := json.Marshal(WebhookPayload{Message: msg, Title: title, Text: text})
+	if err != nil {
+		return Permanent(fmt.Errorf("failed to encode webhook payload: %v", err))
+	}
+
+	headers := make(map[string]string, len(w.config.Headers)+2)
+	for key, value := range w.config.Headers {
+		headers[key] = value
+	}
+	if w.config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers[TimestampHeader] = timestamp
+		headers[SignatureHeader] = Sign(w.config.Secret, timestamp, body)
+	}
+	return postJSON(ctx, w.config.Client, w.config.URL, headers, body)
+}
+
+// DefaultChatTextField 聊天 incoming webhook 默认的消息字段（Slack、Mattermost、Rocket.Chat）
+const DefaultChatTextField = "text"
+
+// ChatConfig 聊天工具 incoming webhook 配置
+type ChatConfig struct {
+	URL string
+	// TextField 消息字段名，为空时使用 DefaultChatTextField；Discord 为 "content"
+	TextField string
+	Template  *Template
+	Client    *http.Client
+}
+
+// Chat 向聊天工具的 incoming webhook 发送一条文本消息：标题加粗，正文另起一行
+type Chat struct {
+	config ChatConfig
+}
+
+// NewChat 创建聊天 incoming webhook
+func NewChat(config ChatConfig) *Chat {
+	if config.TextField == "" {
+		config.TextField = DefaultChatTextField
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return &Chat{config: config}
+}
+
+// Notify 发送通知
+func (c *Chat) Notify(ctx context.Context, msg *Message) error {
+	title, text, err := c.config.Template.Render(msg)
+	if err != nil {
+		return Permanent(err)
+	}
+	message := "*" + title + "*"
+	if text != "" {
+		message += "\n" + text
+	}
+	body, err := json.Marshal(map[string]string{c.config.TextField: message})
+	if err != nil {
+		return Permanent(fmt.Errorf("failed to encode chat message: %v", err))
+	}
+	return postJSON(ctx, c.config.Client, c.config.URL, nil, body)
+}
+
+// postJSON 发送 JSON 请求；4xx（请求超时和限流除外）为不可重试的错误
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	err = fmt.Errorf("%s responded %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(message)))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
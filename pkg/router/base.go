@@ -1,6 +1,11 @@
 package router
 
 import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"time"
+
 	"task_scheduler/pkg/types"
 )
 
@@ -14,22 +19,149 @@ func (br *BaseRouter) GetStrategy() types.RouteStrategy {
 	return br.strategy
 }
 
+// OnTaskRemoved 默认无任务级状态，无需清理
+func (br *BaseRouter) OnTaskRemoved(taskID string) {}
+
+// OnExecutorRemoved 默认无执行器级状态，无需清理
+func (br *BaseRouter) OnExecutorRemoved(executorID string) {}
+
+// Snapshotter 支持状态快照与恢复的路由器
+// 快照为JSON格式，可跨进程重启持久化
+type Snapshotter interface {
+	Snapshot() (json.RawMessage, error)
+	Restore(data json.RawMessage) error
+}
+
+// Randomized 使用随机数的路由器，可注入随机源以便复现路由序列
+type Randomized interface {
+	SetSource(src rand.Source)
+}
+
+// idleTracker 记录任务最近一次路由的时间，用于按TTL淘汰长期未使用的任务状态
+// 非并发安全，由所属路由器的锁保护
+type idleTracker struct {
+	ttl        time.Duration
+	lastAccess map[string]time.Time // taskID -> 最近路由时间
+	lastSweep  time.Time
+}
+
+// touch 记录任务访问，开启TTL且距离上次清扫超过TTL时返回已过期的任务
+func (t *idleTracker) touch(taskID string, now time.Time) []string {
+	if t.ttl <= 0 {
+		return nil
+	}
+	if t.lastAccess == nil {
+		t.lastAccess = make(map[string]time.Time)
+		t.lastSweep = now
+	}
+	t.lastAccess[taskID] = now
+
+	if now.Sub(t.lastSweep) < t.ttl {
+		return nil
+	}
+	t.lastSweep = now
+
+	var expired []string
+	for id, lastAccess := range t.lastAccess {
+		if now.Sub(lastAccess) >= t.ttl {
+			expired = append(expired, id)
+			delete(t.lastAccess, id)
+		}
+	}
+	return expired
+}
+
+// forget 移除任务的访问记录
+func (t *idleTracker) forget(taskID string) {
+	delete(t.lastAccess, taskID)
+}
+
 // Factory 路由器工厂
-type Factory struct{}
+type Factory struct {
+	// InFlight 在途运行数跟踪器，供感知负载的路由器共享
+	InFlight *InFlightTracker
+	// Latency 运行耗时与错误率跟踪器，供延迟感知路由器共享
+	Latency *LatencyTracker
+	// LatencyAlpha 创建延迟跟踪器时的EWMA平滑系数，不在(0,1]范围时使用默认值
+	LatencyAlpha float64
+	// Exploration 延迟感知路由的探索概率，0使用默认值，小于0表示不探索
+	Exploration float64
+	// ErrorPenalty 延迟感知路由的错误率惩罚，0使用默认值
+	ErrorPenalty time.Duration
+	// MaxErrorRate 延迟感知路由的错误率上限，0使用默认值，小于0表示不设上限
+	MaxErrorRate float64
+	// GlobalLFUHalfLife 全局LFU频率衰减的半衰期，0表示不衰减
+	GlobalLFUHalfLife time.Duration
+	// TaskStateTTL 任务级路由状态的空闲淘汰时间，0表示不淘汰
+	TaskStateTTL time.Duration
+	// Seed 随机种子，非0时各随机化路由器使用由其派生的确定性随机源
+	Seed int64
+	// NewSource 自定义随机源构造函数，优先于Seed
+	NewSource func(strategy types.RouteStrategy) rand.Source
+}
 
 // CreateRouter 根据策略创建路由器
 func (rf *Factory) CreateRouter(strategy types.RouteStrategy) types.Router {
+	router := rf.createRouter(strategy)
+	if randomized, ok := router.(Randomized); ok {
+		if src := rf.source(strategy); src != nil {
+			randomized.SetSource(src)
+		}
+	}
+	return router
+}
+
+// source 获取策略对应的随机源，未配置时返回nil
+func (rf *Factory) source(strategy types.RouteStrategy) rand.Source {
+	if rf.NewSource != nil {
+		return rf.NewSource(strategy)
+	}
+	if rf.Seed != 0 {
+		// 按策略派生种子，使各路由器的随机序列互相独立又可复现
+		return rand.NewSource(rf.Seed + int64(strategy))
+	}
+	return nil
+}
+
+// createRouter 根据策略创建路由器实例
+func (rf *Factory) createRouter(strategy types.RouteStrategy) types.Router {
 	switch strategy {
 	case types.RoundRobinTask:
-		return NewRoundRobinTaskRouter()
+		router := NewRoundRobinTaskRouter()
+		router.SetTaskTTL(rf.TaskStateTTL)
+		return router
 	case types.RoundRobinApp:
 		return NewRoundRobinAppRouter()
 	case types.Random:
 		return NewRandomRouter()
 	case types.LFU:
-		return NewLFURouter()
+		router := NewLFURouter()
+		router.SetTaskTTL(rf.TaskStateTTL)
+		return router
 	case types.LRU:
-		return NewLRURouter()
+		router := NewLRURouter()
+		router.SetTaskTTL(rf.TaskStateTTL)
+		return router
+	case types.LeastOutstanding:
+		return NewLeastOutstandingRouter(rf.InFlight)
+	case types.PowerOfTwoChoices:
+		return NewPowerOfTwoRouter(rf.InFlight)
+	case types.LatencyAware:
+		router := NewLatencyAwareRouter(rf.Latency)
+		if rf.Exploration != 0 {
+			router.Exploration = math.Max(rf.Exploration, 0)
+		}
+		if rf.ErrorPenalty > 0 {
+			router.ErrorPenalty = rf.ErrorPenalty
+		}
+		if rf.MaxErrorRate != 0 {
+			router.MaxErrorRate = math.Max(rf.MaxErrorRate, 0)
+		}
+		return router
+	case types.GlobalLFU:
+		return NewGlobalLFURouter(rf.GlobalLFUHalfLife)
+	case types.GlobalLRU:
+		return NewGlobalLRURouter()
 	default:
 		return NewRoundRobinAppRouter() // 默认使用应用级别轮询
 	}
@@ -0,0 +1,197 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+// decayedCount 执行器的时间衰减使用频率
+type decayedCount struct {
+	Score     float64   `json:"score"`      // 衰减后的频率
+	UpdatedAt time.Time `json:"updated_at"` // 上次衰减的时间
+	// LastUsage 上次观测到的执行器使用次数，执行器的计数在重启后归零，因此不持久化
+	LastUsage int64 `json:"-"`
+	// pending 已选中但执行器使用次数尚未体现的次数，避免同一次使用被计入两次
+	pending int64
+	// baselined 已以执行器当前使用次数为基线；恢复的条目在首次观测时重新建立基线
+	baselined bool
+}
+
+// GlobalLFURouter 全局最少使用路由器
+// 基于Executor.GetUsageCount()统计的全局使用次数选择执行器，
+// 能平衡所有策略（包括其他任务和其他路由器）造成的负载。
+// 选中时立即计入频率，执行器开始执行前的一批触发也会被分散到不同执行器
+type GlobalLFURouter struct {
+	BaseRouter
+	halfLife time.Duration            // 衰减半衰期，0表示不衰减
+	decayed  map[string]*decayedCount // executorID -> 衰减频率
+	now      func() time.Time
+	mutex    sync.Mutex
+}
+
+// NewGlobalLFURouter 创建全局LFU路由器
+// halfLife大于0时使用时间衰减频率，避免久远的历史主导选择
+func NewGlobalLFURouter(halfLife time.Duration) *GlobalLFURouter {
+	return &GlobalLFURouter{
+		BaseRouter: BaseRouter{strategy: types.GlobalLFU},
+		halfLife:   halfLife,
+		decayed:    make(map[string]*decayedCount),
+		now:        time.Now,
+	}
+}
+
+// Route 全局LFU路由，选中的执行器在持有锁时计入一次使用
+func (r *GlobalLFURouter) Route(task *types.Task, executors []types.Executor) (types.Executor, error) {
+	if len(executors) == 0 {
+		return nil, errors.New("no available executors")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.now()
+	var selected *decayedCount
+	var selectedExecutor types.Executor
+	for _, executor := range executors {
+		entry := r.frequency(executor, now)
+		if selected == nil || entry.Score < selected.Score {
+			selected = entry
+			selectedExecutor = executor
+		}
+	}
+
+	selected.Score++
+	selected.pending++
+	return selectedExecutor, nil
+}
+
+// frequency 按执行器使用次数的增量和衰减更新频率
+// 使用次数的增量先抵消已计入的选中次数，其余来自其他策略或本路由器之外的执行
+func (r *GlobalLFURouter) frequency(executor types.Executor, now time.Time) *decayedCount {
+	usage := executor.GetUsageCount()
+	entry, exists := r.decayed[executor.GetID()]
+	if !exists {
+		// 首次观测时以当前使用次数为基线，历史使用不参与衰减计算
+		entry = &decayedCount{Score: float64(usage), LastUsage: usage, UpdatedAt: now, baselined: true}
+		r.decayed[executor.GetID()] = entry
+		return entry
+	}
+
+	if elapsed := now.Sub(entry.UpdatedAt); r.halfLife > 0 && elapsed > 0 {
+		entry.Score *= math.Exp2(-float64(elapsed) / float64(r.halfLife))
+	}
+	if !entry.baselined {
+		// 恢复后首次观测：保留恢复的频率，只把当前使用次数作为之后增量的基线
+		entry.baselined = true
+	} else if delta := usage - entry.LastUsage; delta > 0 {
+		absorbed := delta
+		if absorbed > entry.pending {
+			absorbed = entry.pending
+		}
+		entry.pending -= absorbed
+		entry.Score += float64(delta - absorbed)
+	}
+	entry.LastUsage = usage
+	entry.UpdatedAt = now
+	return entry
+}
+
+// OnExecutorRemoved 释放执行器的衰减频率
+func (r *GlobalLFURouter) OnExecutorRemoved(executorID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.decayed, executorID)
+}
+
+// globalLFUState 全局LFU路由器的持久化状态
+type globalLFUState struct {
+	Decayed map[string]*decayedCount `json:"decayed"` // executorID -> 衰减频率
+}
+
+// Snapshot 导出各执行器的衰减频率
+func (r *GlobalLFURouter) Snapshot() (json.RawMessage, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return json.Marshal(globalLFUState{Decayed: r.decayed})
+}
+
+// Restore 恢复各执行器的衰减频率
+// 只恢复频率和衰减时间，执行器使用次数的基线在每个条目首次被观测时重新建立
+func (r *GlobalLFURouter) Restore(data json.RawMessage) error {
+	var state globalLFUState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid global LFU state: %v", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for executorID, entry := range state.Decayed {
+		r.decayed[executorID] = entry
+	}
+	return nil
+}
+
+// GlobalLRURouter 全局最久未使用路由器
+// 基于Executor.GetLastUsedTime()选择全局最久未被使用的执行器；
+// 选中时立即记录选中时间，执行器开始执行前的一批触发也会被分散到不同执行器
+type GlobalLRURouter struct {
+	BaseRouter
+	lastSelected map[string]time.Time // executorID -> 最近选中时间
+	latest       time.Time            // 最近一次选中时间，保证选中时间严格递增
+	now          func() time.Time
+	mutex        sync.Mutex
+}
+
+// NewGlobalLRURouter 创建全局LRU路由器
+func NewGlobalLRURouter() *GlobalLRURouter {
+	return &GlobalLRURouter{
+		BaseRouter:   BaseRouter{strategy: types.GlobalLRU},
+		lastSelected: make(map[string]time.Time),
+		now:          time.Now,
+	}
+}
+
+// Route 全局LRU路由，执行器的最后使用时间取实际使用和本路由器选中时间中较晚的一个
+func (r *GlobalLRURouter) Route(task *types.Task, executors []types.Executor) (types.Executor, error) {
+	if len(executors) == 0 {
+		return nil, errors.New("no available executors")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var selectedExecutor types.Executor
+	var oldestTime time.Time
+	for _, executor := range executors {
+		lastUsed := executor.GetLastUsedTime()
+		if selected := r.lastSelected[executor.GetID()]; selected.After(lastUsed) {
+			lastUsed = selected
+		}
+		if selectedExecutor == nil || lastUsed.Before(oldestTime) {
+			oldestTime = lastUsed
+			selectedExecutor = executor
+		}
+	}
+
+	// 同一时钟刻度内的多次选中也按先后区分
+	stamp := r.now()
+	if !stamp.After(r.latest) {
+		stamp = r.latest.Add(time.Nanosecond)
+	}
+	r.latest = stamp
+	r.lastSelected[selectedExecutor.GetID()] = stamp
+	return selectedExecutor, nil
+}
+
+// OnExecutorRemoved 释放执行器的选中时间
+func (r *GlobalLRURouter) OnExecutorRemoved(executorID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.lastSelected, executorID)
+}
@@ -0,0 +1,83 @@
+package router
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// InFlightTracker 执行器在途运行数跟踪器
+// 由调度器在派发前Acquire、执行结束后调用其返回的释放函数，供感知负载的路由器读取
+type InFlightTracker struct {
+	counts map[string]*int64 // executorID -> 在途运行数
+	mutex  sync.RWMutex
+}
+
+// NewInFlightTracker 创建在途运行数跟踪器
+func NewInFlightTracker() *InFlightTracker {
+	return &InFlightTracker{
+		counts: make(map[string]*int64),
+	}
+}
+
+// counter 获取执行器的计数器，不存在时创建
+func (t *InFlightTracker) counter(executorID string) *int64 {
+	t.mutex.RLock()
+	counter, exists := t.counts[executorID]
+	t.mutex.RUnlock()
+	if exists {
+		return counter
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	// 双重检查
+	if counter, exists = t.counts[executorID]; !exists {
+		counter = new(int64)
+		t.counts[executorID] = counter
+	}
+	return counter
+}
+
+// Acquire 记录一次派发到执行器的运行，返回运行结束时调用的释放函数
+// 释放函数作用于派发时的计数器：执行器被移除后以相同ID重新加入时，
+// 旧运行的释放不会影响新的计数；重复调用只释放一次
+func (t *InFlightTracker) Acquire(executorID string) (release func()) {
+	counter := t.counter(executorID)
+	atomic.AddInt64(counter, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.AddInt64(counter, -1)
+		})
+	}
+}
+
+// Remove 移除执行器的计数器，之后的Acquire使用新的计数器
+func (t *InFlightTracker) Remove(executorID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.counts, executorID)
+}
+
+// Count 获取执行器当前的在途运行数
+func (t *InFlightTracker) Count(executorID string) int64 {
+	t.mutex.RLock()
+	counter, exists := t.counts[executorID]
+	t.mutex.RUnlock()
+	if !exists {
+		return 0
+	}
+	return atomic.LoadInt64(counter)
+}
+
+// Snapshot 获取所有执行器在途运行数的快照
+func (t *InFlightTracker) Snapshot() map[string]int64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	snapshot := make(map[string]int64, len(t.counts))
+	for executorID, counter := range t.counts {
+		snapshot[executorID] = atomic.LoadInt64(counter)
+	}
+	return snapshot
+}
@@ -0,0 +1,291 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+const (
+	// DefaultLatencyAlpha EWMA默认平滑系数
+	DefaultLatencyAlpha = 0.3
+	// DefaultExploration 默认探索概率
+	DefaultExploration = 0.05
+	// DefaultErrorPenalty 默认的错误率惩罚：错误率为1时得分增加的耗时
+	DefaultErrorPenalty = 5 * time.Second
+	// DefaultMaxErrorRate 默认的错误率上限，超过时只在所有执行器都超过时才会被选中
+	DefaultMaxErrorRate = 0.5
+)
+
+// latencyStat 单个维度的EWMA统计
+type latencyStat struct {
+	latency   float64 // 运行耗时的EWMA（纳秒）
+	errorRate float64 // 错误率的EWMA
+	samples   int64
+}
+
+// observe 更新EWMA，首个样本直接作为初始值
+func (s *latencyStat) observe(alpha float64, duration time.Duration, failed bool) {
+	errValue := 0.0
+	if failed {
+		errValue = 1.0
+	}
+	if s.samples == 0 {
+		s.latency = float64(duration)
+		s.errorRate = errValue
+	} else {
+		s.latency = alpha*float64(duration) + (1-alpha)*s.latency
+		s.errorRate = alpha*errValue + (1-alpha)*s.errorRate
+	}
+	s.samples++
+}
+
+// LatencyStats 执行器延迟统计快照
+type LatencyStats struct {
+	Latency   time.Duration `json:"latency"`
+	ErrorRate float64       `json:"error_rate"`
+	Samples   int64         `json:"samples"`
+}
+
+// LatencyTracker 执行器运行耗时与错误率跟踪器
+// 同时按执行器和按(handler, 执行器)两个维度维护EWMA
+type LatencyTracker struct {
+	alpha     float64
+	executors map[string]*latencyStat            // executorID -> stat
+	handlers  map[string]map[string]*latencyStat // handler -> executorID -> stat
+	mutex     sync.RWMutex
+}
+
+// NewLatencyTracker 创建延迟跟踪器，alpha不在(0,1]范围时使用默认值
+func NewLatencyTracker(alpha float64) *LatencyTracker {
+	if alpha <= 0 || alpha > 1 {
+		alpha = DefaultLatencyAlpha
+	}
+	return &LatencyTracker{
+		alpha:     alpha,
+		executors: make(map[string]*latencyStat),
+		handlers:  make(map[string]map[string]*latencyStat),
+	}
+}
+
+// Observe 记录一次运行结果
+func (t *LatencyTracker) Observe(executorID, handler string, duration time.Duration, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stat, exists := t.executors[executorID]
+	if !exists {
+		stat = &latencyStat{}
+		t.executors[executorID] = stat
+	}
+	stat.observe(t.alpha, duration, err != nil)
+
+	if handler == "" {
+		return
+	}
+	handlerStats, exists := t.handlers[handler]
+	if !exists {
+		handlerStats = make(map[string]*latencyStat)
+		t.handlers[handler] = handlerStats
+	}
+	stat, exists = handlerStats[executorID]
+	if !exists {
+		stat = &latencyStat{}
+		handlerStats[executorID] = stat
+	}
+	stat.observe(t.alpha, duration, err != nil)
+}
+
+// Get 获取执行器的统计，handler非空且有样本时优先返回handler维度
+func (t *LatencyTracker) Get(executorID, handler string) (LatencyStats, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if handler != "" {
+		if stat, exists := t.handlers[handler][executorID]; exists && stat.samples > 0 {
+			return stat.snapshot(), true
+		}
+	}
+	if stat, exists := t.executors[executorID]; exists && stat.samples > 0 {
+		return stat.snapshot(), true
+	}
+	return LatencyStats{}, false
+}
+
+// Remove 移除执行器在所有维度上的统计
+func (t *LatencyTracker) Remove(executorID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.executors, executorID)
+	for handler, handlerStats := range t.handlers {
+		delete(handlerStats, executorID)
+		if len(handlerStats) == 0 {
+			delete(t.handlers, handler)
+		}
+	}
+}
+
+// latencyState 延迟跟踪器的持久化状态
+type latencyState struct {
+	Executors map[string]LatencyStats            `json:"executors"`
+	Handlers  map[string]map[string]LatencyStats `json:"handlers"`
+}
+
+// Snapshot 导出所有维度的EWMA统计
+func (t *LatencyTracker) Snapshot() (json.RawMessage, error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	state := latencyState{
+		Executors: make(map[string]LatencyStats, len(t.executors)),
+		Handlers:  make(map[string]map[string]LatencyStats, len(t.handlers)),
+	}
+	for executorID, stat := range t.executors {
+		state.Executors[executorID] = stat.snapshot()
+	}
+	for handler, handlerStats := range t.handlers {
+		stats := make(map[string]LatencyStats, len(handlerStats))
+		for executorID, stat := range handlerStats {
+			stats[executorID] = stat.snapshot()
+		}
+		state.Handlers[handler] = stats
+	}
+	return json.Marshal(state)
+}
+
+// Restore 恢复所有维度的EWMA统计
+func (t *LatencyTracker) Restore(data json.RawMessage) error {
+	var state latencyState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid latency state: %v", err)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for executorID, stats := range state.Executors {
+		t.executors[executorID] = newLatencyStat(stats)
+	}
+	for handler, handlerStats := range state.Handlers {
+		if _, exists := t.handlers[handler]; !exists {
+			t.handlers[handler] = make(map[string]*latencyStat, len(handlerStats))
+		}
+		for executorID, stats := range handlerStats {
+			t.handlers[handler][executorID] = newLatencyStat(stats)
+		}
+	}
+	return nil
+}
+
+// newLatencyStat 从统计快照恢复EWMA状态
+func newLatencyStat(stats LatencyStats) *latencyStat {
+	return &latencyStat{
+		latency:   float64(stats.Latency),
+		errorRate: stats.ErrorRate,
+		samples:   stats.Samples,
+	}
+}
+
+// snapshot 转换为对外的统计快照
+func (s *latencyStat) snapshot() LatencyStats {
+	return LatencyStats{
+		Latency:   time.Duration(s.latency),
+		ErrorRate: s.errorRate,
+		Samples:   s.samples,
+	}
+}
+
+// LatencyAwareRouter 延迟感知路由器
+// 优先选择EWMA耗时短、错误率低的执行器，并以一定概率探索其他执行器
+type LatencyAwareRouter struct {
+	BaseRouter
+	tracker *LatencyTracker
+	// Exploration 随机探索概率，保证慢执行器恢复后能被重新发现
+	Exploration float64
+	// ErrorPenalty 错误率惩罚，得分 = 耗时 + 错误率 * ErrorPenalty
+	// 惩罚是加性的，快速失败的执行器不会因耗时短而排在健康执行器之前
+	ErrorPenalty time.Duration
+	// MaxErrorRate 错误率上限，超过上限的执行器只在所有执行器都超过时才参与选择；0表示不设上限
+	MaxErrorRate float64
+	// PerHandler 是否按handler区分统计（无样本时回退到执行器维度）
+	PerHandler bool
+	rnd        *rand.Rand
+	mutex      sync.Mutex
+}
+
+// NewLatencyAwareRouter 创建延迟感知路由器
+func NewLatencyAwareRouter(tracker *LatencyTracker) *LatencyAwareRouter {
+	if tracker == nil {
+		tracker = NewLatencyTracker(DefaultLatencyAlpha)
+	}
+	return &LatencyAwareRouter{
+		BaseRouter:   BaseRouter{strategy: types.LatencyAware},
+		tracker:      tracker,
+		Exploration:  DefaultExploration,
+		ErrorPenalty: DefaultErrorPenalty,
+		MaxErrorRate: DefaultMaxErrorRate,
+		PerHandler:   true,
+		rnd:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Route 延迟感知路由
+func (r *LatencyAwareRouter) Route(task *types.Task, executors []types.Executor) (types.Executor, error) {
+	if len(executors) == 0 {
+		return nil, errors.New("no available executors")
+	}
+
+	r.mutex.Lock()
+	explore := r.rnd.Float64() < r.Exploration
+	exploreIndex := r.rnd.Intn(len(executors))
+	r.mutex.Unlock()
+
+	if explore {
+		return executors[exploreIndex], nil
+	}
+
+	handler := ""
+	if r.PerHandler {
+		handler = task.Handler
+	}
+
+	// 分别记录错误率未超过上限和超过上限的最优执行器
+	var healthy, degraded types.Executor
+	healthyScore, degradedScore := -1.0, -1.0
+
+	for _, executor := range executors {
+		stats, exists := r.tracker.Get(executor.GetID(), handler)
+		if !exists {
+			// 没有样本的执行器优先选择，尽快获得测量数据
+			return executor, nil
+		}
+
+		score := float64(stats.Latency) + stats.ErrorRate*float64(r.ErrorPenalty)
+		if r.MaxErrorRate > 0 && stats.ErrorRate > r.MaxErrorRate {
+			if degradedScore < 0 || score < degradedScore {
+				degradedScore = score
+				degraded = executor
+			}
+		} else if healthyScore < 0 || score < healthyScore {
+			healthyScore = score
+			healthy = executor
+		}
+	}
+
+	if healthy != nil {
+		return healthy, nil
+	}
+	return degraded, nil
+}
+
+// SetSource 注入随机源，相同的随机源可复现相同的探索序列
+func (r *LatencyAwareRouter) SetSource(src rand.Source) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.rnd = rand.New(src)
+}
@@ -1,7 +1,9 @@
 package router
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 type LFURouter struct {
 	BaseRouter
 	taskCounters map[string]map[string]int64 // taskID -> executorID -> count
+	idle         idleTracker
 	mutex        sync.RWMutex
 }
 
@@ -38,6 +41,9 @@ func (r *LFURouter) Route(task *types.Task, executors []types.Executor) (types.E
 	}
 
 	taskCounter := r.taskCounters[task.ID]
+	for _, taskID := range r.idle.touch(task.ID, time.Now()) {
+		delete(r.taskCounters, taskID)
+	}
 
 	// 找到使用次数最少的执行器
 	var selectedExecutor types.Executor
@@ -64,10 +70,62 @@ func (r *LFURouter) Route(task *types.Task, executors []types.Executor) (types.E
 	return selectedExecutor, nil
 }
 
+// SetTaskTTL 设置任务计数的空闲淘汰时间，0表示不淘汰
+func (r *LFURouter) SetTaskTTL(ttl time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.idle.ttl = ttl
+}
+
+// OnTaskRemoved 释放任务的使用计数
+func (r *LFURouter) OnTaskRemoved(taskID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.taskCounters, taskID)
+	r.idle.forget(taskID)
+}
+
+// OnExecutorRemoved 释放所有任务中该执行器的使用计数
+func (r *LFURouter) OnExecutorRemoved(executorID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, taskCounter := range r.taskCounters {
+		delete(taskCounter, executorID)
+	}
+}
+
+// lfuState LFU路由器的持久化状态
+type lfuState struct {
+	Counters map[string]map[string]int64 `json:"counters"` // taskID -> executorID -> count
+}
+
+// Snapshot 导出各任务的执行器使用计数
+func (r *LFURouter) Snapshot() (json.RawMessage, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return json.Marshal(lfuState{Counters: r.taskCounters})
+}
+
+// Restore 恢复各任务的执行器使用计数
+func (r *LFURouter) Restore(data json.RawMessage) error {
+	var state lfuState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid LFU state: %v", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for taskID, counters := range state.Counters {
+		r.taskCounters[taskID] = counters
+	}
+	return nil
+}
+
 // LRURouter 最近最久未使用路由器
 type LRURouter struct {
 	BaseRouter
 	taskLastUsed map[string]map[string]time.Time // taskID -> executorID -> lastUsedTime
+	idle         idleTracker
 	mutex        sync.RWMutex
 }
 
@@ -95,6 +153,9 @@ func (r *LRURouter) Route(task *types.Task, executors []types.Executor) (types.E
 
 	taskUsage := r.taskLastUsed[task.ID]
 	now := time.Now()
+	for _, taskID := range r.idle.touch(task.ID, now) {
+		delete(r.taskLastUsed, taskID)
+	}
 
 	// 找到最久未使用的执行器
 	var selectedExecutor types.Executor
@@ -122,3 +183,54 @@ func (r *LRURouter) Route(task *types.Task, executors []types.Executor) (types.E
 
 	return selectedExecutor, nil
 }
+
+// SetTaskTTL 设置任务使用时间记录的空闲淘汰时间，0表示不淘汰
+func (r *LRURouter) SetTaskTTL(ttl time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.idle.ttl = ttl
+}
+
+// OnTaskRemoved 释放任务的使用时间记录
+func (r *LRURouter) OnTaskRemoved(taskID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.taskLastUsed, taskID)
+	r.idle.forget(taskID)
+}
+
+// OnExecutorRemoved 释放所有任务中该执行器的使用时间记录
+func (r *LRURouter) OnExecutorRemoved(executorID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, taskUsage := range r.taskLastUsed {
+		delete(taskUsage, executorID)
+	}
+}
+
+// lruState LRU路由器的持久化状态
+type lruState struct {
+	LastUsed map[string]map[string]time.Time `json:"last_used"` // taskID -> executorID -> lastUsedTime
+}
+
+// Snapshot 导出各任务的执行器最后使用时间
+func (r *LRURouter) Snapshot() (json.RawMessage, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return json.Marshal(lruState{LastUsed: r.taskLastUsed})
+}
+
+// Restore 恢复各任务的执行器最后使用时间
+func (r *LRURouter) Restore(data json.RawMessage) error {
+	var state lruState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid LRU state: %v", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for taskID, lastUsed := range state.LastUsed {
+		r.taskLastUsed[taskID] = lastUsed
+	}
+	return nil
+}
@@ -1,23 +1,84 @@
 package router
 
 import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
 	"sync"
+	"time"
 
 	"task_scheduler/pkg/types"
 )
 
 // MultiStrategyRouter 多策略路由器管理
 type MultiStrategyRouter struct {
-	routers map[types.RouteStrategy]types.Router
-	factory *Factory
-	mutex   sync.RWMutex
+	routers  map[types.RouteStrategy]types.Router
+	factory  *Factory
+	inFlight *InFlightTracker
+	latency  *LatencyTracker
+	mutex    sync.RWMutex
+}
+
+// Option 多策略路由器配置项，作用于内部的路由器工厂
+type Option func(factory *Factory)
+
+// WithTaskStateTTL 设置任务级路由状态的空闲淘汰时间
+func WithTaskStateTTL(ttl time.Duration) Option {
+	return func(factory *Factory) {
+		factory.TaskStateTTL = ttl
+	}
+}
+
+// WithGlobalLFUHalfLife 设置全局LFU频率衰减的半衰期
+func WithGlobalLFUHalfLife(halfLife time.Duration) Option {
+	return func(factory *Factory) {
+		factory.GlobalLFUHalfLife = halfLife
+	}
+}
+
+// WithLatencyAware 设置延迟感知路由的参数：EWMA平滑系数、探索概率、错误率惩罚和错误率上限，
+// 零值使用默认值，探索概率或错误率上限小于0时关闭对应机制
+func WithLatencyAware(alpha, exploration float64, errorPenalty time.Duration, maxErrorRate float64) Option {
+	return func(factory *Factory) {
+		factory.LatencyAlpha = alpha
+		factory.Exploration = exploration
+		factory.ErrorPenalty = errorPenalty
+		factory.MaxErrorRate = maxErrorRate
+	}
+}
+
+// WithSeed 设置随机种子，相同种子下可复现相同的路由序列
+func WithSeed(seed int64) Option {
+	return func(factory *Factory) {
+		factory.Seed = seed
+	}
+}
+
+// WithSourceFunc 设置自定义随机源构造函数
+func WithSourceFunc(newSource func(strategy types.RouteStrategy) rand.Source) Option {
+	return func(factory *Factory) {
+		factory.NewSource = newSource
+	}
 }
 
 // NewMultiStrategyRouter 创建多策略路由器
-func NewMultiStrategyRouter() *MultiStrategyRouter {
+func NewMultiStrategyRouter(opts ...Option) *MultiStrategyRouter {
+	inFlight := NewInFlightTracker()
+	factory := &Factory{InFlight: inFlight}
+	for _, opt := range opts {
+		opt(factory)
+	}
+	latency := NewLatencyTracker(factory.LatencyAlpha)
+	factory.Latency = latency
+	if factory.Seed == 0 && factory.NewSource == nil {
+		// 未指定种子时也生成一个确定的种子，便于事后按种子回放路由序列
+		factory.Seed = time.Now().UnixNano()
+	}
 	return &MultiStrategyRouter{
-		routers: make(map[types.RouteStrategy]types.Router),
-		factory: &Factory{},
+		routers:  make(map[types.RouteStrategy]types.Router),
+		factory:  factory,
+		inFlight: inFlight,
+		latency:  latency,
 	}
 }
 
@@ -44,3 +105,109 @@ func (msr *MultiStrategyRouter) Route(task *types.Task, executors []types.Execut
 func (msr *MultiStrategyRouter) GetStrategy() types.RouteStrategy {
 	return types.RoundRobinApp // 默认策略
 }
+
+// OnTaskRemoved 通知所有已创建的路由器释放任务状态
+func (msr *MultiStrategyRouter) OnTaskRemoved(taskID string) {
+	msr.mutex.RLock()
+	defer msr.mutex.RUnlock()
+
+	for _, router := range msr.routers {
+		router.OnTaskRemoved(taskID)
+	}
+}
+
+// OnExecutorRemoved 通知所有已创建的路由器及共享跟踪器释放执行器状态
+func (msr *MultiStrategyRouter) OnExecutorRemoved(executorID string) {
+	msr.mutex.RLock()
+	defer msr.mutex.RUnlock()
+
+	for _, router := range msr.routers {
+		router.OnExecutorRemoved(executorID)
+	}
+	msr.inFlight.Remove(executorID)
+	msr.latency.Remove(executorID)
+}
+
+// State 多策略路由器的持久化状态
+type State struct {
+	SavedAt    time.Time                               `json:"saved_at"`
+	Strategies map[types.RouteStrategy]json.RawMessage `json:"strategies"` // 各策略路由器的快照
+	Latency    json.RawMessage                         `json:"latency,omitempty"`
+}
+
+// Snapshot 导出所有支持快照的路由器状态
+// 在途运行数反映的是进程内的实时负载，不参与持久化
+func (msr *MultiStrategyRouter) Snapshot() (*State, error) {
+	msr.mutex.RLock()
+	defer msr.mutex.RUnlock()
+
+	state := &State{
+		SavedAt:    time.Now(),
+		Strategies: make(map[types.RouteStrategy]json.RawMessage),
+	}
+	for strategy, router := range msr.routers {
+		snapshotter, ok := router.(Snapshotter)
+		if !ok {
+			continue
+		}
+		data, err := snapshotter.Snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot router %v: %v", strategy, err)
+		}
+		state.Strategies[strategy] = data
+	}
+
+	latency, err := msr.latency.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot latency tracker: %v", err)
+	}
+	state.Latency = latency
+	return state, nil
+}
+
+// Restore 恢复路由器状态，尚未创建的策略路由器会被提前创建
+func (msr *MultiStrategyRouter) Restore(state *State) error {
+	if state == nil {
+		return nil
+	}
+
+	msr.mutex.Lock()
+	defer msr.mutex.Unlock()
+
+	for strategy, data := range state.Strategies {
+		router, exists := msr.routers[strategy]
+		if !exists {
+			router = msr.factory.CreateRouter(strategy)
+			msr.routers[strategy] = router
+		}
+		snapshotter, ok := router.(Snapshotter)
+		if !ok {
+			continue
+		}
+		if err := snapshotter.Restore(data); err != nil {
+			return fmt.Errorf("failed to restore router %v: %v", strategy, err)
+		}
+	}
+
+	if len(state.Latency) > 0 {
+		if err := msr.latency.Restore(state.Latency); err != nil {
+			return fmt.Errorf("failed to restore latency tracker: %v", err)
+		}
+	}
+	return nil
+}
+
+// Seed 获取随机种子（使用自定义随机源时为0）
+func (msr *MultiStrategyRouter) Seed() int64 {
+	return msr.factory.Seed
+}
+
+// InFlight 获取在途运行数跟踪器
+func (msr *MultiStrategyRouter) InFlight() *InFlightTracker {
+	return msr.inFlight
+}
+
+// Latency 获取运行耗时与错误率跟踪器
+func (msr *MultiStrategyRouter) Latency() *LatencyTracker {
+	return msr.latency
+}
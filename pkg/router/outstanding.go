@@ -0,0 +1,108 @@
+package router
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+// LeastOutstandingRouter 最少在途运行路由器
+// 选择当前在途运行数最少的执行器，长短任务混合时能自然均衡
+type LeastOutstandingRouter struct {
+	BaseRouter
+	inFlight   *InFlightTracker
+	tieCounter int64 // 在途数相同时轮询打散
+}
+
+// NewLeastOutstandingRouter 创建最少在途运行路由器
+func NewLeastOutstandingRouter(inFlight *InFlightTracker) *LeastOutstandingRouter {
+	if inFlight == nil {
+		inFlight = NewInFlightTracker()
+	}
+	return &LeastOutstandingRouter{
+		BaseRouter: BaseRouter{strategy: types.LeastOutstanding},
+		inFlight:   inFlight,
+	}
+}
+
+// Route 最少在途运行路由
+func (r *LeastOutstandingRouter) Route(task *types.Task, executors []types.Executor) (types.Executor, error) {
+	if len(executors) == 0 {
+		return nil, errors.New("no available executors")
+	}
+
+	// 从轮询偏移处开始扫描，避免在途数相同时总是选中第一个
+	offset := int(atomic.AddInt64(&r.tieCounter, 1) % int64(len(executors)))
+
+	var selectedExecutor types.Executor
+	minCount := int64(-1)
+
+	for i := 0; i < len(executors); i++ {
+		executor := executors[(offset+i)%len(executors)]
+		count := r.inFlight.Count(executor.GetID())
+		if minCount == -1 || count < minCount {
+			minCount = count
+			selectedExecutor = executor
+		}
+	}
+
+	return selectedExecutor, nil
+}
+
+// PowerOfTwoRouter 随机两选一路由器
+// 随机挑选两个执行器，选择在途运行数较少的一个
+type PowerOfTwoRouter struct {
+	BaseRouter
+	inFlight *InFlightTracker
+	rnd      *rand.Rand
+	mutex    sync.Mutex
+}
+
+// NewPowerOfTwoRouter 创建随机两选一路由器
+func NewPowerOfTwoRouter(inFlight *InFlightTracker) *PowerOfTwoRouter {
+	if inFlight == nil {
+		inFlight = NewInFlightTracker()
+	}
+	return &PowerOfTwoRouter{
+		BaseRouter: BaseRouter{strategy: types.PowerOfTwoChoices},
+		inFlight:   inFlight,
+		rnd:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Route 随机两选一路由
+func (r *PowerOfTwoRouter) Route(task *types.Task, executors []types.Executor) (types.Executor, error) {
+	if len(executors) == 0 {
+		return nil, errors.New("no available executors")
+	}
+	if len(executors) == 1 {
+		return executors[0], nil
+	}
+
+	r.mutex.Lock()
+	first := r.rnd.Intn(len(executors))
+	second := r.rnd.Intn(len(executors) - 1)
+	r.mutex.Unlock()
+
+	// 保证两次选择不重复
+	if second >= first {
+		second++
+	}
+
+	a, b := executors[first], executors[second]
+	if r.inFlight.Count(b.GetID()) < r.inFlight.Count(a.GetID()) {
+		return b, nil
+	}
+	return a, nil
+}
+
+// SetSource 注入随机源，相同的随机源可复现相同的路由序列
+func (r *PowerOfTwoRouter) SetSource(src rand.Source) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.rnd = rand.New(src)
+}
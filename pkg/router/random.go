@@ -36,3 +36,10 @@ func (r *RandomRouter) Route(task *types.Task, executors []types.Executor) (type
 
 	return executors[index], nil
 }
+
+// SetSource 注入随机源，相同的随机源可复现相同的路由序列
+func (r *RandomRouter) SetSource(src rand.Source) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.rnd = rand.New(src)
+}
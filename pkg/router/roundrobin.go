@@ -1,10 +1,13 @@
 package router
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math/rand"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"task_scheduler/pkg/types"
 )
@@ -13,6 +16,8 @@ import (
 type RoundRobinTaskRouter struct {
 	BaseRouter
 	taskCounters map[string]*int64 // 每个任务的计数器
+	idle         idleTracker
+	rnd          *rand.Rand // 初始偏移的随机源，由mutex保护
 	mutex        sync.RWMutex
 }
 
@@ -21,6 +26,7 @@ func NewRoundRobinTaskRouter() *RoundRobinTaskRouter {
 	return &RoundRobinTaskRouter{
 		BaseRouter:   BaseRouter{strategy: types.RoundRobinTask},
 		taskCounters: make(map[string]*int64),
+		rnd:          rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -34,17 +40,22 @@ func (r *RoundRobinTaskRouter) Route(task *types.Task, executors []types.Executo
 	counter, exists := r.taskCounters[task.ID]
 	if !exists {
 		// 初始化时随机一次，缓解首次压力
-		initialValue := int64(rand.Intn(100))
+		initialValue := int64(r.rnd.Intn(100))
 		counter = &initialValue
 		r.taskCounters[task.ID] = counter
 	}
+	for _, taskID := range r.idle.touch(task.ID, time.Now()) {
+		delete(r.taskCounters, taskID)
+	}
 	r.mutex.Unlock()
 
 	// 原子操作增加计数器
 	count := atomic.AddInt64(counter, 1)
 	if count > 1000000 {
 		// 重置计数器，避免溢出
-		atomic.StoreInt64(counter, int64(rand.Intn(100)))
+		r.mutex.Lock()
+		atomic.StoreInt64(counter, int64(r.rnd.Intn(100)))
+		r.mutex.Unlock()
 		count = atomic.LoadInt64(counter)
 	}
 
@@ -52,6 +63,61 @@ func (r *RoundRobinTaskRouter) Route(task *types.Task, executors []types.Executo
 	return executors[index], nil
 }
 
+// SetSource 注入初始偏移的随机源，相同的随机源可复现相同的路由序列
+func (r *RoundRobinTaskRouter) SetSource(src rand.Source) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.rnd = rand.New(src)
+}
+
+// SetTaskTTL 设置任务计数器的空闲淘汰时间，0表示不淘汰
+func (r *RoundRobinTaskRouter) SetTaskTTL(ttl time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.idle.ttl = ttl
+}
+
+// OnTaskRemoved 释放任务的计数器
+func (r *RoundRobinTaskRouter) OnTaskRemoved(taskID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.taskCounters, taskID)
+	r.idle.forget(taskID)
+}
+
+// roundRobinTaskState 任务级别轮询的持久化状态
+type roundRobinTaskState struct {
+	Counters map[string]int64 `json:"counters"` // taskID -> 计数器
+}
+
+// Snapshot 导出各任务的计数器
+func (r *RoundRobinTaskRouter) Snapshot() (json.RawMessage, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	state := roundRobinTaskState{Counters: make(map[string]int64, len(r.taskCounters))}
+	for taskID, counter := range r.taskCounters {
+		state.Counters[taskID] = atomic.LoadInt64(counter)
+	}
+	return json.Marshal(state)
+}
+
+// Restore 恢复各任务的计数器
+func (r *RoundRobinTaskRouter) Restore(data json.RawMessage) error {
+	var state roundRobinTaskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid round robin task state: %v", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for taskID, value := range state.Counters {
+		counter := value
+		r.taskCounters[taskID] = &counter
+	}
+	return nil
+}
+
 // RoundRobinAppRouter 应用级别轮询路由器
 type RoundRobinAppRouter struct {
 	BaseRouter
@@ -81,3 +147,23 @@ func (r *RoundRobinAppRouter) Route(task *types.Task, executors []types.Executor
 	index := int(count) % len(executors)
 	return executors[index], nil
 }
+
+// roundRobinAppState 应用级别轮询的持久化状态
+type roundRobinAppState struct {
+	Counter int64 `json:"counter"`
+}
+
+// Snapshot 导出全局计数器
+func (r *RoundRobinAppRouter) Snapshot() (json.RawMessage, error) {
+	return json.Marshal(roundRobinAppState{Counter: atomic.LoadInt64(&r.globalCounter)})
+}
+
+// Restore 恢复全局计数器
+func (r *RoundRobinAppRouter) Restore(data json.RawMessage) error {
+	var state roundRobinAppState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid round robin app state: %v", err)
+	}
+	atomic.StoreInt64(&r.globalCounter, state.Counter)
+	return nil
+}
@@ -1,6 +1,10 @@
 package router
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -72,6 +76,7 @@ func TestRoundRobinAppRouter(t *testing.T) {
 
 func TestRandomRouter(t *testing.T) {
 	router := NewRandomRouter()
+	router.SetSource(rand.NewSource(42))
 	executors := createTestExecutors()
 	task := createTestTask("random-task", types.Random)
 
@@ -85,7 +90,7 @@ func TestRandomRouter(t *testing.T) {
 		results[exec.GetID()]++
 	}
 
-	// 验证所有执行器都被使用过（概率性检查）
+	// 固定种子下结果确定，验证所有执行器都被使用过
 	for _, exec := range executors {
 		if results[exec.GetID()] == 0 {
 			t.Errorf("Executor %s was never selected in 100 random selections", exec.GetID())
@@ -93,6 +98,46 @@ func TestRandomRouter(t *testing.T) {
 	}
 }
 
+func TestSeededRoutersReplay(t *testing.T) {
+	executors := createTestExecutors()
+	strategies := []types.RouteStrategy{
+		types.RoundRobinTask,
+		types.Random,
+		types.PowerOfTwoChoices,
+		types.LatencyAware,
+	}
+
+	// 相同种子的两个路由器应当产生完全相同的路由序列
+	replay := func(seed int64) []string {
+		router := NewMultiStrategyRouter(WithSeed(seed))
+		var sequence []string
+		for i := 0; i < 20; i++ {
+			for _, strategy := range strategies {
+				exec, err := router.Route(createTestTask(fmt.Sprintf("task-%d", i%3), strategy), executors)
+				if err != nil {
+					t.Fatalf("Route failed: %v", err)
+				}
+				sequence = append(sequence, exec.GetID())
+			}
+		}
+		return sequence
+	}
+
+	first, second := replay(20261018), replay(20261018)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("routing diverged at step %d: %s vs %s", i, first[i], second[i])
+		}
+	}
+
+	if seed := NewMultiStrategyRouter(WithSeed(7)).Seed(); seed != 7 {
+		t.Errorf("expected seed 7, got %d", seed)
+	}
+	if seed := NewMultiStrategyRouter().Seed(); seed == 0 {
+		t.Error("a seed should be generated when none is given")
+	}
+}
+
 func TestLFURouter(t *testing.T) {
 	router := NewLFURouter()
 	executors := createTestExecutors()
@@ -140,6 +185,334 @@ func TestLRURouter(t *testing.T) {
 	}
 }
 
+func TestGlobalLFURouterBalancesAcrossStrategies(t *testing.T) {
+	executors := createTestExecutors()
+	taskRouter := NewRoundRobinTaskRouter()
+	globalRouter := NewGlobalLFURouter(0)
+
+	// 任务级轮询的大任务执行造成负载不均：exec-1、exec-2 各多执行若干次
+	executors[0].Execute(createTestTask("warmup", types.RoundRobinTask))
+	executors[0].Execute(createTestTask("warmup", types.RoundRobinTask))
+	executors[1].Execute(createTestTask("warmup", types.RoundRobinTask))
+	for i := 0; i < 6; i++ {
+		exec, _ := taskRouter.Route(createTestTask("big-task", types.RoundRobinTask), executors)
+		exec.Execute(createTestTask("big-task", types.RoundRobinTask))
+	}
+
+	// 全局LFU的任务应当补齐使用较少的执行器，最终各执行器使用次数接近
+	for i := 0; i < 9; i++ {
+		task := createTestTask("small-task", types.GlobalLFU)
+		exec, err := globalRouter.Route(task, executors)
+		if err != nil {
+			t.Fatalf("Route failed: %v", err)
+		}
+		exec.Execute(task)
+	}
+
+	minUsage, maxUsage := executors[0].GetUsageCount(), executors[0].GetUsageCount()
+	for _, exec := range executors {
+		usage := exec.GetUsageCount()
+		if usage < minUsage {
+			minUsage = usage
+		}
+		if usage > maxUsage {
+			maxUsage = usage
+		}
+	}
+	if maxUsage-minUsage > 1 {
+		t.Errorf("global LFU should balance fleet-wide usage, min=%d max=%d", minUsage, maxUsage)
+	}
+
+	// 按任务统计的LFU看不到其他策略造成的负载
+	taskLFU := NewLFURouter()
+	busy := executor.NewSimpleExecutor("busy", "http://localhost:8009")
+	for i := 0; i < 10; i++ {
+		busy.Execute(createTestTask("other", types.Random))
+	}
+	exec, _ := taskLFU.Route(createTestTask("lfu-task", types.LFU), []types.Executor{busy, executors[0]})
+	if exec.GetID() != "busy" {
+		t.Errorf("task-scoped LFU is expected to ignore global usage, got %s", exec.GetID())
+	}
+	exec, _ = globalRouter.Route(createTestTask("lfu-task", types.GlobalLFU), []types.Executor{busy, executors[0]})
+	if exec.GetID() == "busy" {
+		t.Error("global LFU should avoid the executor busy with other strategies")
+	}
+}
+
+func TestGlobalLFURouterDecay(t *testing.T) {
+	executors := createTestExecutors()[:2]
+	router := NewGlobalLFURouter(time.Minute)
+	now := time.Now()
+	router.now = func() time.Time { return now }
+
+	// exec-1 历史上使用很多次，exec-2 最近才开始使用
+	for i := 0; i < 100; i++ {
+		executors[0].IncrementUsage()
+	}
+	router.Route(createTestTask("decay-task", types.GlobalLFU), executors)
+
+	// 十个半衰期后旧的使用记录几乎衰减殆尽
+	now = now.Add(10 * time.Minute)
+	for i := 0; i < 5; i++ {
+		executors[1].IncrementUsage()
+	}
+	exec, _ := router.Route(createTestTask("decay-task", types.GlobalLFU), executors)
+	if exec.GetID() != "exec-1" {
+		t.Errorf("old usage should decay and let exec-1 be selected, got %s", exec.GetID())
+	}
+
+	// 不衰减时历史使用次数仍然主导选择
+	exec, _ = NewGlobalLFURouter(0).Route(createTestTask("decay-task", types.GlobalLFU), executors)
+	if exec.GetID() != "exec-2" {
+		t.Errorf("without decay the raw usage count should win, got %s", exec.GetID())
+	}
+}
+
+func TestGlobalLFURouterRestoreWithFreshExecutors(t *testing.T) {
+	executors := createTestExecutors()[:2]
+	original := NewGlobalLFURouter(0)
+	for i := 0; i < 30; i++ {
+		executors[0].IncrementUsage()
+	}
+	for i := 0; i < 10; i++ {
+		executors[1].IncrementUsage()
+	}
+	original.Route(createTestTask("restore-task", types.GlobalLFU), executors)
+	executors[1].IncrementUsage()
+	data, err := original.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	// 重启后执行器的使用次数从0开始
+	fresh := createTestExecutors()[:2]
+	restored := NewGlobalLFURouter(0)
+	if err := restored.Restore(data); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	exec, _ := restored.Route(createTestTask("restore-task", types.GlobalLFU), fresh)
+	if exec.GetID() != "exec-2" {
+		t.Errorf("restored frequencies should steer away from exec-1, got %s", exec.GetID())
+	}
+
+	// 重启后新产生的使用必须计入频率
+	for i := 0; i < 25; i++ {
+		fresh[1].IncrementUsage()
+	}
+	exec, _ = restored.Route(createTestTask("restore-task", types.GlobalLFU), fresh)
+	if exec.GetID() != "exec-1" {
+		t.Errorf("usage after restore should be counted, got %s", exec.GetID())
+	}
+}
+
+func TestGlobalLRURouter(t *testing.T) {
+	executors := createTestExecutors()
+	router := NewGlobalLRURouter()
+
+	// 其他策略刚刚使用过 exec-1 和 exec-3
+	time.Sleep(2 * time.Millisecond)
+	executors[0].Execute(createTestTask("other", types.Random))
+	executors[2].Execute(createTestTask("other", types.RoundRobinApp))
+
+	exec, err := router.Route(createTestTask("lru-task", types.GlobalLRU), executors)
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if exec.GetID() != "exec-2" {
+		t.Errorf("global LRU should pick the executor idle longest fleet-wide, got %s", exec.GetID())
+	}
+}
+
+func TestGlobalRoutersSpreadBurst(t *testing.T) {
+	// 一批触发在执行器开始执行（使用次数增加）之前连续路由，选择应分散到各执行器
+	for _, router := range []types.Router{NewGlobalLFURouter(0), NewGlobalLFURouter(time.Minute), NewGlobalLRURouter()} {
+		executors := createTestExecutors()
+		counts := make(map[string]int)
+		for i := 0; i < 9; i++ {
+			exec, err := router.Route(createTestTask("burst-task", router.GetStrategy()), executors)
+			if err != nil {
+				t.Fatalf("Route failed: %v", err)
+			}
+			counts[exec.GetID()]++
+		}
+		for _, exec := range executors {
+			if counts[exec.GetID()] != 3 {
+				t.Errorf("%s: burst should be spread evenly, got %v", router.GetStrategy(), counts)
+				break
+			}
+		}
+
+		// 选中的执行随后完成，不应被重复计入
+		for _, exec := range executors {
+			for i := 0; i < counts[exec.GetID()]; i++ {
+				exec.Execute(createTestTask("burst-task", router.GetStrategy()))
+			}
+		}
+		executors[0].Execute(createTestTask("other", types.Random))
+		exec, _ := router.Route(createTestTask("burst-task", router.GetStrategy()), executors)
+		if exec.GetID() == "exec-1" {
+			t.Errorf("%s: executor used by another strategy should be avoided", router.GetStrategy())
+		}
+	}
+}
+
+func TestLeastOutstandingRouter(t *testing.T) {
+	inFlight := NewInFlightTracker()
+	router := NewLeastOutstandingRouter(inFlight)
+	executors := createTestExecutors()
+	task := createTestTask("lo-task", types.LeastOutstanding)
+
+	// exec-1 和 exec-3 上有长任务在运行
+	inFlight.Acquire("exec-1")
+	inFlight.Acquire("exec-1")
+	inFlight.Acquire("exec-3")
+
+	for i := 0; i < 5; i++ {
+		exec, err := router.Route(task, executors)
+		if err != nil {
+			t.Fatalf("Route failed: %v", err)
+		}
+		if exec.GetID() != "exec-2" {
+			t.Errorf("expected exec-2 with no outstanding runs, got %s", exec.GetID())
+		}
+	}
+
+	// 负载相同时应当分散到不同执行器
+	inFlight.Acquire("exec-2")
+	inFlight.Acquire("exec-3")
+	inFlight.Acquire("exec-2")
+	results := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		exec, _ := router.Route(task, executors)
+		results[exec.GetID()]++
+	}
+	if len(results) != len(executors) {
+		t.Errorf("ties should be spread across all executors, got %v", results)
+	}
+}
+
+func TestPowerOfTwoRouter(t *testing.T) {
+	inFlight := NewInFlightTracker()
+	router := NewPowerOfTwoRouter(inFlight)
+	executors := createTestExecutors()
+	task := createTestTask("p2c-task", types.PowerOfTwoChoices)
+
+	// exec-1 负载最高，任意两选一都不应选中它
+	for i := 0; i < 10; i++ {
+		inFlight.Acquire("exec-1")
+	}
+	inFlight.Acquire("exec-2")
+
+	for i := 0; i < 50; i++ {
+		exec, err := router.Route(task, executors)
+		if err != nil {
+			t.Fatalf("Route failed: %v", err)
+		}
+		if exec.GetID() == "exec-1" {
+			t.Fatal("power of two choices should never pick the most loaded executor")
+		}
+	}
+}
+
+func TestLatencyAwareRouter(t *testing.T) {
+	tracker := NewLatencyTracker(0.5)
+	router := NewLatencyAwareRouter(tracker)
+	router.Exploration = 0
+	executors := createTestExecutors()
+	task := createTestTask("latency-task", types.LatencyAware)
+
+	// 未测量过的执行器优先被选中
+	exec, _ := router.Route(task, executors)
+	if exec.GetID() != "exec-1" {
+		t.Errorf("expected unmeasured exec-1 first, got %s", exec.GetID())
+	}
+
+	// 远端机房的exec-1在该handler上慢3倍，exec-2偶有失败
+	for i := 0; i < 5; i++ {
+		tracker.Observe("exec-1", task.Handler, 300*time.Millisecond, nil)
+		tracker.Observe("exec-2", task.Handler, 100*time.Millisecond, errors.New("boom"))
+		tracker.Observe("exec-3", task.Handler, 120*time.Millisecond, nil)
+	}
+	exec, _ = router.Route(task, executors)
+	if exec.GetID() != "exec-3" {
+		t.Errorf("expected fast and healthy exec-3, got %s", exec.GetID())
+	}
+
+	// 其他handler没有样本时回退到执行器维度的统计
+	other := createTestTask("other-task", types.LatencyAware)
+	other.Handler = "otherHandler"
+	exec, _ = router.Route(other, executors)
+	if exec.GetID() != "exec-3" {
+		t.Errorf("expected executor-level fallback to pick exec-3, got %s", exec.GetID())
+	}
+
+	// 快速失败的执行器不应排在健康但较慢的执行器之前
+	fast := createTestTask("fast-failing", types.LatencyAware)
+	fast.Handler = "fastFailingHandler"
+	for i := 0; i < 5; i++ {
+		tracker.Observe("exec-1", fast.Handler, 5*time.Millisecond, errors.New("connection refused"))
+		tracker.Observe("exec-2", fast.Handler, 100*time.Millisecond, nil)
+		tracker.Observe("exec-3", fast.Handler, 30*time.Second, nil)
+	}
+	if exec, _ = router.Route(fast, executors); exec.GetID() != "exec-2" {
+		t.Errorf("expected healthy exec-2 over the fast failing exec-1, got %s", exec.GetID())
+	}
+	// 超过错误率上限的执行器只在没有其他选择时被选中，即使健康执行器很慢
+	if exec, _ = router.Route(fast, executors[:1]); exec.GetID() != "exec-1" {
+		t.Errorf("a degraded executor should still be picked when it is the only one, got %s", exec.GetID())
+	}
+	router.ErrorPenalty, router.MaxErrorRate = time.Millisecond, 0
+	if exec, _ = router.Route(fast, []types.Executor{executors[0], executors[2]}); exec.GetID() != "exec-1" {
+		t.Errorf("without a cutoff the penalty alone decides, got %s", exec.GetID())
+	}
+	router.MaxErrorRate = DefaultMaxErrorRate
+	if exec, _ = router.Route(fast, []types.Executor{executors[0], executors[2]}); exec.GetID() != "exec-3" {
+		t.Errorf("the cutoff should keep the failing executor out, got %s", exec.GetID())
+	}
+
+	// 工厂按配置设置参数
+	factory := &Factory{Latency: tracker, Exploration: -1, ErrorPenalty: time.Second, MaxErrorRate: 0.2}
+	configured := factory.CreateRouter(types.LatencyAware).(*LatencyAwareRouter)
+	if configured.Exploration != 0 || configured.ErrorPenalty != time.Second || configured.MaxErrorRate != 0.2 {
+		t.Errorf("factory settings not applied: %+v", configured)
+	}
+
+	// 探索概率为1时应当覆盖所有执行器
+	router.Exploration = 1
+	results := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		exec, _ = router.Route(task, executors)
+		results[exec.GetID()]++
+	}
+	if len(results) != len(executors) {
+		t.Errorf("exploration should reach every executor, got %v", results)
+	}
+}
+
+func TestInFlightTracker(t *testing.T) {
+	inFlight := NewInFlightTracker()
+	inFlight.Acquire("exec-1")
+	release := inFlight.Acquire("exec-1")
+	release()
+	release()
+
+	if count := inFlight.Count("exec-1"); count != 1 {
+		t.Errorf("expected 1 in-flight run, got %d", count)
+	}
+	if snapshot := inFlight.Snapshot(); snapshot["exec-1"] != 1 {
+		t.Errorf("unexpected snapshot: %v", snapshot)
+	}
+
+	// 移除后以相同ID重新加入，旧运行的结束不影响新的计数
+	stale := inFlight.Acquire("exec-2")
+	inFlight.Remove("exec-2")
+	inFlight.Acquire("exec-2")
+	stale()
+	if count := inFlight.Count("exec-2"); count != 1 {
+		t.Errorf("release from a removed executor should not count against the new one, got %d", count)
+	}
+}
+
 func TestMultiStrategyRouter(t *testing.T) {
 	router := NewMultiStrategyRouter()
 	executors := createTestExecutors()
@@ -151,6 +524,11 @@ func TestMultiStrategyRouter(t *testing.T) {
 		types.Random,
 		types.LFU,
 		types.LRU,
+		types.LeastOutstanding,
+		types.PowerOfTwoChoices,
+		types.LatencyAware,
+		types.GlobalLFU,
+		types.GlobalLRU,
 	}
 
 	for _, strategy := range strategies {
@@ -176,6 +554,100 @@ func TestEmptyExecutors(t *testing.T) {
 	}
 }
 
+func TestRouterStateReleasedOnRemoval(t *testing.T) {
+	router := NewMultiStrategyRouter()
+	executors := createTestExecutors()
+
+	for _, strategy := range []types.RouteStrategy{types.RoundRobinTask, types.LFU, types.LRU} {
+		task := createTestTask("one-off", strategy)
+		if _, err := router.Route(task, executors); err != nil {
+			t.Fatalf("Route failed: %v", err)
+		}
+	}
+	router.OnTaskRemoved("one-off")
+	router.OnExecutorRemoved("exec-1")
+
+	if size := len(router.routers[types.RoundRobinTask].(*RoundRobinTaskRouter).taskCounters); size != 0 {
+		t.Errorf("round robin counters should be released, got %d entries", size)
+	}
+	if size := len(router.routers[types.LFU].(*LFURouter).taskCounters); size != 0 {
+		t.Errorf("LFU counters should be released, got %d entries", size)
+	}
+	if size := len(router.routers[types.LRU].(*LRURouter).taskLastUsed); size != 0 {
+		t.Errorf("LRU timestamps should be released, got %d entries", size)
+	}
+
+	// 执行器移除后，其他任务中该执行器的记录也应当被释放
+	lfu := NewLFURouter()
+	lfu.Route(createTestTask("task-a", types.LFU), executors)
+	lfu.OnExecutorRemoved("exec-1")
+	if _, exists := lfu.taskCounters["task-a"]["exec-1"]; exists {
+		t.Error("LFU should drop counters of removed executors")
+	}
+}
+
+func TestRouterStateTTLEviction(t *testing.T) {
+	router := NewLRURouter()
+	router.SetTaskTTL(20 * time.Millisecond)
+	executors := createTestExecutors()
+
+	for i := 0; i < 100; i++ {
+		router.Route(createTestTask(fmt.Sprintf("short-lived-%d", i), types.LRU), executors)
+	}
+	time.Sleep(30 * time.Millisecond)
+	router.Route(createTestTask("long-running", types.LRU), executors)
+
+	if size := len(router.taskLastUsed); size != 1 {
+		t.Errorf("idle task state should be evicted after TTL, got %d entries", size)
+	}
+}
+
+func TestMultiStrategyRouterSnapshotRestore(t *testing.T) {
+	executors := createTestExecutors()
+	original := NewMultiStrategyRouter()
+
+	rrTask := createTestTask("rr-task", types.RoundRobinTask)
+	lfuTask := createTestTask("lfu-task", types.LFU)
+	lruTask := createTestTask("lru-task", types.LRU)
+	for i := 0; i < 4; i++ {
+		original.Route(rrTask, executors)
+		original.Route(createTestTask("app-task", types.RoundRobinApp), executors)
+		original.Route(lfuTask, executors)
+		original.Route(lruTask, executors)
+	}
+	original.Latency().Observe("exec-1", "h", 300*time.Millisecond, nil)
+
+	state, err := original.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded State
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	restored := NewMultiStrategyRouter()
+	if err := restored.Restore(&decoded); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	// 恢复后的路由序列应当与原路由器完全一致
+	for _, task := range []*types.Task{rrTask, createTestTask("app-task", types.RoundRobinApp), lfuTask, lruTask} {
+		want, _ := original.Route(task, executors)
+		got, _ := restored.Route(task, executors)
+		if want.GetID() != got.GetID() {
+			t.Errorf("strategy %v: restored router picked %s, want %s", task.Strategy, got.GetID(), want.GetID())
+		}
+	}
+	if _, exists := restored.Latency().Get("exec-1", "h"); !exists {
+		t.Error("latency statistics should be restored")
+	}
+}
+
 // 基准测试
 func BenchmarkRoundRobinTaskRouter(b *testing.B) {
 	router := NewRoundRobinTaskRouter()
@@ -198,3 +670,31 @@ func BenchmarkRandomRouter(b *testing.B) {
 		_, _ = router.Route(task, executors)
 	}
 }
+
+// BenchmarkRouterStateChurn 模拟大量一次性任务的创建和删除，报告路由状态的条目数
+func BenchmarkRouterStateChurn(b *testing.B) {
+	router := NewMultiStrategyRouter()
+	executors := createTestExecutors()
+	strategies := []types.RouteStrategy{types.RoundRobinTask, types.LFU, types.LRU}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		task := createTestTask(fmt.Sprintf("one-off-%d", i), strategies[i%len(strategies)])
+		_, _ = router.Route(task, executors)
+		router.OnTaskRemoved(task.ID)
+	}
+	b.StopTimer()
+
+	entries := len(router.routers[types.RoundRobinTask].(*RoundRobinTaskRouter).taskCounters)
+	if lfu, ok := router.routers[types.LFU].(*LFURouter); ok {
+		entries += len(lfu.taskCounters)
+	}
+	if lru, ok := router.routers[types.LRU].(*LRURouter); ok {
+		entries += len(lru.taskLastUsed)
+	}
+	if entries != 0 {
+		b.Fatalf("router state should stay bounded under churn, got %d entries", entries)
+	}
+	b.ReportMetric(float64(entries), "entries")
+}
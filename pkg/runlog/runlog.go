@@ -0,0 +1,348 @@
+package runlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 日志流名称
+const (
+	// StreamStdout 执行器的标准输出
+	StreamStdout = "stdout"
+	// StreamStderr 执行器的标准错误
+	StreamStderr = "stderr"
+	// StreamScheduler 调度器记录的生命周期事件
+	StreamScheduler = "scheduler"
+)
+
+const (
+	// DefaultMaxBytesPerRun 每次运行默认保留的日志字节数
+	DefaultMaxBytesPerRun = 1 << 20
+	// DefaultMaxRuns 默认保留日志的已结束运行数
+	DefaultMaxRuns = 1000
+	// logFileSuffix 持久化日志文件的后缀
+	logFileSuffix = ".log"
+)
+
+// ErrNotFound 运行没有日志（未派发或已被保留策略清理）
+var ErrNotFound = errors.New("run log not found")
+
+// Line 一行运行日志，Seq 在一次运行内从1开始递增
+type Line struct {
+	Seq    int64     `json:"seq"`
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"`
+	Text   string    `json:"text"`
+}
+
+// String 文本格式：时间 [流] 内容
+func (l Line) String() string {
+	return fmt.Sprintf("%s [%s] %s", l.Time.Format(time.RFC3339Nano), l.Stream, l.Text)
+}
+
+// Snapshot 读取到的运行日志
+type Snapshot struct {
+	Lines    []Line `json:"lines"`
+	Dropped  int64  `json:"dropped"`  // 因大小上限丢弃的最早行数
+	Finished bool   `json:"finished"` // 运行已结束，不会再有新的日志
+}
+
+// Options 日志存储的配置
+type Options struct {
+	// MaxBytesPerRun 每次运行保留的日志字节数，超出时丢弃最早的行，0使用默认值
+	MaxBytesPerRun int
+	// MaxRuns 保留日志的已结束运行数，超出时清理最早结束的运行，0使用默认值
+	MaxRuns int
+	// MaxAge 已结束运行的日志保留时长，0表示不按时长清理
+	MaxAge time.Duration
+	// Dir 持久化目录，为空时只保存在内存中；运行结束后日志写入 {Dir}/{runID}.log 并释放内存
+	Dir string
+}
+
+// runLog 一次运行的日志
+type runLog struct {
+	lines      []Line
+	bytes      int
+	nextSeq    int64
+	finished   bool
+	finishedAt time.Time
+	persisted  bool          // 日志已写入文件，lines 为空
+	changed    chan struct{} // 有新日志或结束时关闭并替换，用于跟随
+}
+
+// Store 按运行保存执行日志，限制每次运行的大小并按保留策略清理已结束的运行
+type Store struct {
+	opts     Options
+	logs     map[string]*runLog
+	finished []string // 按结束顺序排列的运行ID
+	mutex    sync.Mutex
+}
+
+// NewStore 创建日志存储，配置了 Dir 时加载目录中已有的日志
+func NewStore(opts Options) (*Store, error) {
+	if opts.MaxBytesPerRun <= 0 {
+		opts.MaxBytesPerRun = DefaultMaxBytesPerRun
+	}
+	if opts.MaxRuns <= 0 {
+		opts.MaxRuns = DefaultMaxRuns
+	}
+	s := &Store{opts: opts, logs: make(map[string]*runLog)}
+	if opts.Dir != "" {
+		if err := s.loadDir(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// loadDir 登记目录中已持久化的日志，按修改时间作为结束时间
+func (s *Store) loadDir() error {
+	if err := os.MkdirAll(s.opts.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create run log directory: %v", err)
+	}
+	entries, err := os.ReadDir(s.opts.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read run log directory: %v", err)
+	}
+
+	type persisted struct {
+		runID      string
+		finishedAt time.Time
+	}
+	var found []persisted
+	for _, entry := range entries {
+		runID, ok := strings.CutSuffix(entry.Name(), logFileSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, persisted{runID, info.ModTime()})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].finishedAt.Before(found[j].finishedAt) })
+	for _, log := range found {
+		s.logs[log.runID] = &runLog{finished: true, finishedAt: log.finishedAt, persisted: true, changed: make(chan struct{})}
+		s.finished = append(s.finished, log.runID)
+	}
+	s.evictLocked(time.Now())
+	return nil
+}
+
+// Append 追加运行日志，时间为空时使用当前时间；运行结束后追加的日志被忽略
+func (s *Store) Append(runID, stream, text string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	log := s.openLocked(runID)
+	if log.finished {
+		return
+	}
+	if len(text) > s.opts.MaxBytesPerRun {
+		text = text[:s.opts.MaxBytesPerRun]
+	}
+	log.nextSeq++
+	log.lines = append(log.lines, Line{Seq: log.nextSeq, Time: time.Now(), Stream: stream, Text: text})
+	log.bytes += len(text)
+
+	// 超出大小上限时丢弃最早的行，保留最接近失败现场的日志
+	drop := 0
+	for log.bytes > s.opts.MaxBytesPerRun {
+		log.bytes -= len(log.lines[drop].Text)
+		drop++
+	}
+	if drop > 0 {
+		log.lines = append(log.lines[:0], log.lines[drop:]...)
+	}
+	log.notify()
+}
+
+// Finish 标记运行结束，唤醒跟随者并按保留策略清理
+func (s *Store) Finish(runID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	log := s.openLocked(runID)
+	if log.finished {
+		return
+	}
+	log.finished = true
+	log.finishedAt = time.Now()
+	s.finished = append(s.finished, runID)
+	if s.opts.Dir != "" {
+		if err := s.persistLocked(runID, log); err == nil {
+			log.lines = nil
+			log.persisted = true
+		}
+	}
+	log.notify()
+	s.evictLocked(log.finishedAt)
+}
+
+// Read 读取运行日志，tail 大于0时只返回最后 tail 行
+func (s *Store) Read(runID string, tail int) (*Snapshot, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	log, exists := s.logs[runID]
+	if !exists {
+		return nil, fmt.Errorf("run %s: %w", runID, ErrNotFound)
+	}
+	lines := log.lines
+	if log.persisted {
+		loaded, err := s.loadLocked(runID)
+		if err != nil {
+			return nil, err
+		}
+		lines = loaded
+	}
+	snapshot := &Snapshot{Finished: log.finished}
+	if len(lines) > 0 {
+		snapshot.Dropped = lines[0].Seq - 1
+	} else {
+		snapshot.Dropped = log.nextSeq
+	}
+	if tail > 0 && len(lines) > tail {
+		snapshot.Dropped += int64(len(lines) - tail)
+		lines = lines[len(lines)-tail:]
+	}
+	snapshot.Lines = append([]Line(nil), lines...)
+	return snapshot, nil
+}
+
+// Follow 返回序号大于 after 的日志，没有新日志且运行未结束时阻塞直到有新日志、运行结束或 ctx 取消
+// 返回的 finished 为真时不会再有新的日志
+func (s *Store) Follow(ctx context.Context, runID string, after int64) (lines []Line, finished bool, err error) {
+	for {
+		s.mutex.Lock()
+		log, exists := s.logs[runID]
+		if !exists {
+			s.mutex.Unlock()
+			return nil, true, fmt.Errorf("run %s: %w", runID, ErrNotFound)
+		}
+		current := log.lines
+		if log.persisted {
+			if current, err = s.loadLocked(runID); err != nil {
+				s.mutex.Unlock()
+				return nil, true, err
+			}
+		}
+		for _, line := range current {
+			if line.Seq > after {
+				lines = append(lines, line)
+			}
+		}
+		finished = log.finished
+		changed := log.changed
+		s.mutex.Unlock()
+
+		if len(lines) > 0 || finished {
+			return lines, finished, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+// openLocked 获取运行日志，不存在时创建
+func (s *Store) openLocked(runID string) *runLog {
+	log, exists := s.logs[runID]
+	if !exists {
+		log = &runLog{changed: make(chan struct{})}
+		s.logs[runID] = log
+	}
+	return log
+}
+
+// notify 唤醒等待新日志的跟随者
+func (l *runLog) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// evictLocked 清理超出数量或时长的已结束运行
+func (s *Store) evictLocked(now time.Time) {
+	evict := 0
+	for evict < len(s.finished) {
+		log := s.logs[s.finished[evict]]
+		expired := s.opts.MaxAge > 0 && log != nil && now.Sub(log.finishedAt) > s.opts.MaxAge
+		if len(s.finished)-evict <= s.opts.MaxRuns && !expired {
+			break
+		}
+		runID := s.finished[evict]
+		if log != nil && log.persisted {
+			os.Remove(s.path(runID))
+		}
+		delete(s.logs, runID)
+		evict++
+	}
+	if evict > 0 {
+		s.finished = append(s.finished[:0], s.finished[evict:]...)
+	}
+}
+
+// path 持久化日志的文件路径
+func (s *Store) path(runID string) string {
+	return filepath.Join(s.opts.Dir, filepath.Base(runID)+logFileSuffix)
+}
+
+// persistLocked 把已结束运行的日志写入文件，每行一个 JSON 对象
+func (s *Store) persistLocked(runID string, log *runLog) error {
+	tmp := s.path(runID) + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, line := range log.lines {
+		if err := encoder.Encode(line); err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, s.path(runID))
+}
+
+// loadLocked 读取持久化的日志
+func (s *Store) loadLocked(runID string) ([]Line, error) {
+	file, err := os.Open(s.path(runID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log of run %s: %v", runID, err)
+	}
+	defer file.Close()
+
+	var lines []Line
+	decoder := json.NewDecoder(file)
+	for {
+		var line Line
+		if err := decoder.Decode(&line); err != nil {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
@@ -0,0 +1,136 @@
+package runlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func texts(lines []Line) string {
+	var parts []string
+	for _, line := range lines {
+		parts = append(parts, line.Text)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestSizeCapAndTail(t *testing.T) {
+	store, _ := NewStore(Options{MaxBytesPerRun: 10})
+	for i := 1; i <= 6; i++ {
+		store.Append("run-1", StreamStdout, fmt.Sprintf("l%d", i))
+	}
+	store.Append("run-1", StreamStderr, "0123456789abc")
+
+	snapshot, err := store.Read("run-1", 0)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	// 超长的行被截断到上限，之前的行全部丢弃
+	if texts(snapshot.Lines) != "0123456789" || snapshot.Dropped != 6 || snapshot.Finished {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+
+	store.Append("run-2", StreamStdout, "a")
+	store.Append("run-2", StreamStdout, "b")
+	store.Append("run-2", StreamStdout, "c")
+	snapshot, _ = store.Read("run-2", 2)
+	if texts(snapshot.Lines) != "b,c" || snapshot.Dropped != 1 || snapshot.Lines[0].Seq != 2 {
+		t.Errorf("unexpected tail: %+v", snapshot)
+	}
+	if _, err := store.Read("missing", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRetentionAndPersistence(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(Options{MaxRuns: 2, Dir: dir})
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	for _, runID := range []string{"run-1", "run-2", "run-3"} {
+		store.Append(runID, StreamStdout, "output of "+runID)
+		store.Finish(runID)
+	}
+	store.Append("run-3", StreamStdout, "ignored after finish")
+
+	if _, err := store.Read("run-1", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("oldest run should be evicted, got %v", err)
+	}
+	snapshot, err := store.Read("run-3", 0)
+	if err != nil || texts(snapshot.Lines) != "output of run-3" || !snapshot.Finished {
+		t.Fatalf("unexpected persisted log: %+v %v", snapshot, err)
+	}
+
+	// 重新打开后仍能读取
+	reopened, err := NewStore(Options{MaxRuns: 2, Dir: dir})
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	snapshot, err = reopened.Read("run-2", 0)
+	if err != nil || texts(snapshot.Lines) != "output of run-2" || !snapshot.Finished {
+		t.Errorf("unexpected reloaded log: %+v %v", snapshot, err)
+	}
+	if _, err := reopened.Read("run-1", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("evicted log should stay deleted, got %v", err)
+	}
+
+	aged, _ := NewStore(Options{MaxAge: time.Millisecond})
+	aged.Append("old", StreamStdout, "x")
+	aged.Finish("old")
+	time.Sleep(5 * time.Millisecond)
+	aged.Append("new", StreamStdout, "y")
+	aged.Finish("new")
+	if _, err := aged.Read("old", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired log should be evicted, got %v", err)
+	}
+}
+
+func TestFollowAndOutput(t *testing.T) {
+	store, _ := NewStore(Options{})
+	ctx := WithSink(context.Background(), store.Sink("run-1"))
+	out := Output(ctx, StreamStdout)
+	io.WriteString(out, "first\r\nsec")
+
+	lines, finished, err := store.Follow(context.Background(), "run-1", 0)
+	if err != nil || texts(lines) != "first" || finished {
+		t.Fatalf("unexpected follow result: %v %v %v", lines, finished, err)
+	}
+
+	result := make(chan []Line)
+	go func() {
+		lines, _, _ := store.Follow(context.Background(), "run-1", 1)
+		result <- lines
+	}()
+	time.Sleep(10 * time.Millisecond)
+	io.WriteString(out, "ond\n")
+	select {
+	case lines := <-result:
+		if texts(lines) != "second" {
+			t.Errorf("unexpected followed lines: %v", lines)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("follower was not woken up")
+	}
+
+	io.WriteString(out, "partial")
+	out.Close()
+	store.Finish("run-1")
+	lines, finished, _ = store.Follow(context.Background(), "run-1", 2)
+	if texts(lines) != "partial" || !finished {
+		t.Errorf("unexpected final lines: %v %v", lines, finished)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	store.Append("run-2", StreamStdout, "x")
+	if _, _, err := store.Follow(cancelled, "run-2", 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+	// 没有接收端时丢弃
+	Output(context.Background(), StreamStdout).Write([]byte("dropped\n"))
+}
@@ -0,0 +1,95 @@
+package runlog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// Sink 接收一次运行的日志行
+type Sink interface {
+	Log(stream, text string)
+}
+
+// runSink 写入存储中指定运行的日志
+type runSink struct {
+	store *Store
+	runID string
+}
+
+func (s runSink) Log(stream, text string) {
+	s.store.Append(s.runID, stream, text)
+}
+
+// Sink 返回写入指定运行日志的接收端
+func (s *Store) Sink(runID string) Sink {
+	return runSink{store: s, runID: runID}
+}
+
+// sinkKey 上下文中保存日志接收端的键
+type sinkKey struct{}
+
+// WithSink 返回携带日志接收端的上下文，调度器据此把执行上下文与运行日志关联
+func WithSink(ctx context.Context, sink Sink) context.Context {
+	return context.WithValue(ctx, sinkKey{}, sink)
+}
+
+// SinkFromContext 返回上下文中的日志接收端，没有时返回nil
+func SinkFromContext(ctx context.Context) Sink {
+	sink, _ := ctx.Value(sinkKey{}).(Sink)
+	return sink
+}
+
+// Log 向上下文中的接收端写入一行日志，没有接收端时忽略
+func Log(ctx context.Context, stream, text string) {
+	if sink := SinkFromContext(ctx); sink != nil {
+		sink.Log(stream, text)
+	}
+}
+
+// Output 返回按行写入运行日志的 Writer，本地执行器可以把子进程的标准输出接到这里：
+//
+//	cmd.Stdout = runlog.Output(ctx, runlog.StreamStdout)
+//
+// 没有接收端时写入的内容被丢弃；未以换行结束的最后一行在 Close 时写入
+func Output(ctx context.Context, stream string) io.WriteCloser {
+	return &lineWriter{sink: SinkFromContext(ctx), stream: stream}
+}
+
+// lineWriter 按行切分写入的内容
+type lineWriter struct {
+	sink    Sink
+	stream  string
+	partial []byte
+	mutex   sync.Mutex
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.sink == nil {
+		return len(p), nil
+	}
+	data := append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		w.sink.Log(w.stream, string(bytes.TrimSuffix(data[:i], []byte{'\r'})))
+		data = data[i+1:]
+	}
+	w.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+func (w *lineWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.sink != nil && len(w.partial) > 0 {
+		w.sink.Log(w.stream, string(w.partial))
+	}
+	w.partial = nil
+	return nil
+}
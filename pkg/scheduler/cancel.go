@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/types"
+)
+
+// CancelRun 取消正在执行的运行
+// 实现 types.RunExecutor 的执行器通过ctx收到取消通知；其他执行器执行结束后运行记录为已取消
+func (ts *TaskScheduler) CancelRun(runID string) error {
+	ts.runMutex.Lock()
+	cancel, exists := ts.runCancels[runID]
+	ts.runMutex.Unlock()
+
+	if !exists {
+		if _, err := ts.store.GetRun(runID); err != nil {
+			return err
+		}
+		return fmt.Errorf("run %s: %w", runID, types.ErrRunFinished)
+	}
+
+	cancel()
+	ts.logger.Info("Cancellation requested", logging.KeyRunID, runID)
+	return nil
+}
+
+// trackRun 登记运行的取消函数
+func (ts *TaskScheduler) trackRun(runID string, cancel context.CancelFunc) {
+	ts.runMutex.Lock()
+	defer ts.runMutex.Unlock()
+	ts.runCancels[runID] = cancel
+}
+
+// untrackRun 注销运行并释放其上下文
+func (ts *TaskScheduler) untrackRun(runID string) {
+	ts.runMutex.Lock()
+	cancel, exists := ts.runCancels[runID]
+	delete(ts.runCancels, runID)
+	ts.runMutex.Unlock()
+	if exists {
+		cancel()
+	}
+}
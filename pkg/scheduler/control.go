@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"task_scheduler/pkg/types"
+	"task_scheduler/pkg/wal"
+)
+
+// PauseTask 暂停任务：移除cron条目，已派发的运行继续执行，仍可手动触发
+func (ts *TaskScheduler) PauseTask(taskID string) error {
+	ts.taskMutex.Lock()
+	defer ts.unlockTasks()
+
+	task, exists := ts.tasks[taskID]
+	if !exists {
+		return fmt.Errorf("task %s: %w", taskID, types.ErrTaskNotFound)
+	}
+	if task.Status == types.TaskStatusPaused {
+		return nil
+	}
+
+	ts.removeCronEntry(taskID)
+	task.Status = types.TaskStatusPaused
+	task.NextRunTime = time.Time{}
+	ts.persistTaskLocked(task)
+	ts.publishTaskEvent(types.EventTaskPaused, task)
+
+	ts.taskLogger(task).Info("Task paused")
+	return nil
+}
+
+// ResumeTask 恢复暂停的任务
+func (ts *TaskScheduler) ResumeTask(taskID string) error {
+	ts.taskMutex.Lock()
+	defer ts.unlockTasks()
+
+	task, exists := ts.tasks[taskID]
+	if !exists {
+		return fmt.Errorf("task %s: %w", taskID, types.ErrTaskNotFound)
+	}
+	if task.Status != types.TaskStatusPaused {
+		return nil
+	}
+
+	// 任务仍在调度中，只恢复cron条目；并发名额保持不变，暂停前派发的运行继续占用
+	task.Status = types.TaskStatusPending
+	if err := ts.addCronEntry(task); err != nil {
+		task.Status = types.TaskStatusPaused
+		return err
+	}
+	ts.refreshNextRunTime(task, time.Now())
+	ts.persistTaskLocked(task)
+	ts.publishTaskEvent(types.EventTaskResumed, task)
+
+	ts.taskLogger(task).Info("Task resumed")
+	return nil
+}
+
+// TriggerTask 立即手动触发一次任务，返回已派发的运行
+// 任务达到并发上限时返回 types.ErrTaskBusy
+func (ts *TaskScheduler) TriggerTask(taskID string) (*types.Run, error) {
+	ts.taskMutex.RLock()
+	task, exists := ts.tasks[taskID]
+	ts.taskMutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("task %s: %w", taskID, types.ErrTaskNotFound)
+	}
+
+	run := newRun(task, time.Now(), types.RunTriggerManual)
+	if done := ts.fireTask(task, run); done == nil {
+		return nil, fmt.Errorf("task %s: %w", taskID, types.ErrTaskBusy)
+	}
+	// 派发时运行记录已写入存储，从存储读取副本避免与执行协程竞争
+	return ts.store.GetRun(run.ID)
+}
+
+// persistTaskLocked 将任务定义写入预写日志和存储（调用方持有taskMutex）
+func (ts *TaskScheduler) persistTaskLocked(task *types.Task) {
+	ts.recordWALLocked(wal.Record{Type: wal.TaskUpdated, TaskID: task.ID, Task: task})
+	snapshot := *task
+	ts.saveTask(&snapshot)
+}
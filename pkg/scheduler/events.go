@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+// eventBuffer 每个订阅者的事件缓冲大小，缓冲满时丢弃事件，避免慢订阅者阻塞调度
+const eventBuffer = 256
+
+// eventSubscriber 事件订阅者
+type eventSubscriber struct {
+	filter types.EventFilter
+	ch     chan *types.Event
+	// reliable 不丢弃事件：发布时放入 queue，由转发协程按顺序写入 ch
+	reliable bool
+	queue    []*types.Event
+	wake     chan struct{}
+	done     chan struct{}
+	// dropping 正在丢弃事件，恢复投递前只记录一次日志
+	dropping bool
+}
+
+// eventBus 进程内事件总线
+type eventBus struct {
+	subscribers map[int]*eventSubscriber
+	nextID      int
+	sequence    uint64
+	logger      *slog.Logger
+	onDrop      func(event *types.Event) // 事件因订阅者缓冲满被丢弃时调用
+	mutex       sync.Mutex
+}
+
+// newEventBus 创建事件总线
+func newEventBus(logger *slog.Logger) *eventBus {
+	return &eventBus{subscribers: make(map[int]*eventSubscriber), logger: logger}
+}
+
+// subscribe 订阅满足条件的事件，返回事件通道和取消订阅函数
+// reliable 为 true 时事件不会因消费过慢被丢弃
+func (b *eventBus) subscribe(filter types.EventFilter, reliable bool) (<-chan *types.Event, func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	sub := &eventSubscriber{filter: filter, ch: make(chan *types.Event, eventBuffer), reliable: reliable}
+	if reliable {
+		sub.wake = make(chan struct{}, 1)
+		sub.done = make(chan struct{})
+		go b.forward(sub)
+	}
+	b.subscribers[id] = sub
+
+	cancel := func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		if _, exists := b.subscribers[id]; exists {
+			delete(b.subscribers, id)
+			if sub.reliable {
+				// 转发协程退出时关闭通道
+				close(sub.done)
+			} else {
+				close(sub.ch)
+			}
+		}
+	}
+	return sub.ch, cancel
+}
+
+// forward 将不丢弃事件的订阅者队列中的事件按顺序写入其通道，取消订阅后关闭通道
+func (b *eventBus) forward(sub *eventSubscriber) {
+	defer close(sub.ch)
+	for {
+		b.mutex.Lock()
+		pending := sub.queue
+		sub.queue = nil
+		b.mutex.Unlock()
+
+		for _, event := range pending {
+			select {
+			case sub.ch <- event:
+			case <-sub.done:
+				return
+			}
+		}
+		select {
+		case <-sub.wake:
+		case <-sub.done:
+			return
+		}
+	}
+}
+
+// publish 编号并投递事件，不阻塞发布方
+func (b *eventBus) publish(event *types.Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.sequence++
+	event.ID = b.sequence
+	event.Time = time.Now()
+	for _, sub := range b.subscribers {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		if sub.reliable {
+			sub.queue = append(sub.queue, event)
+			select {
+			case sub.wake <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		select {
+		case sub.ch <- event:
+			sub.dropping = false
+		default:
+			// 订阅者处理过慢，丢弃事件
+			if !sub.dropping {
+				sub.dropping = true
+				b.logger.Warn("Event subscriber is falling behind, dropping events", "event", event.Type, "buffer", eventBuffer)
+			}
+			if b.onDrop != nil {
+				b.onDrop(event)
+			}
+		}
+	}
+}
+
+// Subscribe 订阅调度器事件，返回事件通道和取消订阅函数
+// 事件通道有缓冲，消费过慢时新事件会被丢弃并计入 task_scheduler_events_dropped_total；订阅者不得修改收到的事件
+func (ts *TaskScheduler) Subscribe(filter types.EventFilter) (<-chan *types.Event, func()) {
+	return ts.events.subscribe(filter, false)
+}
+
+// SubscribeReliable 订阅调度器事件，事件不会因消费过慢被丢弃，供通知、告警等内部消费者使用
+// 未消费的事件在内存中排队，订阅者应持续读取直到取消订阅；订阅者不得修改收到的事件
+func (ts *TaskScheduler) SubscribeReliable(filter types.EventFilter) (<-chan *types.Event, func()) {
+	return ts.events.subscribe(filter, true)
+}
+
+// publishTaskEvent 发布任务事件，附带任务快照
+func (ts *TaskScheduler) publishTaskEvent(eventType types.EventType, task *types.Task) {
+	snapshot := *task
+	ts.events.publish(&types.Event{Type: eventType, TaskID: task.ID, Task: &snapshot})
+}
+
+// publishRunEvent 发布运行事件，附带运行快照
+func (ts *TaskScheduler) publishRunEvent(eventType types.EventType, run *types.Run) {
+	snapshot := *run
+	ts.events.publish(&types.Event{Type: eventType, TaskID: run.TaskID, ExecutorID: run.ExecutorID, Run: &snapshot})
+}
+
+// publishExecutorEvent 发布执行器事件
+func (ts *TaskScheduler) publishExecutorEvent(eventType types.EventType, executorID string) {
+	ts.events.publish(&types.Event{Type: eventType, ExecutorID: executorID})
+}
@@ -1,11 +1,18 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"strconv"
+	"sync"
 	"time"
 
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/runlog"
+	"task_scheduler/pkg/tracing"
 	"task_scheduler/pkg/types"
+	"task_scheduler/pkg/wal"
 )
 
 // Start 启动调度器
@@ -17,14 +24,38 @@ func (ts *TaskScheduler) Start() error {
 		return fmt.Errorf("scheduler is already running")
 	}
 
+	// 重放预写日志，补齐存储中缺失的变更并标记上次崩溃时丢失的运行
+	if err := ts.openWAL(); err != nil {
+		return fmt.Errorf("failed to open write-ahead log: %v", err)
+	}
+
+	// 从存储加载任务，恢复上次运行时动态添加的任务
+	if err := ts.loadTasks(); err != nil {
+		ts.closeWAL()
+		return err
+	}
+
+	// 恢复路由器状态，避免重启后首批任务集中到同一批执行器
+	if err := ts.loadRouterState(); err != nil {
+		ts.logger.Warn("Failed to restore router state, starting fresh", logging.Err(err))
+	}
+
 	// 启动cron调度器
 	ts.cron.Start()
 
+	// 补偿停机期间错过的触发
+	ts.catchUpMisfires(time.Now())
+
 	// 启动健康检查
 	go ts.healthCheckLoop()
 
+	// 定期保存路由器状态
+	if ts.config.RouterStatePath != "" && ts.config.RouterStateSaveInterval > 0 {
+		go ts.routerStateLoop()
+	}
+
 	ts.running = true
-	log.Println("Task scheduler started successfully")
+	ts.logger.Info("Task scheduler started", "router_seed", ts.router.Seed())
 	return nil
 }
 
@@ -52,67 +83,280 @@ func (ts *TaskScheduler) Stop() error {
 	}
 	ts.taskMutex.Unlock()
 
+	// 保存路由器状态，供下次启动恢复
+	if err := ts.saveRouterState(); err != nil {
+		ts.logger.Error("Failed to save router state", logging.Err(err))
+	}
+
+	// 压缩并关闭预写日志；仍在执行的运行保留在日志中，下次启动时标记为丢失
+	ts.closeWAL()
+
 	ts.running = false
-	log.Println("Task scheduler stopped successfully")
+	ts.logger.Info("Task scheduler stopped")
 	return nil
 }
 
-// executeTask 执行任务
+// executeTask 执行任务，先按cron条目更新下次触发时间
 func (ts *TaskScheduler) executeTask(task *types.Task) {
-	// 检查任务状态
-	if task.Status == types.TaskStatusRunning {
-		log.Printf("Task %s is already running, skipping", task.ID)
-		return
+	now := time.Now()
+	ts.taskMutex.Lock()
+	ts.refreshNextRunTime(task, now)
+	ts.taskMutex.Unlock()
+	ts.fireTask(task, newRun(task, now, types.RunTriggerCron))
+}
+
+// newRun 创建一次待派发的运行
+func newRun(task *types.Task, fireTime time.Time, trigger types.RunTrigger) *types.Run {
+	return &types.Run{
+		ID:       newRunID(),
+		TaskID:   task.ID,
+		Strategy: task.Strategy,
+		Attempt:  1,
+		Status:   types.RunStatusScheduled,
+		Trigger:  trigger,
+		FireTime: fireTime,
+	}
+}
+
+// fireTask 执行一次运行，任务达到并发上限时跳过
+// 返回的通道在运行结束后收到最终的运行记录；任务被跳过时返回nil
+func (ts *TaskScheduler) fireTask(task *types.Task, run *types.Run) <-chan *types.Run {
+	if ts.taskStatus(task) == types.TaskStatusStopped {
+		ts.runLogger(context.Background(), run).Info("Task is stopped, skipping run")
+		return nil
+	}
+
+	slot := ts.taskSlot(task)
+	select {
+	case slot <- struct{}{}:
+	default:
+		ts.runLogger(context.Background(), run).Info("Task is already running, skipping run", "max_concurrency", cap(slot))
+		return nil
+	}
+	return ts.dispatch(task, run, slot, time.Now())
+}
+
+// waitAndFire 等待任务的并发名额后执行一次任务，用于补跑和回填
+func (ts *TaskScheduler) waitAndFire(ctx context.Context, task *types.Task, run *types.Run) <-chan *types.Run {
+	if ts.taskStatus(task) == types.TaskStatusStopped {
+		ts.runLogger(ctx, run).Info("Task is stopped, skipping run")
+		return nil
+	}
+
+	queuedAt := time.Now()
+	slot := ts.taskSlot(task)
+	ts.metrics.queue(task.ID, 1)
+	defer ts.metrics.queue(task.ID, -1)
+	select {
+	case slot <- struct{}{}:
+	case <-ctx.Done():
+		return nil
+	}
+	return ts.dispatch(task, run, slot, queuedAt)
+}
+
+// taskSlot 获取任务的并发名额，已移除的任务使用临时名额
+func (ts *TaskScheduler) taskSlot(task *types.Task) chan struct{} {
+	ts.taskMutex.RLock()
+	slot, exists := ts.slots[task.ID]
+	ts.taskMutex.RUnlock()
+	if !exists {
+		slot = newTaskSlot(task)
+	}
+	return slot
+}
+
+// newTaskSlot 按任务的并发上限创建名额
+func newTaskSlot(task *types.Task) chan struct{} {
+	limit := task.MaxConcurrency
+	if limit <= 0 {
+		limit = 1
+	}
+	return make(chan struct{}, limit)
+}
+
+// dispatch 路由并异步执行一次运行，运行结束后归还并发名额
+// 派发失败时运行记录为失败，返回的通道中立即可以读到该运行
+// queuedAt 为运行开始等待并发名额的时间，用于追踪调度延迟
+func (ts *TaskScheduler) dispatch(task *types.Task, run *types.Run, slot chan struct{}, queuedAt time.Time) <-chan *types.Run {
+	// 任务状态和下次触发时间会在taskMutex下被修改，路由器、钩子、中间件和执行器拿到的是派发时的副本
+	ts.taskMutex.RLock()
+	snapshot := *task
+	ts.taskMutex.RUnlock()
+	task = &snapshot
+
+	done := make(chan *types.Run, 1)
+	ts.recordWAL(wal.Record{Type: wal.RunScheduled, TaskID: task.ID, Run: run})
+	runCtx, runSpan := ts.startRunSpan(task, run, queuedAt)
+	logger := ts.runLogger(runCtx, run)
+	ts.logRun(run, "scheduled run %s of task %s (trigger %s, attempt %d, fire time %s)",
+		run.ID, task.ID, run.Trigger, run.Attempt, run.FireTime.Format(time.RFC3339Nano))
+
+	// 派发失败：记录失败并结束跨度
+	reject := func(routeSpan *tracing.Span, reason string) <-chan *types.Run {
+		ts.failRun(task, run, reason)
+		routeSpan.SetStatus(errors.New(reason))
+		routeSpan.End()
+		endRunSpan(runSpan, run)
+		<-slot
+		done <- run
+		close(done)
+		return done
 	}
 
-	if task.Status == types.TaskStatusStopped {
-		log.Printf("Task %s is stopped, skipping", task.ID)
-		return
+	_, routeSpan := ts.tracer().Start(runCtx, spanRoute)
+	if err := ts.hooks.runBeforeRoute(task, run); err != nil {
+		logger.Warn("Run rejected by before-route hook", logging.Err(err))
+		return reject(routeSpan, err.Error())
 	}
 
 	// 获取可用执行器
 	executors := ts.executorManager.GetExecutors()
+	routeSpan.SetAttribute("route.candidates", strconv.Itoa(len(executors)))
 	if len(executors) == 0 {
-		log.Printf("No available executors for task %s", task.ID)
-		task.Status = types.TaskStatusFailed
-		return
+		logger.Warn("No available executors")
+		return reject(routeSpan, "no available executors")
 	}
 
 	// 使用路由策略选择执行器
 	executor, err := ts.router.Route(task, executors)
 	if err != nil {
-		log.Printf("Failed to route task %s: %v", task.ID, err)
-		task.Status = types.TaskStatusFailed
-		return
+		logger.Error("Failed to route run", logging.Err(err))
+		return reject(routeSpan, err.Error())
+	}
+	run.ExecutorID = executor.GetID()
+	logger = ts.runLogger(runCtx, run)
+	routeSpan.SetAttribute("executor.id", run.ExecutorID)
+	routeSpan.SetStatus(nil)
+	routeSpan.End()
+
+	_, dispatchSpan := ts.tracer().Start(runCtx, spanDispatch, tracing.WithAttributes("executor.id", run.ExecutorID))
+	ts.recordWAL(wal.Record{Type: wal.RunDispatched, TaskID: task.ID, Run: run})
+	ts.saveRun(run, true)
+	ts.metrics.observeSelection(run)
+	ts.publishRunEvent(types.EventRunScheduled, run)
+	ts.hooks.runAfterRoute(task, run, executor)
+
+	// 派发前记录在途运行，保证并发路由时能立即看到负载
+	release := ts.router.InFlight().Acquire(executor.GetID())
+
+	// 登记取消函数，运行结束后注销；执行跨度随上下文传给执行器
+	ctx := logging.WithLogger(runCtx, logger)
+	ctx = runlog.WithSink(ctx, ts.runLogs.Sink(run.ID))
+	ctx, cancel := context.WithCancel(ctx)
+	ts.trackRun(run.ID, cancel)
+	// 任务设置了超时时，超时与取消区分：超时的运行记为失败并计入统计的超时次数
+	stopTimeout := context.CancelFunc(func() {})
+	if task.Timeout > 0 {
+		ctx, stopTimeout = context.WithTimeout(ctx, task.Timeout)
 	}
 
 	// 异步执行任务
 	go func() {
+		defer close(done)
+		defer func() { <-slot }()
+		defer release()
+		defer ts.untrackRun(run.ID)
+		defer stopTimeout()
+
 		// 更新任务状态
-		task.Status = types.TaskStatusRunning
-		task.LastRunTime = time.Now()
+		run.StartTime = time.Now()
+		dispatchSpan.EndAt(run.StartTime)
+		ts.updateTaskState(task.ID, func(task *types.Task) {
+			if task.Status != types.TaskStatusPaused && task.Status != types.TaskStatusStopped {
+				task.Status = types.TaskStatusRunning
+			}
+			task.LastRunTime = run.StartTime
+		})
 
-		log.Printf("Executing task %s on executor %s (strategy: %v)",
-			task.ID, executor.GetID(), task.Strategy)
+		run.Status = types.RunStatusRunning
+		ts.saveRun(run, false)
+		ts.metrics.observeStart(run)
+		ts.publishRunEvent(types.EventRunStarted, run)
 
-		// 执行任务
-		err := executor.Execute(task)
-		if err != nil {
-			log.Printf("Task %s execution failed: %v", task.ID, err)
-			task.Status = types.TaskStatusFailed
+		logger.Info("Executing run", "fire_time", run.FireTime)
+		ts.logRun(run, "started on executor %s (strategy %s)", executor.GetID(), run.Strategy)
+
+		// 执行任务，经过已注册的中间件；执行前钩子返回错误时不再执行
+		ctx, executeSpan := ts.tracer().Start(ctx, spanExecute, tracing.WithAttributes("executor.id", executor.GetID()))
+		err := ts.hooks.runBeforeExecute(ctx, task, run)
+		if err == nil {
+			err = ts.hooks.executeFunc()(ctx, executor, task, run)
+		}
+		run.EndTime = time.Now()
+		executeSpan.SetStatus(err)
+		executeSpan.EndAt(run.EndTime)
+		cancelled := errors.Is(ctx.Err(), context.Canceled)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("run timed out after %s: %w", task.Timeout, context.DeadlineExceeded)
+		}
+		if !cancelled {
+			// 被取消的运行不反映执行器的耗时和错误率
+			ts.router.Latency().Observe(executor.GetID(), task.Handler, run.EndTime.Sub(run.StartTime), err)
+		}
+		status := types.TaskStatusCompleted
+		if cancelled {
+			logger.Info("Run was cancelled")
+			run.Status = types.RunStatusCancelled
+			run.Error = "cancelled"
+		} else if err != nil {
+			logger.Error("Run failed", logging.Err(err), "duration", run.EndTime.Sub(run.StartTime))
+			status = types.TaskStatusFailed
+			run.Status = types.RunStatusFailed
+			run.Error = err.Error()
 		} else {
-			log.Printf("Task %s executed successfully", task.ID)
-			task.Status = types.TaskStatusCompleted
+			logger.Info("Run succeeded", "duration", run.EndTime.Sub(run.StartTime))
+			run.Status = types.RunStatusSucceeded
 		}
 
-		// 计算下次运行时间（如果是周期性任务）
+		// 周期性任务重置为待执行状态，下次触发时间已在触发时更新
 		if task.Cron != "" {
-			// 这里可以添加计算下次运行时间的逻辑
-			task.Status = types.TaskStatusPending // 重置为待执行状态
+			status = types.TaskStatusPending
+		}
+
+		ts.recordWAL(wal.Record{Type: wal.RunFinished, TaskID: task.ID, Run: run})
+		ts.saveRun(run, false)
+		ts.finishRunLog(run)
+		ts.updateTaskState(task.ID, func(task *types.Task) {
+			if task.Status != types.TaskStatusPaused && task.Status != types.TaskStatusStopped {
+				task.Status = status
+			}
+		})
+		ts.metrics.observeFinish(run)
+		ts.runStats.record(run, err)
+		ts.publishRunEvent(types.EventRunFinished, run)
+		ts.hooks.runAfterExecute(ctx, task, run, err)
+		if run.Status == types.RunStatusFailed {
+			ts.hooks.runOnFailure(task, run, err)
 		}
+		endRunSpan(runSpan, run)
+		done <- run
 	}()
+	return done
 }
 
+// failRun 记录未能派发的运行
+func (ts *TaskScheduler) failRun(task *types.Task, run *types.Run, reason string) {
+	run.Status = types.RunStatusFailed
+	run.Error = reason
+	run.EndTime = time.Now()
+	ts.recordWAL(wal.Record{Type: wal.RunFinished, TaskID: task.ID, Run: run})
+	ts.saveRun(run, true)
+	ts.finishRunLog(run)
+	ts.updateTaskState(task.ID, func(task *types.Task) {
+		if task.Status != types.TaskStatusPaused && task.Status != types.TaskStatusStopped {
+			task.Status = types.TaskStatusFailed
+		}
+	})
+	ts.metrics.observeFinish(run)
+	ts.runStats.record(run, errors.New(reason))
+	ts.publishRunEvent(types.EventRunFinished, run)
+	ts.hooks.runOnFailure(task, run, errors.New(reason))
+}
+
+// maxHealthProbeTimeout 单次健康探测的最长等待时间
+const maxHealthProbeTimeout = 5 * time.Second
+
 // healthCheckLoop 健康检查循环
 func (ts *TaskScheduler) healthCheckLoop() {
 	ticker := time.NewTicker(ts.config.HealthCheckInterval)
@@ -128,17 +372,60 @@ func (ts *TaskScheduler) healthCheckLoop() {
 	}
 }
 
-// performHealthCheck 执行健康检查
+// performHealthCheck 执行健康检查，执行器健康状态变化时发布事件
+// 实现 types.HealthProber 的执行器（如 HTTPExecutor）并发探测，其余以 IsHealthy 为准；
+// 探测成功时才更新心跳时间，远程执行器失联后心跳年龄持续增长
 func (ts *TaskScheduler) performHealthCheck() {
-	executors := ts.executorManager.GetExecutors()
-	for _, exec := range executors {
-		go func(executor types.Executor) {
-			// 这里可以添加实际的健康检查逻辑
-			// 比如发送ping请求、检查响应时间等
-			// 模拟健康检查
-			// 实际应用中可以发送HTTP请求或RPC调用
-			// 这里可以通过接口方法来设置健康状态
-			// healthy := true
-		}(exec)
+	// 包括不健康的执行器，才能发现恢复
+	executors := ts.executorManager.ListExecutors()
+
+	probeErrors := make([]error, len(executors))
+	var wg sync.WaitGroup
+	for i, exec := range executors {
+		prober, ok := exec.(types.HealthProber)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, prober types.HealthProber) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ts.ctx, ts.healthProbeTimeout())
+			defer cancel()
+			probeErrors[i] = prober.Probe(ctx)
+		}(i, prober)
+	}
+	wg.Wait()
+
+	ts.healthMutex.Lock()
+	defer ts.healthMutex.Unlock()
+	for i, exec := range executors {
+		healthy := exec.IsHealthy() && probeErrors[i] == nil
+		previous, known := ts.executorHealth[exec.GetID()]
+		ts.executorHealth[exec.GetID()] = healthy
+		if healthy {
+			ts.executorHeartbeat[exec.GetID()] = time.Now()
+		}
+		if !known || previous == healthy {
+			continue
+		}
+		if healthy {
+			ts.executorLogger(exec.GetID()).Info("Executor recovered")
+			ts.publishExecutorEvent(types.EventExecutorRecovered, exec.GetID())
+		} else {
+			logger := ts.executorLogger(exec.GetID())
+			if probeErrors[i] != nil {
+				logger = logger.With(logging.Err(probeErrors[i]))
+			}
+			logger.Warn("Executor is unhealthy")
+			ts.publishExecutorEvent(types.EventExecutorUnhealthy, exec.GetID())
+		}
+	}
+}
+
+// healthProbeTimeout 单次健康探测的超时，不超过健康检查间隔
+func (ts *TaskScheduler) healthProbeTimeout() time.Duration {
+	if interval := ts.config.HealthCheckInterval; interval > 0 && interval < maxHealthProbeTimeout {
+		return interval
 	}
+	return maxHealthProbeTimeout
 }
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+// GetFairnessStats 按路由策略和滚动窗口计算各执行器间的负载均衡程度
+// 参与比较的执行器为当前可用的执行器以及窗口内执行过该策略运行的执行器
+func (ts *TaskScheduler) GetFairnessStats() []*types.StrategyFairness {
+	available := ts.executorManager.GetExecutors()
+	now := time.Now()
+
+	var report []*types.StrategyFairness
+	for _, window := range ts.statsWindows() {
+		runs := make(map[types.RouteStrategy]map[string]int)
+		busy := make(map[types.RouteStrategy]map[string]time.Duration)
+		for _, sample := range ts.runStats.since(now.Add(-window)) {
+			if sample.executorID == "" {
+				continue
+			}
+			if runs[sample.strategy] == nil {
+				runs[sample.strategy] = make(map[string]int)
+				busy[sample.strategy] = make(map[string]time.Duration)
+			}
+			runs[sample.strategy][sample.executorID]++
+			busy[sample.strategy][sample.executorID] += sample.duration
+		}
+
+		strategies := make([]types.RouteStrategy, 0, len(runs))
+		for strategy := range runs {
+			strategies = append(strategies, strategy)
+		}
+		sort.Slice(strategies, func(i, j int) bool { return strategies[i] < strategies[j] })
+
+		for _, strategy := range strategies {
+			byExecutor := runs[strategy]
+			for _, exec := range available {
+				if _, exists := byExecutor[exec.GetID()]; !exists {
+					byExecutor[exec.GetID()] = 0
+				}
+			}
+
+			counts := make([]float64, 0, len(byExecutor))
+			busyTimes := make([]float64, 0, len(byExecutor))
+			total := 0
+			for executorID, count := range byExecutor {
+				counts = append(counts, float64(count))
+				busyTimes = append(busyTimes, busy[strategy][executorID].Seconds())
+				total += count
+			}
+			report = append(report, &types.StrategyFairness{
+				Strategy:       strategy,
+				Window:         window,
+				Runs:           total,
+				RunsByExecutor: byExecutor,
+				RunCount:       fairness(counts),
+				BusyTime:       fairness(busyTimes),
+			})
+		}
+	}
+	return report
+}
+
+// fairness 计算一组非负负载值的离散程度
+func fairness(values []float64) types.FairnessMetrics {
+	var metrics types.FairnessMetrics
+	if len(values) == 0 {
+		return metrics
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := float64(len(sorted))
+	metrics.Min = sorted[0]
+	metrics.Max = sorted[len(sorted)-1]
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	metrics.Mean = sum / n
+	if metrics.Min > 0 {
+		ratio := metrics.Max / metrics.Min
+		metrics.MaxMinRatio = &ratio
+	}
+	if metrics.Mean == 0 {
+		return metrics
+	}
+
+	var variance float64
+	for _, v := range sorted {
+		variance += (v - metrics.Mean) * (v - metrics.Mean)
+	}
+	metrics.CoefficientOfVariation = math.Sqrt(variance/n) / metrics.Mean
+
+	// 基尼系数的排序公式：G = Σ(2i-n-1)·x_i / (n·Σx)，i从1开始
+	var weighted float64
+	for i, v := range sorted {
+		weighted += (2*float64(i+1) - n - 1) * v
+	}
+	metrics.Gini = weighted / (n * sum)
+	return metrics
+}
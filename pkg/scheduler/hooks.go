@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+
+	"task_scheduler/pkg/types"
+)
+
+// BeforeRouteHook 路由前调用，返回错误时运行不再派发并记录为失败
+type BeforeRouteHook func(task *types.Task, run *types.Run) error
+
+// AfterRouteHook 选定执行器后、开始执行前调用
+type AfterRouteHook func(task *types.Task, run *types.Run, executor types.Executor)
+
+// BeforeExecuteHook 执行前调用，返回错误时跳过执行并记录为失败
+type BeforeExecuteHook func(ctx context.Context, task *types.Task, run *types.Run) error
+
+// AfterExecuteHook 运行结束后调用，run 中为最终状态，err 为执行返回的错误
+type AfterExecuteHook func(ctx context.Context, task *types.Task, run *types.Run, err error)
+
+// FailureHook 运行失败时调用，包括派发失败和执行失败，取消的运行不算失败
+type FailureHook func(task *types.Task, run *types.Run, err error)
+
+// ExecuteFunc 在执行器上执行一次运行
+type ExecuteFunc func(ctx context.Context, executor types.Executor, task *types.Task, run *types.Run) error
+
+// Middleware 包装执行过程，可以在调用 next 前后加入日志、指标、鉴权等逻辑，
+// 也可以传给 next 修改过的任务副本（如渲染参数模板）
+type Middleware func(next ExecuteFunc) ExecuteFunc
+
+// hooks 注册的生命周期钩子和执行中间件
+// 钩子在调度协程中同步调用，应尽快返回；不得保留或修改传入的运行记录
+// 调用钩子时不持有锁，钩子中可以继续注册钩子
+type hooks struct {
+	beforeRoute   []BeforeRouteHook
+	afterRoute    []AfterRouteHook
+	beforeExecute []BeforeExecuteHook
+	afterExecute  []AfterExecuteHook
+	onFailure     []FailureHook
+	middleware    []Middleware
+	mutex         sync.RWMutex
+}
+
+// OnBeforeRoute 注册路由前钩子
+func (ts *TaskScheduler) OnBeforeRoute(hook BeforeRouteHook) {
+	ts.hooks.mutex.Lock()
+	defer ts.hooks.mutex.Unlock()
+	ts.hooks.beforeRoute = append(ts.hooks.beforeRoute, hook)
+}
+
+// OnAfterRoute 注册路由后钩子
+func (ts *TaskScheduler) OnAfterRoute(hook AfterRouteHook) {
+	ts.hooks.mutex.Lock()
+	defer ts.hooks.mutex.Unlock()
+	ts.hooks.afterRoute = append(ts.hooks.afterRoute, hook)
+}
+
+// OnBeforeExecute 注册执行前钩子
+func (ts *TaskScheduler) OnBeforeExecute(hook BeforeExecuteHook) {
+	ts.hooks.mutex.Lock()
+	defer ts.hooks.mutex.Unlock()
+	ts.hooks.beforeExecute = append(ts.hooks.beforeExecute, hook)
+}
+
+// OnAfterExecute 注册执行后钩子
+func (ts *TaskScheduler) OnAfterExecute(hook AfterExecuteHook) {
+	ts.hooks.mutex.Lock()
+	defer ts.hooks.mutex.Unlock()
+	ts.hooks.afterExecute = append(ts.hooks.afterExecute, hook)
+}
+
+// OnFailure 注册失败钩子
+func (ts *TaskScheduler) OnFailure(hook FailureHook) {
+	ts.hooks.mutex.Lock()
+	defer ts.hooks.mutex.Unlock()
+	ts.hooks.onFailure = append(ts.hooks.onFailure, hook)
+}
+
+// Use 注册执行中间件，先注册的在最外层
+func (ts *TaskScheduler) Use(middleware ...Middleware) {
+	ts.hooks.mutex.Lock()
+	defer ts.hooks.mutex.Unlock()
+	ts.hooks.middleware = append(ts.hooks.middleware, middleware...)
+}
+
+// runBeforeRoute 依次调用路由前钩子，遇到错误即停止
+func (h *hooks) runBeforeRoute(task *types.Task, run *types.Run) error {
+	h.mutex.RLock()
+	registered := h.beforeRoute
+	h.mutex.RUnlock()
+	for _, hook := range registered {
+		if err := hook(task, run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runAfterRoute 调用路由后钩子
+func (h *hooks) runAfterRoute(task *types.Task, run *types.Run, executor types.Executor) {
+	h.mutex.RLock()
+	registered := h.afterRoute
+	h.mutex.RUnlock()
+	for _, hook := range registered {
+		hook(task, run, executor)
+	}
+}
+
+// runBeforeExecute 依次调用执行前钩子，遇到错误即停止
+func (h *hooks) runBeforeExecute(ctx context.Context, task *types.Task, run *types.Run) error {
+	h.mutex.RLock()
+	registered := h.beforeExecute
+	h.mutex.RUnlock()
+	for _, hook := range registered {
+		if err := hook(ctx, task, run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runAfterExecute 调用执行后钩子
+func (h *hooks) runAfterExecute(ctx context.Context, task *types.Task, run *types.Run, err error) {
+	h.mutex.RLock()
+	registered := h.afterExecute
+	h.mutex.RUnlock()
+	for _, hook := range registered {
+		hook(ctx, task, run, err)
+	}
+}
+
+// runOnFailure 调用失败钩子
+func (h *hooks) runOnFailure(task *types.Task, run *types.Run, err error) {
+	h.mutex.RLock()
+	registered := h.onFailure
+	h.mutex.RUnlock()
+	for _, hook := range registered {
+		hook(task, run, err)
+	}
+}
+
+// executeFunc 用已注册的中间件包装基础执行函数
+func (h *hooks) executeFunc() ExecuteFunc {
+	h.mutex.RLock()
+	registered := h.middleware
+	h.mutex.RUnlock()
+	execute := executeRun
+	for i := len(registered) - 1; i >= 0; i-- {
+		execute = registered[i](execute)
+	}
+	return execute
+}
+
+// executeRun 基础执行函数：支持运行信息的执行器可以获得逻辑触发时间并响应取消
+func executeRun(ctx context.Context, executor types.Executor, task *types.Task, run *types.Run) error {
+	if runExecutor, ok := executor.(types.RunExecutor); ok {
+		return runExecutor.ExecuteRun(ctx, task, run)
+	}
+	return executor.Execute(task)
+}
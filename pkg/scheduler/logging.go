@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/tracing"
+	"task_scheduler/pkg/types"
+)
+
+// taskLogger 任务相关的日志记录器
+func (ts *TaskScheduler) taskLogger(task *types.Task) *slog.Logger {
+	return ts.logger.With(
+		slog.String(logging.KeyTaskID, task.ID),
+		slog.String(logging.KeyStrategy, task.Strategy.String()),
+	)
+}
+
+// runLogger 运行相关的日志记录器，携带任务、运行、执行器、策略和尝试次数
+// 执行器在路由后才确定，路由前为空；配置了追踪时附带追踪ID
+func (ts *TaskScheduler) runLogger(ctx context.Context, run *types.Run) *slog.Logger {
+	logger := ts.logger.With(
+		slog.String(logging.KeyTaskID, run.TaskID),
+		slog.String(logging.KeyRunID, run.ID),
+		slog.String(logging.KeyExecutorID, run.ExecutorID),
+		slog.String(logging.KeyStrategy, run.Strategy.String()),
+		slog.Int(logging.KeyAttempt, run.Attempt),
+		slog.String(logging.KeyTrigger, string(run.Trigger)),
+	)
+	if sc := tracing.SpanFromContext(ctx).SpanContext(); sc.IsValid() {
+		logger = logger.With(slog.String(logging.KeyTraceID, sc.TraceID.String()))
+	}
+	return logger
+}
+
+// executorLogger 执行器相关的日志记录器
+func (ts *TaskScheduler) executorLogger(executorID string) *slog.Logger {
+	return ts.logger.With(slog.String(logging.KeyExecutorID, executorID))
+}
@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"task_scheduler/pkg/metrics"
+	"task_scheduler/pkg/types"
+)
+
+// schedulerMetrics 调度器的 Prometheus 指标
+type schedulerMetrics struct {
+	registry   *metrics.Registry
+	runs       *metrics.CounterVec
+	duration   *metrics.HistogramVec
+	lag        *metrics.HistogramVec
+	selections *metrics.CounterVec
+	dropped    *metrics.CounterVec
+	queued     map[string]int // taskID -> 等待并发名额的运行数
+	mutex      sync.Mutex
+}
+
+// newSchedulerMetrics 注册调度器指标，仪表盘类指标在输出时从调度器读取
+func newSchedulerMetrics(ts *TaskScheduler) *schedulerMetrics {
+	registry := metrics.NewRegistry()
+	m := &schedulerMetrics{
+		registry: registry,
+		runs: registry.NewCounterVec("task_scheduler_runs_total",
+			"Finished runs by task, final status, executor and routing strategy.",
+			"task", "status", "executor", "strategy"),
+		duration: registry.NewHistogramVec("task_scheduler_run_duration_seconds",
+			"Execution time of finished runs.", nil, "task", "status"),
+		lag: registry.NewHistogramVec("task_scheduler_schedule_lag_seconds",
+			"Delay between the logical fire time and the actual start of a run.", nil, "task", "trigger"),
+		selections: registry.NewCounterVec("task_scheduler_router_selections_total",
+			"Executors selected by the router.", "executor", "strategy"),
+		dropped: registry.NewCounterVec("task_scheduler_events_dropped_total",
+			"Events dropped because a subscriber's buffer was full.", "type"),
+		queued: make(map[string]int),
+	}
+
+	registry.NewGaugeFunc("task_scheduler_tasks", "Registered tasks by status.", []string{"status"}, func() []metrics.Sample {
+		counts := make(map[types.TaskStatus]int)
+		for _, task := range ts.GetTasks() {
+			counts[task.Status]++
+		}
+		samples := make([]metrics.Sample, 0, len(counts))
+		for status, count := range counts {
+			samples = append(samples, metrics.Sample{LabelValues: []string{status.String()}, Value: float64(count)})
+		}
+		return samples
+	})
+	registry.NewGaugeFunc("task_scheduler_queue_depth", "Runs waiting for a concurrency slot of their task.", []string{"task"}, func() []metrics.Sample {
+		tasks := ts.GetTasks()
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		samples := make([]metrics.Sample, 0, len(tasks))
+		for _, task := range tasks {
+			samples = append(samples, metrics.Sample{LabelValues: []string{task.ID}, Value: float64(m.queued[task.ID])})
+		}
+		return samples
+	})
+	registry.NewGaugeFunc("task_scheduler_runs_in_flight", "Runs currently executing on each executor.", []string{"executor"}, func() []metrics.Sample {
+		var samples []metrics.Sample
+		for _, exec := range ts.ListExecutors() {
+			count := ts.router.InFlight().Count(exec.GetID())
+			samples = append(samples, metrics.Sample{LabelValues: []string{exec.GetID()}, Value: float64(count)})
+		}
+		return samples
+	})
+	registry.NewGaugeFunc("task_scheduler_executor_healthy", "Whether the executor is healthy (1) or not (0).", []string{"executor", "draining"}, func() []metrics.Sample {
+		var samples []metrics.Sample
+		for _, exec := range ts.ListExecutors() {
+			value := 0.0
+			if exec.IsHealthy() {
+				value = 1
+			}
+			draining := strconv.FormatBool(ts.IsExecutorDraining(exec.GetID()))
+			samples = append(samples, metrics.Sample{LabelValues: []string{exec.GetID(), draining}, Value: value})
+		}
+		return samples
+	})
+	registry.NewGaugeFunc("task_scheduler_executor_heartbeat_age_seconds", "Seconds since the executor last passed a health check (an HTTP probe of /healthz for remote executors).", []string{"executor"}, func() []metrics.Sample {
+		ts.healthMutex.Lock()
+		defer ts.healthMutex.Unlock()
+		now := time.Now()
+		samples := make([]metrics.Sample, 0, len(ts.executorHeartbeat))
+		for executorID, seen := range ts.executorHeartbeat {
+			samples = append(samples, metrics.Sample{LabelValues: []string{executorID}, Value: now.Sub(seen).Seconds()})
+		}
+		return samples
+	})
+	return m
+}
+
+// Metrics 返回调度器的指标注册表，应用可以在其中注册自己的指标
+func (ts *TaskScheduler) Metrics() *metrics.Registry {
+	return ts.metrics.registry
+}
+
+// observeSelection 记录路由选择
+func (m *schedulerMetrics) observeSelection(run *types.Run) {
+	m.selections.Inc(run.ExecutorID, run.Strategy.String())
+}
+
+// observeDroppedEvent 记录被丢弃的事件
+func (m *schedulerMetrics) observeDroppedEvent(event *types.Event) {
+	m.dropped.Inc(string(event.Type))
+}
+
+// observeStart 记录调度延迟
+func (m *schedulerMetrics) observeStart(run *types.Run) {
+	m.lag.Observe(run.StartTime.Sub(run.FireTime).Seconds(), run.TaskID, string(run.Trigger))
+}
+
+// observeFinish 记录结束的运行，未开始执行的运行不计入耗时
+func (m *schedulerMetrics) observeFinish(run *types.Run) {
+	m.runs.Inc(run.TaskID, run.Status.String(), run.ExecutorID, run.Strategy.String())
+	if !run.StartTime.IsZero() {
+		m.duration.Observe(run.EndTime.Sub(run.StartTime).Seconds(), run.TaskID, run.Status.String())
+	}
+}
+
+// removeTask 删除已移除任务的指标序列，避免一次性任务使序列无限增长
+func (m *schedulerMetrics) removeTask(taskID string) {
+	m.runs.DeletePartialMatch("task", taskID)
+	m.duration.DeletePartialMatch("task", taskID)
+	m.lag.DeletePartialMatch("task", taskID)
+}
+
+// removeExecutor 删除已移除执行器的指标序列
+func (m *schedulerMetrics) removeExecutor(executorID string) {
+	m.runs.DeletePartialMatch("executor", executorID)
+	m.selections.DeletePartialMatch("executor", executorID)
+}
+
+// queue 调整任务等待并发名额的运行数
+func (m *schedulerMetrics) queue(taskID string, delta int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.queued[taskID] += delta
+	if m.queued[taskID] <= 0 {
+		delete(m.queued, taskID)
+	}
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/types"
+)
+
+// cronParser 与 cron.WithSeconds() 一致的表达式解析器
+var cronParser = cron.NewParser(
+	cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// NextFireTimes 计算cron表达式在from之后的n次触发时间
+func NextFireTimes(expr string, from time.Time, n int) ([]time.Time, error) {
+	schedule, err := cronParser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+
+	fireTimes := make([]time.Time, 0, n)
+	for next := schedule.Next(from); !next.IsZero() && len(fireTimes) < n; next = schedule.Next(next) {
+		fireTimes = append(fireTimes, next)
+	}
+	return fireTimes, nil
+}
+
+// catchUpMisfires 按任务的补偿策略补跑停机期间错过的触发
+// 错过的触发从存储中最后一次触发时间（从未触发过时为创建时间）算起，补跑的运行以错过的触发时间作为触发时间，
+// 因此再次重启时不会重复补跑
+func (ts *TaskScheduler) catchUpMisfires(now time.Time) {
+	ts.taskMutex.RLock()
+	tasks := make([]*types.Task, 0, len(ts.tasks))
+	for _, task := range ts.tasks {
+		if task.Cron != "" && task.MisfirePolicy != types.MisfireIgnore && task.Status != types.TaskStatusPaused {
+			tasks = append(tasks, task)
+		}
+	}
+	ts.taskMutex.RUnlock()
+
+	for _, task := range tasks {
+		missed, err := ts.missedFireTimes(task, now)
+		if err != nil {
+			ts.taskLogger(task).Error("Failed to compute misfires", logging.Err(err))
+			continue
+		}
+		if len(missed) == 0 {
+			continue
+		}
+
+		ts.taskLogger(task).Info("Catching up missed fires",
+			"missed", len(missed), "since", missed[0], "policy", task.MisfirePolicy.String())
+		go ts.fireMissed(task, missed)
+	}
+}
+
+// missedFireTimes 计算任务需要补跑的触发时间（按时间先后）
+func (ts *TaskScheduler) missedFireTimes(task *types.Task, now time.Time) ([]time.Time, error) {
+	last, err := ts.lastFireTime(task)
+	if err != nil {
+		return nil, err
+	}
+	if last.IsZero() {
+		// 从未触发过的任务从创建时间算起，创建前的触发不补跑
+		ts.taskMutex.RLock()
+		last = task.CreatedAt
+		ts.taskMutex.RUnlock()
+	}
+	if last.IsZero() {
+		return nil, nil
+	}
+
+	schedule, err := cronParser.Parse(task.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", task.Cron, err)
+	}
+
+	limit := 1
+	if task.MisfirePolicy == types.MisfireFireAll {
+		limit = task.MaxMisfires
+		if limit <= 0 {
+			limit = types.DefaultMaxMisfires
+		}
+	}
+
+	// 只保留最近的limit次触发
+	var missed []time.Time
+	dropped := 0
+	for next := schedule.Next(last); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		if len(missed) == limit {
+			missed = missed[1:]
+			dropped++
+		}
+		missed = append(missed, next)
+	}
+	if dropped > 0 && task.MisfirePolicy == types.MisfireFireAll {
+		ts.taskLogger(task).Warn("Too many missed fires, skipping the oldest", "limit", limit, "skipped", dropped)
+	}
+	return missed, nil
+}
+
+// lastFireTime 获取任务最后一次触发时间，取运行记录与任务记录中较晚者
+func (ts *TaskScheduler) lastFireTime(task *types.Task) (time.Time, error) {
+	ts.taskMutex.RLock()
+	last := task.LastRunTime
+	ts.taskMutex.RUnlock()
+
+	page, err := ts.store.QueryRuns(types.RunQuery{TaskID: task.ID, Limit: 1})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query last run: %v", err)
+	}
+	if len(page.Runs) > 0 && page.Runs[0].FireTime.After(last) {
+		last = page.Runs[0].FireTime
+	}
+	return last, nil
+}
+
+// fireMissed 依次补跑错过的触发，每次等待上一次运行结束
+func (ts *TaskScheduler) fireMissed(task *types.Task, fireTimes []time.Time) {
+	for _, fireTime := range fireTimes {
+		select {
+		case <-ts.ctx.Done():
+			return
+		default:
+		}
+
+		done := ts.waitAndFire(ts.ctx, task, newRun(task, fireTime, types.RunTriggerMisfire))
+		if done == nil {
+			ts.taskLogger(task).Warn("Catch-up fire was not dispatched", "fire_time", fireTime)
+			continue
+		}
+		select {
+		case <-done:
+		case <-ts.ctx.Done():
+			return
+		}
+	}
+}
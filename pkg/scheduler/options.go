@@ -0,0 +1,23 @@
+package scheduler
+
+import (
+	"task_scheduler/pkg/runlog"
+	"task_scheduler/pkg/tracing"
+)
+
+// Option 调度器配置项，用于配置 types.SchedulerConfig 之外的实现依赖
+type Option func(ts *TaskScheduler)
+
+// WithTracer 设置运行追踪器，未设置时不生成跨度
+func WithTracer(tracer *tracing.Tracer) Option {
+	return func(ts *TaskScheduler) {
+		ts.tracing = tracer
+	}
+}
+
+// WithRunLogs 设置运行日志存储，未设置时使用默认大小上限和保留策略的内存存储
+func WithRunLogs(store *runlog.Store) Option {
+	return func(ts *TaskScheduler) {
+		ts.runLogs = store
+	}
+}
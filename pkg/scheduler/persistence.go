@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/router"
+)
+
+// loadRouterState 从快照文件恢复路由器状态，文件不存在时视为首次启动
+func (ts *TaskScheduler) loadRouterState() error {
+	path := ts.config.RouterStatePath
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read router state %s: %v", path, err)
+	}
+
+	var state router.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to decode router state %s: %v", path, err)
+	}
+	if err := ts.router.Restore(&state); err != nil {
+		return err
+	}
+
+	ts.logger.Info("Router state restored", "path", path, "saved_at", state.SavedAt)
+	return nil
+}
+
+// saveRouterState 将路由器状态写入快照文件
+// 先写临时文件再重命名，避免进程中途退出留下损坏的快照
+func (ts *TaskScheduler) saveRouterState() error {
+	path := ts.config.RouterStatePath
+	if path == "" {
+		return nil
+	}
+
+	state, err := ts.router.Snapshot()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode router state: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create router state directory: %v", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write router state %s: %v", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace router state %s: %v", path, err)
+	}
+	return nil
+}
+
+// routerStateLoop 定期保存路由器状态
+func (ts *TaskScheduler) routerStateLoop() {
+	ticker := time.NewTicker(ts.config.RouterStateSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ts.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ts.saveRouterState(); err != nil {
+				ts.logger.Error("Failed to save router state", logging.Err(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+const (
+	// MaxPreviewFireTimes 单次预览最多计算的触发次数
+	MaxPreviewFireTimes = 1000
+	// DefaultTimelineLimit 时间线默认最多返回的触发数
+	DefaultTimelineLimit = 1000
+)
+
+// refreshNextRunTime 按任务的cron条目计算from之后的下次触发时间（调用方持有taskMutex）
+// 暂停、没有cron表达式或未注册的任务为零值
+func (ts *TaskScheduler) refreshNextRunTime(task *types.Task, from time.Time) {
+	task.NextRunTime = time.Time{}
+	entryID, exists := ts.cronEntries[task.ID]
+	if !exists {
+		return
+	}
+	if entry := ts.cron.Entry(entryID); entry.Valid() {
+		task.NextRunTime = entry.Schedule.Next(from)
+	}
+}
+
+// PreviewTask 计算任务按cron计划在from之后的n次触发时间，不考虑暂停状态
+// 没有cron表达式的任务返回空列表
+func (ts *TaskScheduler) PreviewTask(taskID string, from time.Time, n int) ([]time.Time, error) {
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Cron == "" {
+		return []time.Time{}, nil
+	}
+	return NextFireTimes(task.Cron, from, clampPreview(n))
+}
+
+// clampPreview 把预览次数限制在 [0, MaxPreviewFireTimes]
+func clampPreview(n int) int {
+	if n < 0 {
+		return 0
+	}
+	if n > MaxPreviewFireTimes {
+		return MaxPreviewFireTimes
+	}
+	return n
+}
+
+// FireTime 时间线上一个任务的一次计划触发
+type FireTime struct {
+	TaskID string    `json:"task_id"`
+	Time   time.Time `json:"time"`
+}
+
+// TimelineOptions 时间线配置
+type TimelineOptions struct {
+	// TaskIDs 只包含这些任务，为空时包含所有有cron表达式的任务
+	TaskIDs []string
+	// IncludePaused 是否包含暂停的任务（按恢复后的计划计算）
+	IncludePaused bool
+	// Limit 最多返回的触发数，0使用 DefaultTimelineLimit
+	Limit int
+}
+
+// Timeline 多个任务在一个时间段内合并后的计划触发
+type Timeline struct {
+	From  time.Time  `json:"from"`
+	To    time.Time  `json:"to"`
+	Fires []FireTime `json:"fires"`
+	// Truncated 时间段内的触发数超过 Limit，只返回了最早的 Limit 个
+	Truncated bool `json:"truncated"`
+}
+
+// Timeline 计算各任务在 [from, to) 内的计划触发，按时间先后合并；同一时刻按任务ID排序
+// 用于上线前检查任务的触发是否集中在同一时刻
+func (ts *TaskScheduler) Timeline(from, to time.Time, opts TimelineOptions) (*Timeline, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("timeline window [%s, %s) is empty", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultTimelineLimit
+	}
+
+	var tasks []*types.Task
+	if len(opts.TaskIDs) == 0 {
+		tasks = ts.GetTasks()
+	} else {
+		for _, taskID := range opts.TaskIDs {
+			task, err := ts.GetTask(taskID)
+			if err != nil {
+				return nil, err
+			}
+			tasks = append(tasks, task)
+		}
+	}
+
+	timeline := &Timeline{From: from, To: to, Fires: []FireTime{}}
+	for _, task := range tasks {
+		if task.Cron == "" || (task.Status == types.TaskStatusPaused && !opts.IncludePaused) {
+			continue
+		}
+		schedule, err := cronParser.Parse(task.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q for task %s: %v", task.Cron, task.ID, err)
+		}
+		// 窗口下界包含在内，从下界前一刻开始计算
+		count := 0
+		for next := schedule.Next(from.Add(-time.Nanosecond)); !next.IsZero() && next.Before(to); next = schedule.Next(next) {
+			if count == limit {
+				timeline.Truncated = true
+				break
+			}
+			timeline.Fires = append(timeline.Fires, FireTime{TaskID: task.ID, Time: next})
+			count++
+		}
+	}
+
+	sort.Slice(timeline.Fires, func(i, j int) bool {
+		a, b := timeline.Fires[i], timeline.Fires[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		return a.TaskID < b.TaskID
+	})
+	if len(timeline.Fires) > limit {
+		timeline.Fires = timeline.Fires[:limit]
+		timeline.Truncated = true
+	}
+	return timeline, nil
+}
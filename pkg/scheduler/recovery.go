@@ -0,0 +1,150 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/types"
+	"task_scheduler/pkg/wal"
+)
+
+// lostRunReason 丢失运行的说明
+const lostRunReason = "scheduler exited before the run finished; outcome unknown"
+
+// openWAL 打开预写日志并据此恢复状态
+// 日志中的任务同步到任务存储，已调度/已派发但没有结束记录的运行标记为丢失
+func (ts *TaskScheduler) openWAL() error {
+	if ts.config.WALPath == "" {
+		return nil
+	}
+
+	var opts []wal.Option
+	if ts.config.WALSyncInterval > 0 {
+		opts = append(opts, wal.WithSyncInterval(ts.config.WALSyncInterval))
+	}
+	l, err := wal.Open(ts.config.WALPath, opts...)
+	if err != nil {
+		return err
+	}
+	state, err := l.State()
+	if err != nil {
+		l.Close()
+		return fmt.Errorf("failed to replay write-ahead log: %v", err)
+	}
+	ts.wal.Store(l)
+
+	ts.recoverTasks(state)
+	ts.recoverRuns(state)
+
+	// 启动前通过AddTask添加的任务同样写入日志
+	var waits []func()
+	ts.taskMutex.RLock()
+	for _, task := range ts.tasks {
+		waits = append(waits, ts.appendWAL(wal.Record{Type: wal.TaskAdded, TaskID: task.ID, Task: task}))
+	}
+	ts.taskMutex.RUnlock()
+	for _, wait := range waits {
+		wait()
+	}
+	return nil
+}
+
+// recoverTasks 将日志中的任务变更同步到任务存储
+func (ts *TaskScheduler) recoverTasks(state *wal.State) {
+	for taskID := range state.Removed {
+		if err := ts.store.DeleteTask(taskID); err != nil && !errors.Is(err, types.ErrTaskNotFound) {
+			ts.logger.Error("Failed to apply logged task removal", logging.KeyTaskID, taskID, logging.Err(err))
+		}
+	}
+
+	for _, task := range state.Tasks {
+		err := ts.store.CreateTask(task)
+		if errors.Is(err, types.ErrTaskExists) {
+			err = ts.store.UpdateTask(task)
+		}
+		if err != nil {
+			ts.taskLogger(task).Error("Failed to restore logged task", logging.Err(err))
+		}
+	}
+}
+
+// recoverRuns 将日志中未结束的运行标记为丢失，供人工核对
+// 每个丢失的运行发布一次 run_finished 事件，启动前订阅的通知和告警据此报告
+func (ts *TaskScheduler) recoverRuns(state *wal.State) {
+	orphaned := state.InFlightRuns()
+	if len(orphaned) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, run := range orphaned {
+		logger := ts.runLogger(context.Background(), run)
+		logger.Warn("Run was in flight when the scheduler stopped, marking as lost")
+
+		run.Status = types.RunStatusLost
+		run.EndTime = now
+		run.Error = lostRunReason
+
+		err := ts.store.UpdateRun(run)
+		if errors.Is(err, types.ErrRunNotFound) {
+			err = ts.store.CreateRun(run)
+		}
+		if err != nil {
+			logger.Error("Failed to record lost run", logging.Err(err))
+		}
+		ts.recordWAL(wal.Record{Type: wal.RunFinished, TaskID: run.TaskID, Run: run})
+		ts.publishRunEvent(types.EventRunFinished, run)
+	}
+}
+
+// closeWAL 压缩并关闭预写日志
+func (ts *TaskScheduler) closeWAL() {
+	l := ts.wal.Swap(nil)
+	if l == nil {
+		return
+	}
+	if err := l.Compact(); err != nil {
+		ts.logger.Error("Failed to compact write-ahead log", logging.Err(err))
+	}
+	if err := l.Close(); err != nil {
+		ts.logger.Error("Failed to close write-ahead log", logging.Err(err))
+	}
+}
+
+// recordWAL 记录状态变更并等待落盘，未配置预写日志时为空操作
+func (ts *TaskScheduler) recordWAL(record wal.Record) {
+	ts.appendWAL(record)()
+}
+
+// recordWALLocked 在持有taskMutex写锁时记录状态变更，由 unlockTasks 在解锁后等待落盘
+func (ts *TaskScheduler) recordWALLocked(record wal.Record) {
+	ts.walWaits = append(ts.walWaits, ts.appendWAL(record))
+}
+
+// unlockTasks 释放taskMutex写锁，再等待持锁期间记录的状态变更落盘，
+// 避免派发和查询在每次管理操作中等待fsync
+func (ts *TaskScheduler) unlockTasks() {
+	waits := ts.walWaits
+	ts.walWaits = nil
+	ts.taskMutex.Unlock()
+	for _, wait := range waits {
+		wait()
+	}
+}
+
+// appendWAL 将记录写入预写日志缓冲，返回等待其落盘的函数
+func (ts *TaskScheduler) appendWAL(record wal.Record) func() {
+	l := ts.wal.Load()
+	if l == nil {
+		return func() {}
+	}
+	wait := l.AppendAsync(record)
+	return func() {
+		if err := wait(); err != nil {
+			ts.logger.Error("Failed to append record to write-ahead log", "record", record.Type, logging.KeyTaskID, record.TaskID, logging.Err(err))
+		}
+	}
+}
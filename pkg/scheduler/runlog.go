@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"task_scheduler/pkg/runlog"
+	"task_scheduler/pkg/types"
+)
+
+// RunLogs 返回运行日志存储
+func (ts *TaskScheduler) RunLogs() *runlog.Store {
+	return ts.runLogs
+}
+
+// logRun 在运行日志中记录一条调度器的生命周期事件
+func (ts *TaskScheduler) logRun(run *types.Run, format string, args ...interface{}) {
+	ts.runLogs.Append(run.ID, runlog.StreamScheduler, fmt.Sprintf(format, args...))
+}
+
+// finishRunLog 记录运行的最终状态并结束运行日志
+func (ts *TaskScheduler) finishRunLog(run *types.Run) {
+	if run.Error != "" && run.Status != types.RunStatusCancelled {
+		ts.logRun(run, "%s: %s", run.Status, run.Error)
+	} else {
+		ts.logRun(run, "%s", run.Status)
+	}
+	ts.runLogs.Finish(run.ID)
+}
@@ -2,34 +2,59 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
 
 	"task_scheduler/pkg/executor"
 	"task_scheduler/pkg/router"
+	"task_scheduler/pkg/runlog"
+	"task_scheduler/pkg/store"
+	"task_scheduler/pkg/tracing"
 	"task_scheduler/pkg/types"
+	"task_scheduler/pkg/wal"
 )
 
 // TaskScheduler 任务调度器实现
 type TaskScheduler struct {
-	tasks           map[string]*types.Task
-	executorManager *executor.Manager
-	router          *router.MultiStrategyRouter
-	cron            *cron.Cron
-	config          *types.SchedulerConfig
-	running         bool
-	ctx             context.Context
-	cancel          context.CancelFunc
-	mutex           sync.RWMutex
-	taskMutex       sync.RWMutex
-}
-
-// New 创建新的任务调度器
-func New(config *types.SchedulerConfig) *TaskScheduler {
+	tasks             map[string]*types.Task
+	cronEntries       map[string]cron.EntryID       // taskID -> cron条目
+	slots             map[string]chan struct{}      // taskID -> 并发名额
+	runCancels        map[string]context.CancelFunc // runID -> 取消函数，仅包含执行中的运行
+	executorHealth    map[string]bool               // executorID -> 上次健康检查的结果
+	executorHeartbeat map[string]time.Time          // executorID -> 最近一次确认健康的时间
+	metrics           *schedulerMetrics
+	runStats          *runRecorder // 滚动窗口统计
+	walWaits          []func()     // 持有taskMutex写锁时写入预写日志、待解锁后等待落盘的记录
+	events            *eventBus
+	hooks             hooks
+	logger            *slog.Logger
+	runLogs           *runlog.Store
+	tracing           *tracing.Tracer // 运行追踪器，未配置时为nil
+	store             types.TaskStore
+	wal               atomic.Pointer[wal.Log] // 预写日志，未配置时为nil
+	executorManager   *executor.Manager
+	router            *router.MultiStrategyRouter
+	cron              *cron.Cron
+	config            *types.SchedulerConfig
+	running           bool
+	ctx               context.Context
+	cancel            context.CancelFunc
+	mutex             sync.RWMutex
+	taskMutex         sync.RWMutex
+	runMutex          sync.Mutex
+	healthMutex       sync.Mutex
+}
+
+// New 创建新的任务调度器，opts 配置追踪器等实现依赖
+func New(config *types.SchedulerConfig, opts ...Option) *TaskScheduler {
 	if config == nil {
 		config = &types.SchedulerConfig{
 			MaxConcurrentTasks:  10,
@@ -40,61 +65,244 @@ func New(config *types.SchedulerConfig) *TaskScheduler {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &TaskScheduler{
-		tasks:           make(map[string]*types.Task),
-		executorManager: executor.NewManager(),
-		router:          router.NewMultiStrategyRouter(),
-		cron:            cron.New(cron.WithSeconds()),
-		config:          config,
-		ctx:             ctx,
-		cancel:          cancel,
+	taskStore := config.Store
+	if taskStore == nil {
+		taskStore = store.NewMemoryStore()
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
 	}
+
+	multiRouter := router.NewMultiStrategyRouter(
+		router.WithTaskStateTTL(config.RouterStateTTL),
+		router.WithGlobalLFUHalfLife(config.RouterGlobalLFUHalfLife),
+		router.WithSeed(config.RouterSeed),
+		router.WithLatencyAware(config.LatencyAlpha, config.LatencyExploration,
+			config.LatencyErrorPenalty, config.LatencyMaxErrorRate),
+	)
+
+	ts := &TaskScheduler{
+		tasks:             make(map[string]*types.Task),
+		cronEntries:       make(map[string]cron.EntryID),
+		slots:             make(map[string]chan struct{}),
+		runCancels:        make(map[string]context.CancelFunc),
+		executorHealth:    make(map[string]bool),
+		executorHeartbeat: make(map[string]time.Time),
+		events:            newEventBus(logger),
+		logger:            logger,
+		store:             taskStore,
+		executorManager:   executor.NewManager(),
+		router:            multiRouter,
+		cron:              cron.New(cron.WithSeconds()),
+		config:            config,
+		ctx:               ctx,
+		cancel:            cancel,
+	}
+	for _, opt := range opts {
+		opt(ts)
+	}
+	if ts.runLogs == nil {
+		// 内存存储不会出错
+		ts.runLogs, _ = runlog.NewStore(runlog.Options{})
+	}
+	ts.metrics = newSchedulerMetrics(ts)
+	ts.events.onDrop = ts.metrics.observeDroppedEvent
+	ts.runStats = newRunRecorder(ts.statsWindows())
+	return ts
 }
 
 // AddTask 添加任务
 func (ts *TaskScheduler) AddTask(task *types.Task) error {
+	// 设置默认策略
+	if task.Strategy == 0 {
+		task.Strategy = ts.config.DefaultStrategy
+	}
+	if err := validateTask(task); err != nil {
+		return err
+	}
+
 	ts.taskMutex.Lock()
-	defer ts.taskMutex.Unlock()
+	defer ts.unlockTasks()
 
 	if _, exists := ts.tasks[task.ID]; exists {
-		return fmt.Errorf("task %s already exists", task.ID)
+		return fmt.Errorf("task %s: %w", task.ID, types.ErrTaskExists)
 	}
 
-	// 设置默认策略
+	// 设置任务状态；代码中定义的任务重启后再次添加时沿用存储中的创建时间，用于计算错过的触发
+	task.Status = types.TaskStatusPending
+	task.CreatedAt = time.Now()
+	if stored, err := ts.store.GetTask(task.ID); err == nil && !stored.CreatedAt.IsZero() {
+		task.CreatedAt = stored.CreatedAt
+	}
+
+	if err := ts.registerTask(task); err != nil {
+		return err
+	}
+
+	ts.recordWALLocked(wal.Record{Type: wal.TaskAdded, TaskID: task.ID, Task: task})
+
+	// 写入存储；代码中定义的任务在重启后再次添加时覆盖存储中的旧定义
+	err := ts.store.CreateTask(task)
+	if errors.Is(err, types.ErrTaskExists) {
+		err = ts.store.UpdateTask(task)
+	}
+	if err != nil {
+		ts.unregisterTask(task.ID)
+		return fmt.Errorf("failed to persist task %s: %v", task.ID, err)
+	}
+
+	ts.publishTaskEvent(types.EventTaskAdded, task)
+	ts.taskLogger(task).Info("Task added")
+	return nil
+}
+
+// UpdateTask 更新任务定义，保留创建时间、最后运行时间和暂停状态
+// 已派发的运行按旧定义执行完毕，之后的触发使用新定义
+func (ts *TaskScheduler) UpdateTask(task *types.Task) error {
 	if task.Strategy == 0 {
 		task.Strategy = ts.config.DefaultStrategy
 	}
+	if err := validateTask(task); err != nil {
+		return err
+	}
+
+	ts.taskMutex.Lock()
+	defer ts.unlockTasks()
+
+	old, exists := ts.tasks[task.ID]
+	if !exists {
+		return fmt.Errorf("task %s: %w", task.ID, types.ErrTaskNotFound)
+	}
 
-	// 设置任务状态
+	task.CreatedAt = old.CreatedAt
+	task.LastRunTime = old.LastRunTime
 	task.Status = types.TaskStatusPending
-	task.CreatedAt = time.Now()
+	if old.Status == types.TaskStatusPaused {
+		task.Status = types.TaskStatusPaused
+	}
+
+	// 只替换cron条目，保留并发名额，进行中的运行继续占用
+	slot := ts.slots[task.ID]
+	ts.removeCronEntry(old.ID)
+	if err := ts.registerTask(task); err != nil {
+		ts.registerTask(old)
+		return err
+	}
+
+	ts.recordWALLocked(wal.Record{Type: wal.TaskUpdated, TaskID: task.ID, Task: task})
+	if err := ts.store.UpdateTask(task); err != nil {
+		ts.removeCronEntry(task.ID)
+		ts.registerTask(old)
+		ts.slots[task.ID] = slot
+		return fmt.Errorf("failed to persist task %s: %v", task.ID, err)
+	}
+
+	if old.Strategy != task.Strategy {
+		ts.router.OnTaskRemoved(task.ID)
+	}
+	ts.publishTaskEvent(types.EventTaskUpdated, task)
+	ts.taskLogger(task).Info("Task updated")
+	return nil
+}
+
+// GetTask 获取任务（副本）
+func (ts *TaskScheduler) GetTask(taskID string) (*types.Task, error) {
+	ts.taskMutex.RLock()
+	defer ts.taskMutex.RUnlock()
+
+	task, exists := ts.tasks[taskID]
+	if !exists {
+		return nil, fmt.Errorf("task %s: %w", taskID, types.ErrTaskNotFound)
+	}
+	snapshot := *task
+	return &snapshot, nil
+}
+
+// validateTask 校验任务定义
+func validateTask(task *types.Task) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", types.ErrInvalidTask, fmt.Sprintf(format, args...))
+	}
 
-	// 添加到cron调度器
+	switch {
+	case task.ID == "":
+		return invalid("id is required")
+	case strings.ContainsAny(task.ID, "/ \t\n"):
+		return invalid("id %q must not contain slashes or whitespace", task.ID)
+	case task.Handler == "":
+		return invalid("handler is required for task %s", task.ID)
+	case !task.Strategy.Valid():
+		return invalid("unknown strategy %d for task %s", task.Strategy, task.ID)
+	case !task.MisfirePolicy.Valid():
+		return invalid("unknown misfire policy %d for task %s", task.MisfirePolicy, task.ID)
+	case task.MaxMisfires < 0 || task.MaxConcurrency < 0:
+		return invalid("max_misfires and max_concurrency must not be negative for task %s", task.ID)
+	case task.Timeout < 0:
+		return invalid("timeout must not be negative for task %s", task.ID)
+	}
 	if task.Cron != "" {
-		entryID, err := ts.cron.AddFunc(task.Cron, func() {
-			ts.executeTask(task)
-		})
-		if err != nil {
-			return fmt.Errorf("failed to add cron job for task %s: %v", task.ID, err)
+		if _, err := cronParser.Parse(task.Cron); err != nil {
+			return invalid("invalid cron expression %q for task %s: %v", task.Cron, task.ID, err)
 		}
+	}
+	return nil
+}
 
-		// 可以将entryID存储到task中，用于后续管理
-		_ = entryID
+// registerTask 将任务加入调度（调用方持有taskMutex）
+// 已有的并发名额在上限不变时沿用，进行中的运行仍占用名额
+func (ts *TaskScheduler) registerTask(task *types.Task) error {
+	if err := ts.addCronEntry(task); err != nil {
+		return err
 	}
+	ts.refreshNextRunTime(task, time.Now())
 
 	ts.tasks[task.ID] = task
-	log.Printf("Task %s added successfully with strategy %v", task.ID, task.Strategy)
+	if slot, exists := ts.slots[task.ID]; !exists || cap(slot) != cap(newTaskSlot(task)) {
+		ts.slots[task.ID] = newTaskSlot(task)
+	}
 	return nil
 }
 
+// addCronEntry 为任务添加cron条目；没有cron表达式或暂停的任务不添加（调用方持有taskMutex）
+func (ts *TaskScheduler) addCronEntry(task *types.Task) error {
+	if task.Cron == "" || task.Status == types.TaskStatusPaused {
+		return nil
+	}
+	entryID, err := ts.cron.AddFunc(task.Cron, func() {
+		ts.executeTask(task)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add cron job for task %s: %v", task.ID, err)
+	}
+	ts.cronEntries[task.ID] = entryID
+	return nil
+}
+
+// removeCronEntry 移除任务的cron条目，避免已删除或暂停的任务继续触发（调用方持有taskMutex）
+func (ts *TaskScheduler) removeCronEntry(taskID string) {
+	if entryID, exists := ts.cronEntries[taskID]; exists {
+		ts.cron.Remove(entryID)
+		delete(ts.cronEntries, taskID)
+	}
+}
+
+// unregisterTask 将任务移出调度（调用方持有taskMutex）
+func (ts *TaskScheduler) unregisterTask(taskID string) {
+	ts.removeCronEntry(taskID)
+	delete(ts.tasks, taskID)
+	delete(ts.slots, taskID)
+}
+
 // RemoveTask 移除任务
 func (ts *TaskScheduler) RemoveTask(taskID string) error {
 	ts.taskMutex.Lock()
-	defer ts.taskMutex.Unlock()
+	defer ts.unlockTasks()
 
 	task, exists := ts.tasks[taskID]
 	if !exists {
-		return fmt.Errorf("task %s not found", taskID)
+		return fmt.Errorf("task %s: %w", taskID, types.ErrTaskNotFound)
 	}
 
 	// 停止正在运行的任务
@@ -102,31 +310,88 @@ func (ts *TaskScheduler) RemoveTask(taskID string) error {
 		task.Status = types.TaskStatusStopped
 	}
 
-	delete(ts.tasks, taskID)
-	log.Printf("Task %s removed successfully", taskID)
+	ts.recordWALLocked(wal.Record{Type: wal.TaskRemoved, TaskID: taskID})
+	if err := ts.store.DeleteTask(taskID); err != nil && !errors.Is(err, types.ErrTaskNotFound) {
+		return fmt.Errorf("failed to delete task %s from store: %v", taskID, err)
+	}
+
+	ts.unregisterTask(taskID)
+	ts.router.OnTaskRemoved(taskID)
+	ts.metrics.removeTask(taskID)
+	ts.publishTaskEvent(types.EventTaskRemoved, task)
+	ts.taskLogger(task).Info("Task removed")
 	return nil
 }
 
-// GetTasks 获取所有任务
+// GetTasks 获取所有任务（副本，按ID排序）
 func (ts *TaskScheduler) GetTasks() []*types.Task {
 	ts.taskMutex.RLock()
 	defer ts.taskMutex.RUnlock()
 
 	tasks := make([]*types.Task, 0, len(ts.tasks))
 	for _, task := range ts.tasks {
-		tasks = append(tasks, task)
+		snapshot := *task
+		tasks = append(tasks, &snapshot)
 	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
 	return tasks
 }
 
 // AddExecutor 添加执行器
 func (ts *TaskScheduler) AddExecutor(executor types.Executor) error {
-	return ts.executorManager.AddExecutor(executor)
+	if err := ts.executorManager.AddExecutor(executor); err != nil {
+		return err
+	}
+	ts.healthMutex.Lock()
+	ts.executorHealth[executor.GetID()] = executor.IsHealthy()
+	if executor.IsHealthy() {
+		ts.executorHeartbeat[executor.GetID()] = time.Now()
+	}
+	ts.healthMutex.Unlock()
+	ts.publishExecutorEvent(types.EventExecutorJoined, executor.GetID())
+	return nil
 }
 
 // RemoveExecutor 移除执行器
 func (ts *TaskScheduler) RemoveExecutor(executorID string) error {
-	return ts.executorManager.RemoveExecutor(executorID)
+	if err := ts.executorManager.RemoveExecutor(executorID); err != nil {
+		return err
+	}
+	ts.router.OnExecutorRemoved(executorID)
+	ts.metrics.removeExecutor(executorID)
+	ts.healthMutex.Lock()
+	delete(ts.executorHealth, executorID)
+	delete(ts.executorHeartbeat, executorID)
+	ts.healthMutex.Unlock()
+	ts.publishExecutorEvent(types.EventExecutorRemoved, executorID)
+	return nil
+}
+
+// DrainExecutor 排空执行器：不再路由新的运行，返回仍在执行的运行数
+// 在途运行数降为0后可以安全地移除执行器
+func (ts *TaskScheduler) DrainExecutor(executorID string) (int64, error) {
+	if err := ts.executorManager.Drain(executorID); err != nil {
+		return 0, err
+	}
+	inFlight := ts.router.InFlight().Count(executorID)
+	ts.publishExecutorEvent(types.EventExecutorDraining, executorID)
+	ts.executorLogger(executorID).Info("Executor is draining", "in_flight", inFlight)
+	return inFlight, nil
+}
+
+// IsExecutorDraining 执行器是否正在排空
+func (ts *TaskScheduler) IsExecutorDraining(executorID string) bool {
+	return ts.executorManager.IsDraining(executorID)
+}
+
+// ListExecutors 获取所有执行器，包括不健康和排空中的
+func (ts *TaskScheduler) ListExecutors() []types.Executor {
+	return ts.executorManager.ListExecutors()
+}
+
+// GetStore 获取任务存储
+func (ts *TaskScheduler) GetStore() types.TaskStore {
+	return ts.store
 }
 
 // GetExecutors 获取所有执行器
@@ -0,0 +1,1156 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"task_scheduler/pkg/executor"
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/router"
+	"task_scheduler/pkg/store"
+	"task_scheduler/pkg/tracing"
+	"task_scheduler/pkg/types"
+	"task_scheduler/pkg/wal"
+)
+
+// 创建测试用的调度器配置
+func createTestConfig() *types.SchedulerConfig {
+	return &types.SchedulerConfig{
+		MaxConcurrentTasks:  10,
+		HealthCheckInterval: time.Hour,
+		DefaultStrategy:     types.RoundRobinApp,
+	}
+}
+
+// 创建带执行器的测试调度器
+func createTestScheduler(t *testing.T, config *types.SchedulerConfig) *TaskScheduler {
+	t.Helper()
+	ts := New(config)
+	for _, id := range []string{"exec-1", "exec-2", "exec-3"} {
+		if err := ts.AddExecutor(executor.NewSimpleExecutor(id, "http://localhost")); err != nil {
+			t.Fatalf("AddExecutor failed: %v", err)
+		}
+	}
+	return ts
+}
+
+func TestRouterStatePersistedAcrossRestart(t *testing.T) {
+	config := createTestConfig()
+	config.RouterStatePath = filepath.Join(t.TempDir(), "router-state.json")
+
+	first := createTestScheduler(t, config)
+	if err := first.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	task := &types.Task{ID: "lfu-task", Strategy: types.LFU}
+	var lastPicked string
+	for i := 0; i < 2; i++ {
+		exec, err := first.GetRouter().Route(task, first.GetExecutors())
+		if err != nil {
+			t.Fatalf("Route failed: %v", err)
+		}
+		lastPicked = exec.GetID()
+	}
+	if err := first.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if _, err := os.Stat(config.RouterStatePath); err != nil {
+		t.Fatalf("router state should be saved on Stop: %v", err)
+	}
+
+	second := createTestScheduler(t, config)
+	if err := second.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer second.Stop()
+
+	// 重启后LFU计数延续，不会再次从第一个执行器开始
+	exec, err := second.GetRouter().Route(task, second.GetExecutors())
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if exec.GetID() == "exec-1" || exec.GetID() == lastPicked {
+		t.Errorf("restored LFU state should route to the unused executor, got %s", exec.GetID())
+	}
+}
+
+func TestRouterGlobalLFUHalfLife(t *testing.T) {
+	saved := time.Now()
+	state := func(halfLifeAgo time.Time) *router.State {
+		data, _ := json.Marshal(map[string]interface{}{"decayed": map[string]interface{}{
+			"exec-1": map[string]interface{}{"score": 100, "updated_at": halfLifeAgo},
+			"exec-2": map[string]interface{}{"score": 5, "updated_at": saved},
+			"exec-3": map[string]interface{}{"score": 5, "updated_at": saved},
+		}})
+		return &router.State{Strategies: map[types.RouteStrategy]json.RawMessage{types.GlobalLFU: data}}
+	}
+	task := &types.Task{ID: "lfu-task", Strategy: types.GlobalLFU}
+
+	// 十个半衰期前的使用几乎衰减殆尽，久远的历史不再主导选择
+	config := createTestConfig()
+	config.RouterGlobalLFUHalfLife = time.Hour
+	ts := createTestScheduler(t, config)
+	if err := ts.GetRouter().Restore(state(saved.Add(-10 * time.Hour))); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if exec, _ := ts.GetRouter().Route(task, ts.GetExecutors()); exec.GetID() != "exec-1" {
+		t.Errorf("decayed executor should be selected, got %s", exec.GetID())
+	}
+
+	// 未配置半衰期时不衰减
+	ts = createTestScheduler(t, createTestConfig())
+	if err := ts.GetRouter().Restore(state(saved.Add(-10 * time.Hour))); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if exec, _ := ts.GetRouter().Route(task, ts.GetExecutors()); exec.GetID() == "exec-1" {
+		t.Errorf("usage should not decay without a half-life")
+	}
+}
+
+func TestTasksRestoredFromStore(t *testing.T) {
+	dir := t.TempDir()
+
+	fileStore, err := store.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	config := createTestConfig()
+	config.Store = fileStore
+	first := createTestScheduler(t, config)
+	if err := first.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	// 运行期间动态添加的任务
+	for _, id := range []string{"dynamic-1", "dynamic-2"} {
+		task := &types.Task{ID: id, Cron: "0 0 2 * * *", Handler: "h", Strategy: types.LRU}
+		if err := first.AddTask(task); err != nil {
+			t.Fatalf("AddTask failed: %v", err)
+		}
+	}
+	if err := first.RemoveTask("dynamic-1"); err != nil {
+		t.Fatalf("RemoveTask failed: %v", err)
+	}
+	first.Stop()
+	fileStore.Close()
+
+	reopened, err := store.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	defer reopened.Close()
+	config = createTestConfig()
+	config.Store = reopened
+	second := createTestScheduler(t, config)
+	if err := second.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer second.Stop()
+
+	tasks := second.GetTasks()
+	if len(tasks) != 1 || tasks[0].ID != "dynamic-2" {
+		t.Fatalf("expected dynamic-2 to be restored, got %+v", tasks)
+	}
+	if tasks[0].Strategy != types.LRU {
+		t.Errorf("restored task should keep its strategy, got %v", tasks[0].Strategy)
+	}
+}
+
+func TestRunsRecordedInStore(t *testing.T) {
+	ts := createTestScheduler(t, createTestConfig())
+	task := &types.Task{ID: "manual", Handler: "h", Strategy: types.RoundRobinApp}
+	if err := ts.AddTask(task); err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+
+	ts.executeTask(task)
+	waitFor(t, func() bool {
+		runs, _ := ts.GetStore().ListRuns("manual")
+		return len(runs) == 1 && runs[0].Status == types.RunStatusSucceeded
+	})
+
+	runs, _ := ts.GetStore().ListRuns("manual")
+	if runs[0].ExecutorID == "" || runs[0].EndTime.IsZero() {
+		t.Errorf("run should record executor and end time: %+v", runs[0])
+	}
+}
+
+func TestWALRecoversAfterCrash(t *testing.T) {
+	config := createTestConfig()
+	config.WALPath = filepath.Join(t.TempDir(), "scheduler.wal")
+
+	first := createTestScheduler(t, config)
+	if err := first.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	task := &types.Task{ID: "nightly", Cron: "0 0 2 * * *", Handler: "h", Strategy: types.RoundRobinApp}
+	if err := first.AddTask(task); err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	// 模拟派发后、执行结束前进程崩溃：只写日志，不调用Stop
+	run := &types.Run{ID: "run-1", TaskID: "nightly", ExecutorID: "exec-2", Status: types.RunStatusRunning, FireTime: time.Now()}
+	first.recordWAL(wal.Record{Type: wal.RunDispatched, TaskID: run.TaskID, Run: run})
+	first.cron.Stop()
+	first.cancel()
+
+	// 内存存储随进程丢失，任务和在途运行只能从预写日志恢复
+	second := createTestScheduler(t, config)
+	finished, cancel := second.SubscribeReliable(types.EventFilter{Types: []types.EventType{types.EventRunFinished}})
+	defer cancel()
+	if err := second.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer second.Stop()
+
+	tasks := second.GetTasks()
+	if len(tasks) != 1 || tasks[0].ID != "nightly" {
+		t.Fatalf("expected nightly to be recovered from the log, got %+v", tasks)
+	}
+	lost, err := second.GetStore().GetRun("run-1")
+	if err != nil {
+		t.Fatalf("in-flight run should be recorded: %v", err)
+	}
+	if lost.Status != types.RunStatusLost || lost.ExecutorID != "exec-2" || lost.EndTime.IsZero() {
+		t.Errorf("in-flight run should be marked lost: %+v", lost)
+	}
+	// 丢失的运行同样发布运行结束事件，供通知和告警报告
+	if event := nextEvent(t, finished); event.Run.ID != "run-1" || event.Run.Status != types.RunStatusLost {
+		t.Errorf("lost run should be published as finished, got %+v", event)
+	}
+
+	// 丢失的运行已写入结束记录，不会在下次重启时重复报告
+	state, _ := second.wal.Load().State()
+	if len(state.InFlightRuns()) != 0 {
+		t.Errorf("lost runs should be closed in the log, got %+v", state.InFlightRuns())
+	}
+}
+
+func TestWALSyncOutsideTaskLock(t *testing.T) {
+	ts := createTestScheduler(t, createTestConfig())
+	ts.AddTask(&types.Task{ID: "nightly", Cron: "0 0 2 * * *", Handler: "h"})
+	// 批量间隔很长，记录只在关闭日志时落盘
+	l, err := wal.Open(filepath.Join(t.TempDir(), "scheduler.wal"), wal.WithSyncInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	ts.wal.Store(l)
+
+	// 暂停在记录落盘后才返回，等待期间不持有taskMutex
+	paused := make(chan error, 1)
+	go func() { paused <- ts.PauseTask("nightly") }()
+	waitFor(t, func() bool {
+		task, _ := ts.GetTask("nightly")
+		return task.Status == types.TaskStatusPaused
+	})
+	select {
+	case err := <-paused:
+		t.Fatalf("PauseTask should wait for the write-ahead log sync, returned %v", err)
+	default:
+	}
+	l.Close()
+	if err := <-paused; err != nil {
+		t.Fatalf("PauseTask failed: %v", err)
+	}
+}
+
+// waitFor 等待条件成立
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met before deadline")
+}
+
+func TestMissedFireTimes(t *testing.T) {
+	ts := createTestScheduler(t, createTestConfig())
+	last := time.Date(2026, 10, 14, 2, 0, 0, 0, time.Local)
+	now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.Local)
+	ts.GetStore().CreateRun(&types.Run{ID: "run-1", TaskID: "data-sync", Status: types.RunStatusSucceeded, FireTime: last})
+
+	task := &types.Task{ID: "data-sync", Cron: "0 0 2 * * *"}
+	cases := []struct {
+		policy      types.MisfirePolicy
+		maxMisfires int
+		want        []int // 错过触发的日期
+	}{
+		{types.MisfireFireOnce, 0, []int{17}},
+		{types.MisfireFireAll, 0, []int{15, 16, 17}},
+		{types.MisfireFireAll, 2, []int{16, 17}},
+	}
+	for _, c := range cases {
+		task.MisfirePolicy, task.MaxMisfires = c.policy, c.maxMisfires
+		missed, err := ts.missedFireTimes(task, now)
+		if err != nil {
+			t.Fatalf("missedFireTimes failed: %v", err)
+		}
+		if len(missed) != len(c.want) {
+			t.Fatalf("policy %v max %d: expected %d misfires, got %v", c.policy, c.maxMisfires, len(c.want), missed)
+		}
+		for i, day := range c.want {
+			if missed[i].Day() != day || missed[i].Hour() != 2 {
+				t.Errorf("policy %v: unexpected misfire %v, want day %d 02:00", c.policy, missed[i], day)
+			}
+		}
+	}
+
+	// 没有触发记录的新任务不补跑
+	fresh := &types.Task{ID: "fresh", Cron: "0 0 2 * * *", MisfirePolicy: types.MisfireFireAll}
+	if missed, _ := ts.missedFireTimes(fresh, now); len(missed) != 0 {
+		t.Errorf("task without history should not catch up, got %v", missed)
+	}
+	// 从未触发过的任务从创建时间算起
+	fresh.CreatedAt = time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	if missed, _ := ts.missedFireTimes(fresh, now); len(missed) != 1 || missed[0].Day() != 17 {
+		t.Errorf("task created before a missed fire should catch up, got %v", missed)
+	}
+}
+
+func TestMisfireCaughtUpForNeverFiredTask(t *testing.T) {
+	config := createTestConfig()
+	config.Store = store.NewMemoryStore()
+	// 上次运行时创建的任务，第一次触发前调度器停机，没有运行历史
+	config.Store.CreateTask(&types.Task{ID: "data-sync", Cron: "0 0 * * * *", Handler: "h", CreatedAt: time.Now().Add(-2 * time.Hour)})
+
+	ts := createTestScheduler(t, config)
+	// 代码中定义的任务再次添加时沿用存储中的创建时间
+	if err := ts.AddTask(&types.Task{ID: "data-sync", Cron: "0 0 * * * *", Handler: "h", MisfirePolicy: types.MisfireFireOnce}); err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	if err := ts.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer ts.Stop()
+
+	waitFor(t, func() bool {
+		runs, _ := ts.GetStore().ListRuns("data-sync")
+		return len(runs) == 1 && runs[0].Trigger == types.RunTriggerMisfire
+	})
+}
+
+func TestNextRunTimeAndPreview(t *testing.T) {
+	ts := createTestScheduler(t, createTestConfig())
+	for _, task := range []*types.Task{
+		{ID: "every-second", Cron: "* * * * * *", Handler: "h"},
+		{ID: "hourly", Cron: "0 0 * * * *", Handler: "h"},
+		{ID: "half-past", Cron: "0 30 * * * *", Handler: "h"},
+		{ID: "manual", Handler: "h"},
+	} {
+		if err := ts.AddTask(task); err != nil {
+			t.Fatalf("AddTask failed: %v", err)
+		}
+	}
+	hourly, _ := ts.GetTask("hourly")
+	if want := time.Now().Truncate(time.Hour).Add(time.Hour); !hourly.NextRunTime.Equal(want) {
+		t.Errorf("next run time should be %v, got %v", want, hourly.NextRunTime)
+	}
+	if manual, _ := ts.GetTask("manual"); !manual.NextRunTime.IsZero() {
+		t.Errorf("task without cron should have no next run time, got %v", manual.NextRunTime)
+	}
+
+	// 每次触发后下次触发时间前移
+	if err := ts.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer ts.Stop()
+	waitFor(t, func() bool {
+		task, _ := ts.GetTask("every-second")
+		return !task.LastRunTime.IsZero() && task.NextRunTime.After(task.LastRunTime)
+	})
+	ts.PauseTask("hourly")
+	if hourly, _ = ts.GetTask("hourly"); !hourly.NextRunTime.IsZero() {
+		t.Errorf("paused task should have no next run time, got %v", hourly.NextRunTime)
+	}
+	ts.ResumeTask("hourly")
+	if hourly, _ = ts.GetTask("hourly"); hourly.NextRunTime.IsZero() {
+		t.Error("resumed task should have a next run time")
+	}
+
+	from := time.Date(2026, 10, 17, 10, 0, 0, 0, time.Local)
+	preview, err := ts.PreviewTask("half-past", from, 2)
+	if err != nil || len(preview) != 2 || preview[0].Hour() != 10 || preview[1].Hour() != 11 || preview[1].Minute() != 30 {
+		t.Errorf("unexpected preview: %v %v", preview, err)
+	}
+	if _, err := ts.PreviewTask("missing", from, 2); !errors.Is(err, types.ErrTaskNotFound) {
+		t.Errorf("unknown task should return ErrTaskNotFound, got %v", err)
+	}
+
+	// 合并时间线，窗口下界包含在内
+	ts.PauseTask("half-past")
+	timeline, err := ts.Timeline(from, from.Add(2*time.Hour), TimelineOptions{TaskIDs: []string{"hourly", "half-past"}})
+	if err != nil || len(timeline.Fires) != 2 || !timeline.Fires[0].Time.Equal(from) || timeline.Fires[1].TaskID != "hourly" {
+		t.Fatalf("paused tasks should be excluded: %+v %v", timeline, err)
+	}
+	timeline, _ = ts.Timeline(from, from.Add(2*time.Hour), TimelineOptions{TaskIDs: []string{"hourly", "half-past"}, IncludePaused: true})
+	var order []string
+	for _, fire := range timeline.Fires {
+		order = append(order, fire.TaskID)
+	}
+	if strings.Join(order, ",") != "hourly,half-past,hourly,half-past" || timeline.Truncated {
+		t.Errorf("unexpected merged timeline: %v", order)
+	}
+	timeline, _ = ts.Timeline(from, from.Add(time.Hour), TimelineOptions{Limit: 100})
+	if len(timeline.Fires) != 100 || !timeline.Truncated || !timeline.Fires[0].Time.Equal(from) {
+		t.Errorf("timeline should be truncated at the limit: %d %v", len(timeline.Fires), timeline.Truncated)
+	}
+	if _, err := ts.Timeline(from, from, TimelineOptions{}); err == nil {
+		t.Error("empty window should be rejected")
+	}
+}
+
+func TestMisfireCaughtUpOnStart(t *testing.T) {
+	config := createTestConfig()
+	config.Store = store.NewMemoryStore()
+	yesterday := time.Now().Add(-24 * time.Hour)
+	config.Store.CreateRun(&types.Run{ID: "old", TaskID: "data-sync", Status: types.RunStatusSucceeded, FireTime: yesterday.Add(-time.Hour)})
+
+	ts := createTestScheduler(t, config)
+	task := &types.Task{ID: "data-sync", Cron: "0 0 * * * *", Handler: "h", MisfirePolicy: types.MisfireFireOnce}
+	if err := ts.AddTask(task); err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	if err := ts.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer ts.Stop()
+
+	waitFor(t, func() bool {
+		runs, _ := ts.GetStore().ListRuns("data-sync")
+		return len(runs) == 2
+	})
+	page, _ := ts.GetRunHistory(types.RunQuery{TaskID: "data-sync", Limit: 1})
+	caughtUp := page.Runs[0]
+	if !caughtUp.FireTime.Before(time.Now()) || caughtUp.FireTime.Minute() != 0 || caughtUp.FireTime.Second() != 0 {
+		t.Errorf("catch-up run should carry the missed fire time, got %v", caughtUp.FireTime)
+	}
+}
+
+// recordingExecutor 记录逻辑触发时间与最大并发数的测试执行器
+type recordingExecutor struct {
+	*executor.SimpleExecutor
+	delay     time.Duration
+	mutex     sync.Mutex
+	active    int
+	maxActive int
+	fireTimes []time.Time
+}
+
+func (e *recordingExecutor) ExecuteRun(ctx context.Context, task *types.Task, run *types.Run) error {
+	e.mutex.Lock()
+	e.active++
+	if e.active > e.maxActive {
+		e.maxActive = e.active
+	}
+	e.fireTimes = append(e.fireTimes, run.FireTime)
+	e.mutex.Unlock()
+
+	time.Sleep(e.delay)
+
+	e.mutex.Lock()
+	e.active--
+	e.mutex.Unlock()
+	return nil
+}
+
+func TestBackfill(t *testing.T) {
+	ts := New(createTestConfig())
+	exec := &recordingExecutor{SimpleExecutor: executor.NewSimpleExecutor("exec-1", "http://localhost"), delay: 10 * time.Millisecond}
+	ts.AddExecutor(exec)
+	task := &types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h", MaxConcurrency: 2}
+	if err := ts.AddTask(task); err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+
+	from := time.Date(2026, 9, 17, 2, 0, 0, 0, time.Local)
+	to := time.Date(2026, 10, 16, 23, 59, 0, 0, time.Local)
+	job, err := ts.Backfill("report", from, to, BackfillOptions{MaxParallelism: 4})
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	result := job.Wait()
+	if result.Total != 30 || result.Succeeded != 30 {
+		t.Fatalf("expected 30 successful runs, got %+v", result)
+	}
+	if job.FireTimes[0] != from || job.FireTimes[29].Day() != 16 {
+		t.Errorf("unexpected fire times: first=%v last=%v", job.FireTimes[0], job.FireTimes[29])
+	}
+	// 并发受任务的 MaxConcurrency 限制
+	if exec.maxActive != 2 {
+		t.Errorf("expected concurrency capped at 2, got %d", exec.maxActive)
+	}
+
+	// 执行器收到的是逻辑触发时间，运行记录同样按逻辑触发时间保存
+	page, _ := ts.GetRunHistory(types.RunQuery{TaskID: "report"})
+	if page.Total != 30 || !page.Runs[0].FireTime.Equal(job.FireTimes[29]) || page.Runs[0].Trigger != types.RunTriggerBackfill {
+		t.Errorf("runs should carry logical fire times: %+v", page.Runs[0])
+	}
+	if len(exec.fireTimes) != 30 || !exec.fireTimes[0].Before(time.Date(2026, 9, 19, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("executor should receive logical fire times in order, got %v", exec.fireTimes)
+	}
+
+	if _, err := ts.Backfill("report", to, from, BackfillOptions{}); err == nil {
+		t.Error("inverted window should be rejected")
+	}
+	if _, err := ts.Backfill("report", from, to, BackfillOptions{MaxRuns: 10}); err == nil {
+		t.Error("window exceeding MaxRuns should be rejected")
+	}
+}
+
+// blockingExecutor 一直执行直到运行被取消的测试执行器
+type blockingExecutor struct {
+	*executor.SimpleExecutor
+	started chan string
+}
+
+func (e *blockingExecutor) ExecuteRun(ctx context.Context, task *types.Task, run *types.Run) error {
+	e.started <- run.ID
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCancelRun(t *testing.T) {
+	ts := New(createTestConfig())
+	exec := &blockingExecutor{SimpleExecutor: executor.NewSimpleExecutor("exec-1", "http://localhost"), started: make(chan string, 1)}
+	ts.AddExecutor(exec)
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h"})
+
+	run, err := ts.TriggerTask("report")
+	if err != nil {
+		t.Fatalf("TriggerTask failed: %v", err)
+	}
+	<-exec.started
+	if err := ts.CancelRun(run.ID); err != nil {
+		t.Fatalf("CancelRun failed: %v", err)
+	}
+
+	var cancelled *types.Run
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cancelled, _ = ts.GetStore().GetRun(run.ID); cancelled.Status.Finished() {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cancelled.Status != types.RunStatusCancelled {
+		t.Fatalf("expected cancelled run, got %+v", cancelled)
+	}
+
+	if err := ts.CancelRun(run.ID); !errors.Is(err, types.ErrRunFinished) {
+		t.Errorf("cancelling a finished run should return ErrRunFinished, got %v", err)
+	}
+	if err := ts.CancelRun("missing"); !errors.Is(err, types.ErrRunNotFound) {
+		t.Errorf("cancelling an unknown run should return ErrRunNotFound, got %v", err)
+	}
+	// 取消后释放并发名额
+	next, err := ts.TriggerTask("report")
+	if err != nil {
+		t.Fatalf("task should accept a new run after cancellation: %v", err)
+	}
+	<-exec.started
+	ts.CancelRun(next.ID)
+}
+
+func TestMetricsDroppedOnRemoval(t *testing.T) {
+	ts := New(createTestConfig())
+	ts.AddExecutor(executor.NewSimpleExecutor("exec-1", "http://localhost"))
+	ts.AddTask(&types.Task{ID: "once", Handler: "h"})
+	task, _ := ts.GetTask("once")
+	<-ts.fireTask(task, newRun(task, time.Now(), types.RunTriggerManual))
+
+	strategy := types.RoundRobinApp.String()
+	if ts.metrics.runs.Value("once", "succeeded", "exec-1", strategy) != 1 || ts.metrics.selections.Value("exec-1", strategy) != 1 {
+		t.Fatal("run should be recorded in the metrics")
+	}
+	// 移除任务和执行器后释放对应的标签序列
+	ts.RemoveTask("once")
+	ts.RemoveExecutor("exec-1")
+	var out strings.Builder
+	ts.Metrics().WriteText(&out)
+	if strings.Contains(out.String(), `task="once"`) || strings.Contains(out.String(), `executor="exec-1"`) {
+		t.Errorf("series of removed tasks and executors should be deleted:\n%s", out.String())
+	}
+}
+
+func TestResumeKeepsConcurrencyLimit(t *testing.T) {
+	ts := New(createTestConfig())
+	exec := &blockingExecutor{SimpleExecutor: executor.NewSimpleExecutor("exec-1", "http://localhost"), started: make(chan string, 1)}
+	ts.AddExecutor(exec)
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h"})
+
+	run, err := ts.TriggerTask("report")
+	if err != nil {
+		t.Fatalf("TriggerTask failed: %v", err)
+	}
+	<-exec.started
+	defer ts.CancelRun(run.ID)
+
+	// 暂停前派发的运行仍在执行，恢复和更新后仍占用并发名额
+	if err := ts.PauseTask("report"); err != nil {
+		t.Fatalf("PauseTask failed: %v", err)
+	}
+	if err := ts.ResumeTask("report"); err != nil {
+		t.Fatalf("ResumeTask failed: %v", err)
+	}
+	if _, err := ts.TriggerTask("report"); !errors.Is(err, types.ErrTaskBusy) {
+		t.Errorf("resumed task should still be at its concurrency limit, got %v", err)
+	}
+	if err := ts.UpdateTask(&types.Task{ID: "report", Name: "renamed", Cron: "0 0 3 * * *", Handler: "h"}); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	if _, err := ts.TriggerTask("report"); !errors.Is(err, types.ErrTaskBusy) {
+		t.Errorf("updated task should still be at its concurrency limit, got %v", err)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	ts := New(createTestConfig())
+	exec := &blockingExecutor{SimpleExecutor: executor.NewSimpleExecutor("exec-1", "http://localhost"), started: make(chan string, 1)}
+	ts.AddExecutor(exec)
+	if err := ts.AddTask(&types.Task{ID: "bad", Handler: "h", Timeout: -time.Second}); !errors.Is(err, types.ErrInvalidTask) {
+		t.Errorf("negative timeout should be rejected, got %v", err)
+	}
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h", Timeout: 20 * time.Millisecond})
+
+	task, _ := ts.GetTask("report")
+	run := <-ts.fireTask(task, newRun(task, time.Now(), types.RunTriggerManual))
+	<-exec.started
+	// 超时的运行记为失败而不是取消，并计入统计的超时次数
+	if run.Status != types.RunStatusFailed || !strings.Contains(run.Error, "timed out") {
+		t.Fatalf("expected timed out run to fail, got %+v", run)
+	}
+	report := ts.GetTaskStats()
+	if w := report.Tasks[0].Windows[0]; w.Failed != 1 || w.TimedOut != 1 || w.Cancelled != 0 {
+		t.Errorf("timeout should be counted: %+v", w)
+	}
+}
+
+// nextEvent 在超时前读取下一个事件
+func nextEvent(t *testing.T, events <-chan *types.Event) *types.Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func TestEvents(t *testing.T) {
+	ts := New(createTestConfig())
+	all, cancelAll := ts.Subscribe(types.EventFilter{})
+	defer cancelAll()
+	runs, cancelRuns := ts.Subscribe(types.EventFilter{TaskIDs: []string{"report"}, Types: []types.EventType{types.EventRunFinished}})
+	defer cancelRuns()
+
+	exec := executor.NewSimpleExecutor("exec-1", "http://localhost")
+	ts.AddExecutor(exec)
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h"})
+	ts.AddTask(&types.Task{ID: "other", Cron: "0 0 2 * * *", Handler: "h"})
+	ts.TriggerTask("other")
+	run, _ := ts.TriggerTask("report")
+
+	if event := nextEvent(t, runs); event.Run.ID != run.ID || event.Run.Status != types.RunStatusSucceeded {
+		t.Errorf("filtered subscriber should only see the finished run of report, got %+v", event)
+	}
+
+	var seen []types.EventType
+	var lastID uint64
+	for len(seen) < 9 {
+		event := nextEvent(t, all)
+		if event.ID <= lastID {
+			t.Errorf("event IDs should increase: %d after %d", event.ID, lastID)
+		}
+		lastID = event.ID
+		if event.TaskID == "other" && event.Type != types.EventTaskAdded {
+			continue
+		}
+		seen = append(seen, event.Type)
+		if len(seen) == 6 {
+			// 健康状态变化由健康检查发现
+			exec.SetHealthy(false)
+			ts.performHealthCheck()
+			exec.SetHealthy(true)
+			ts.performHealthCheck()
+			ts.PauseTask("report")
+		}
+	}
+	expected := []types.EventType{
+		types.EventExecutorJoined, types.EventTaskAdded, types.EventTaskAdded,
+		types.EventRunScheduled, types.EventRunStarted, types.EventRunFinished,
+		types.EventExecutorUnhealthy, types.EventExecutorRecovered, types.EventTaskPaused,
+	}
+	for i := range expected {
+		if seen[i] != expected[i] {
+			t.Fatalf("unexpected event sequence: %v", seen)
+		}
+	}
+}
+
+func TestSlowSubscribers(t *testing.T) {
+	ts := New(createTestConfig())
+	lossy, cancelLossy := ts.Subscribe(types.EventFilter{})
+	defer cancelLossy()
+	reliable, cancelReliable := ts.SubscribeReliable(types.EventFilter{})
+
+	// 两个订阅者都暂不消费，普通订阅者缓冲满后丢弃事件，不丢弃事件的订阅者排队
+	total := eventBuffer + 10
+	for i := 0; i < total; i++ {
+		ts.publishExecutorEvent(types.EventExecutorJoined, "exec-1")
+	}
+	if len(lossy) != eventBuffer {
+		t.Errorf("lossy subscriber should keep %d events, got %d", eventBuffer, len(lossy))
+	}
+	if dropped := ts.metrics.dropped.Value(string(types.EventExecutorJoined)); dropped != 10 {
+		t.Errorf("dropped events should be counted, got %v", dropped)
+	}
+
+	for i := 1; i <= total; i++ {
+		if event := nextEvent(t, reliable); event.ID != uint64(i) {
+			t.Fatalf("reliable subscriber should receive every event in order, got %d want %d", event.ID, i)
+		}
+	}
+	cancelReliable()
+	if _, ok := <-reliable; ok {
+		t.Error("channel should be closed after cancelling")
+	}
+}
+
+func TestHooksAndMiddleware(t *testing.T) {
+	ts := New(createTestConfig())
+	ts.AddExecutor(executor.NewSimpleExecutor("exec-1", "http://localhost"))
+
+	var mutex sync.Mutex
+	var calls []string
+	record := func(call string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls = append(calls, call)
+	}
+
+	ts.OnBeforeRoute(func(task *types.Task, run *types.Run) error {
+		record("before_route:" + task.ID)
+		if task.ID == "blocked" {
+			return errors.New("blocked by policy")
+		}
+		return nil
+	})
+	ts.OnAfterRoute(func(task *types.Task, run *types.Run, exec types.Executor) {
+		record("after_route:" + exec.GetID())
+	})
+	ts.OnBeforeExecute(func(ctx context.Context, task *types.Task, run *types.Run) error {
+		record("before_execute:" + task.ID)
+		if task.ID == "denied" {
+			return errors.New("missing credentials")
+		}
+		return nil
+	})
+	ts.OnAfterExecute(func(ctx context.Context, task *types.Task, run *types.Run, err error) {
+		record("after_execute:" + run.Status.String())
+	})
+	ts.OnFailure(func(task *types.Task, run *types.Run, err error) {
+		record("failure:" + err.Error())
+	})
+	ts.Use(
+		func(next ExecuteFunc) ExecuteFunc {
+			return func(ctx context.Context, exec types.Executor, task *types.Task, run *types.Run) error {
+				record("outer")
+				return next(ctx, exec, task, run)
+			}
+		},
+		// 参数模板：把逻辑触发日期注入任务副本
+		func(next ExecuteFunc) ExecuteFunc {
+			return func(ctx context.Context, exec types.Executor, task *types.Task, run *types.Run) error {
+				templated := *task
+				templated.Params = map[string]interface{}{"date": run.FireTime.Format("2006-01-02")}
+				return next(ctx, exec, &templated, run)
+			}
+		},
+		func(next ExecuteFunc) ExecuteFunc {
+			return func(ctx context.Context, exec types.Executor, task *types.Task, run *types.Run) error {
+				record("inner:" + task.Params.(map[string]interface{})["date"].(string))
+				return next(ctx, exec, task, run)
+			}
+		},
+	)
+
+	for _, id := range []string{"ok", "blocked", "denied"} {
+		ts.AddTask(&types.Task{ID: id, Cron: "0 0 2 * * *", Handler: "h"})
+	}
+	fire := func(id string) *types.Run {
+		task, _ := ts.GetTask(id)
+		run := newRun(task, time.Date(2026, 10, 1, 2, 0, 0, 0, time.Local), types.RunTriggerManual)
+		return <-ts.fireTask(task, run)
+	}
+
+	if run := fire("ok"); run.Status != types.RunStatusSucceeded {
+		t.Fatalf("expected success, got %+v", run)
+	}
+	expected := []string{"before_route:ok", "after_route:exec-1", "before_execute:ok", "outer", "inner:2026-10-01", "after_execute:succeeded"}
+	if strings.Join(calls, " ") != strings.Join(expected, " ") {
+		t.Errorf("unexpected call order:\n got %v\nwant %v", calls, expected)
+	}
+
+	calls = nil
+	if run := fire("blocked"); run.Status != types.RunStatusFailed || run.ExecutorID != "" {
+		t.Errorf("before-route error should fail the run without routing, got %+v", run)
+	}
+	if strings.Join(calls, " ") != "before_route:blocked failure:blocked by policy" {
+		t.Errorf("unexpected calls for rejected run: %v", calls)
+	}
+
+	calls = nil
+	if run := fire("denied"); run.Status != types.RunStatusFailed || run.Error != "missing credentials" {
+		t.Errorf("before-execute error should fail the run, got %+v", run)
+	}
+	for _, call := range calls {
+		if call == "outer" {
+			t.Errorf("executor should not run after a before-execute error: %v", calls)
+		}
+	}
+	if calls[len(calls)-1] != "failure:missing credentials" {
+		t.Errorf("failure hook should run last: %v", calls)
+	}
+}
+
+// funcExecutor 由函数决定执行结果的测试执行器
+type funcExecutor struct {
+	*executor.SimpleExecutor
+	execute func(task *types.Task) error
+}
+
+func (e *funcExecutor) ExecuteRun(ctx context.Context, task *types.Task, run *types.Run) error {
+	return e.execute(task)
+}
+
+func TestStats(t *testing.T) {
+	config := createTestConfig()
+	config.StatsWindows = []time.Duration{time.Minute, time.Hour}
+	ts := New(config)
+	exec := &funcExecutor{SimpleExecutor: executor.NewSimpleExecutor("exec-1", "http://localhost"), execute: func(task *types.Task) error {
+		switch task.ID {
+		case "flaky":
+			return errors.New("boom")
+		case "slow":
+			return context.DeadlineExceeded
+		}
+		return nil
+	}}
+	ts.AddExecutor(exec)
+	// 不健康的、非 SimpleExecutor 的执行器也要出现在统计中
+	down := &funcExecutor{SimpleExecutor: executor.NewSimpleExecutor("exec-2", "http://localhost")}
+	down.SetHealthy(false)
+	ts.AddExecutor(down)
+
+	for _, id := range []string{"ok", "flaky", "slow"} {
+		ts.AddTask(&types.Task{ID: id, Cron: "0 0 2 * * *", Handler: "h"})
+	}
+	fire := func(id string) {
+		task, _ := ts.GetTask(id)
+		<-ts.fireTask(task, newRun(task, time.Now(), types.RunTriggerManual))
+	}
+	for i := 0; i < 3; i++ {
+		fire("ok")
+	}
+	fire("flaky")
+	fire("slow")
+
+	report := ts.GetTaskStats()
+	if report.TotalTasks != 3 || report.StatusDistribution["pending"] != 3 || report.TotalExecutors != 2 || report.AvailableExecutors != 1 {
+		t.Errorf("unexpected summary: %+v", report)
+	}
+	byID := make(map[string]*types.TaskStats)
+	for _, stats := range report.Tasks {
+		byID[stats.TaskID] = stats
+	}
+	if w := byID["ok"].Windows[0]; w.Window != time.Minute || w.Runs != 3 || w.Succeeded != 3 || w.SuccessRate != 1 || w.P50 > w.P99 {
+		t.Errorf("unexpected stats for ok: %+v", w)
+	}
+	if byID["ok"].LastSuccessTime.IsZero() || !byID["ok"].LastFailureTime.IsZero() {
+		t.Errorf("unexpected last outcome for ok: %+v", byID["ok"])
+	}
+	if w := byID["flaky"].Windows[1]; w.Failed != 1 || w.TimedOut != 0 || w.SuccessRate != 0 {
+		t.Errorf("unexpected stats for flaky: %+v", w)
+	}
+	if w := byID["slow"].Windows[1]; w.Failed != 1 || w.TimedOut != 1 {
+		t.Errorf("timeouts should be counted: %+v", w)
+	}
+
+	executors := ts.GetExecutorStats()
+	if len(executors) != 2 || executors[1].ID != "exec-2" || executors[1].IsHealthy {
+		t.Fatalf("stats should include unhealthy executors: %+v", executors)
+	}
+	if w := executors[0].Windows[0]; w.Runs != 5 || w.Failed != 2 || w.ErrorRate != 0.4 || w.LoadShare != 1 {
+		t.Errorf("unexpected executor stats: %+v", w)
+	}
+}
+
+func TestFairness(t *testing.T) {
+	even := fairness([]float64{2, 2, 2})
+	if even.CoefficientOfVariation != 0 || even.Gini != 0 || *even.MaxMinRatio != 1 {
+		t.Errorf("even load should be perfectly fair: %+v", even)
+	}
+	skewed := fairness([]float64{0, 0, 3})
+	if math.Abs(skewed.Gini-2.0/3) > 1e-9 || math.Abs(skewed.CoefficientOfVariation-math.Sqrt2) > 1e-9 || skewed.MaxMinRatio != nil {
+		t.Errorf("unexpected metrics for skewed load: %+v", skewed)
+	}
+	if ratio := fairness([]float64{1, 4}).MaxMinRatio; ratio == nil || *ratio != 4 {
+		t.Errorf("expected max/min ratio 4, got %v", ratio)
+	}
+
+	config := createTestConfig()
+	config.StatsWindows = []time.Duration{time.Hour}
+	ts := New(config)
+	for _, id := range []string{"exec-1", "exec-2", "exec-3"} {
+		ts.AddExecutor(executor.NewSimpleExecutor(id, "http://localhost"))
+	}
+	task := &types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h", Strategy: types.RoundRobinApp}
+	ts.AddTask(task)
+	for i := 0; i < 6; i++ {
+		<-ts.fireTask(task, newRun(task, time.Now(), types.RunTriggerManual))
+	}
+
+	report := ts.GetFairnessStats()
+	if len(report) != 1 || report[0].Strategy != types.RoundRobinApp || report[0].Runs != 6 {
+		t.Fatalf("unexpected fairness report: %+v", report)
+	}
+	if got := report[0].RunCount; got.Gini != 0 || got.Min != 2 || got.Max != 2 {
+		t.Errorf("round robin should spread runs evenly: %+v %v", got, report[0].RunsByExecutor)
+	}
+}
+
+// spanRecorder 记录导出跨度的测试导出器
+type spanRecorder struct {
+	spans []*tracing.Span
+	mutex sync.Mutex
+}
+
+func (r *spanRecorder) Export(ctx context.Context, spans []*tracing.Span) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestTracing(t *testing.T) {
+	recorder := &spanRecorder{}
+	tracer := tracing.NewTracer(recorder)
+	ts := New(createTestConfig(), WithTracer(tracer))
+
+	// 远程执行器：解析运行信息并接续追踪
+	var envelope *executor.RunEnvelope
+	var parent tracing.SpanContext
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ctx context.Context
+		var err error
+		ctx, envelope, err = executor.DecodeRunEnvelope(r)
+		if err != nil || r.URL.Path != "/runs" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_, span := tracing.NewTracer(&spanRecorder{}).Start(ctx, "worker.call")
+		parent = span.SpanContext()
+		if envelope.Task.ID == "broken" {
+			http.Error(w, "handler crashed", http.StatusInternalServerError)
+		}
+	}))
+	defer worker.Close()
+	ts.AddExecutor(executor.NewHTTPExecutor("remote-1", worker.URL))
+
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h"})
+	ts.AddTask(&types.Task{ID: "broken", Cron: "0 0 2 * * *", Handler: "h"})
+	task, _ := ts.GetTask("report")
+	run := <-ts.fireTask(task, newRun(task, time.Now(), types.RunTriggerManual))
+	if run.Status != types.RunStatusSucceeded {
+		t.Fatalf("run should succeed: %+v", run)
+	}
+	tracer.Flush()
+
+	byName := make(map[string]*tracing.Span)
+	for _, span := range recorder.spans {
+		byName[span.Name] = span
+	}
+	root, execute := byName[spanRun], byName[spanExecute]
+	for _, name := range []string{spanRun, spanScheduleDelay, spanRoute, spanDispatch, spanExecute} {
+		span, exists := byName[name]
+		if !exists {
+			t.Fatalf("missing span %s: %+v", name, recorder.spans)
+		}
+		if span.TraceID != root.TraceID || (name != spanRun && span.ParentSpanID != root.SpanID) {
+			t.Errorf("span %s is not a child of the run span: %+v", name, span)
+		}
+	}
+	if root.Attributes["run.id"] != run.ID || root.Attributes["executor.id"] != "remote-1" || root.Status != tracing.StatusOK {
+		t.Errorf("unexpected run span: %+v", root)
+	}
+	if envelope.Run.ID != run.ID || envelope.Traceparent == "" {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+	if parent.TraceID.String() != root.TraceID {
+		t.Errorf("worker span should continue the scheduler trace: %s vs %s", parent.TraceID, root.TraceID)
+	}
+	if !strings.Contains(envelope.Traceparent, execute.SpanID) {
+		t.Errorf("traceparent %s should reference the execute span %s", envelope.Traceparent, execute.SpanID)
+	}
+
+	recorder.spans = nil
+	task, _ = ts.GetTask("broken")
+	run = <-ts.fireTask(task, newRun(task, time.Now(), types.RunTriggerManual))
+	tracer.Shutdown()
+	if run.Status != types.RunStatusFailed || !strings.Contains(run.Error, "handler crashed") {
+		t.Fatalf("remote failure should fail the run: %+v", run)
+	}
+	for _, span := range recorder.spans {
+		if (span.Name == spanRun || span.Name == spanExecute) && span.Status != tracing.StatusError {
+			t.Errorf("span %s should record the failure: %+v", span.Name, span)
+		}
+	}
+}
+
+// lockedBuffer 并发安全的日志输出
+type lockedBuffer struct {
+	builder strings.Builder
+	mutex   sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.builder.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.builder.String()
+}
+
+func TestStructuredLogging(t *testing.T) {
+	var out lockedBuffer
+	config := createTestConfig()
+	config.Logger = logging.New(&out, logging.FormatJSON, slog.LevelDebug)
+	ts := createTestScheduler(t, config)
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h", Strategy: types.LFU})
+
+	task, _ := ts.GetTask("report")
+	run := <-ts.fireTask(task, newRun(task, time.Now(), types.RunTriggerManual))
+
+	var runEntries int
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %q", line)
+		}
+		if entry[logging.KeyRunID] == nil {
+			continue
+		}
+		runEntries++
+		if entry[logging.KeyRunID] != run.ID || entry[logging.KeyTaskID] != "report" ||
+			entry[logging.KeyExecutorID] != run.ExecutorID || entry[logging.KeyStrategy] != types.LFU.String() ||
+			entry[logging.KeyAttempt] != float64(1) {
+			t.Errorf("run log entry is missing correlation fields: %s", line)
+		}
+	}
+	// 开始执行、执行器模拟执行、执行结果
+	if runEntries < 3 {
+		t.Errorf("expected run log entries, got:\n%s", out.String())
+	}
+}
+
+func TestDispatchPassesTaskCopy(t *testing.T) {
+	ts := createTestScheduler(t, createTestConfig())
+	started := make(chan *types.Task, 1)
+	release := make(chan struct{})
+	ts.Use(func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context, exec types.Executor, task *types.Task, run *types.Run) error {
+			status := task.Status
+			started <- task
+			<-release
+			// 执行器（如HTTPExecutor）会在执行期间读取和序列化任务
+			if task.Status != status || task.NextRunTime.IsZero() {
+				return errors.New("task copy changed during execution")
+			}
+			return next(ctx, exec, task, run)
+		}
+	})
+	ts.AddTask(&types.Task{ID: "report", Cron: "0 0 2 * * *", Handler: "h"})
+
+	// cron触发和手动触发派发的都是调度器持有的任务
+	ts.taskMutex.RLock()
+	live := ts.tasks["report"]
+	ts.taskMutex.RUnlock()
+	done := ts.fireTask(live, newRun(live, time.Now(), types.RunTriggerManual))
+	if executing := <-started; executing == live {
+		t.Fatal("executors should not receive the live task")
+	}
+
+	// 执行期间暂停任务会修改任务状态和下次触发时间
+	ts.PauseTask("report")
+	close(release)
+	if run := <-done; run.Status != types.RunStatusSucceeded {
+		t.Errorf("expected the run to succeed on its own copy, got %+v", run)
+	}
+}
+
+func TestHealthCheckProbesRemoteExecutors(t *testing.T) {
+	ts := New(createTestConfig())
+	var mutex sync.Mutex
+	up := true
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if r.URL.Path != "/healthz" || !up {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer worker.Close()
+	remote := executor.NewHTTPExecutor("remote-1", worker.URL)
+	ts.AddExecutor(remote)
+	events, cancel := ts.Subscribe(types.EventFilter{Types: []types.EventType{types.EventExecutorUnhealthy, types.EventExecutorRecovered}})
+	defer cancel()
+
+	heartbeat := func() time.Time {
+		ts.healthMutex.Lock()
+		defer ts.healthMutex.Unlock()
+		return ts.executorHeartbeat["remote-1"]
+	}
+	ts.performHealthCheck()
+	seen := heartbeat()
+
+	// 远程执行器失联：本地健康标记仍为真，探测失败后才会被发现
+	mutex.Lock()
+	up = false
+	mutex.Unlock()
+	ts.performHealthCheck()
+	if event := nextEvent(t, events); event.Type != types.EventExecutorUnhealthy || remote.IsHealthy() {
+		t.Fatalf("failed probe should mark the executor unhealthy, got %+v", event)
+	}
+	if !heartbeat().Equal(seen) {
+		t.Error("heartbeat should not advance while the probe fails")
+	}
+
+	mutex.Lock()
+	up = true
+	mutex.Unlock()
+	ts.performHealthCheck()
+	if event := nextEvent(t, events); event.Type != types.EventExecutorRecovered || !remote.IsHealthy() {
+		t.Fatalf("successful probe should recover the executor, got %+v", event)
+	}
+	if !heartbeat().After(seen) {
+		t.Error("heartbeat should advance after a successful probe")
+	}
+}
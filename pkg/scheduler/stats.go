@@ -1,44 +1,275 @@
 package scheduler
 
 import (
-	"task_scheduler/pkg/executor"
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
 	"task_scheduler/pkg/router"
 	"task_scheduler/pkg/types"
 )
 
+// runSample 一次结束的运行，用于滚动窗口统计
+type runSample struct {
+	end        time.Time
+	taskID     string
+	executorID string // 派发失败时为空
+	strategy   types.RouteStrategy
+	status     types.RunStatus
+	timedOut   bool
+	duration   time.Duration // 未开始执行时为0
+}
+
+// runRecorder 记录最大窗口内结束的运行
+type runRecorder struct {
+	samples     []runSample // 按记录顺序（近似按结束时间）排列
+	retention   time.Duration
+	lastSuccess map[string]time.Time
+	lastFailure map[string]time.Time
+	mutex       sync.RWMutex
+}
+
+// newRunRecorder 创建运行记录器，保留最大窗口内的样本
+func newRunRecorder(windows []time.Duration) *runRecorder {
+	var retention time.Duration
+	for _, window := range windows {
+		if window > retention {
+			retention = window
+		}
+	}
+	return &runRecorder{
+		retention:   retention,
+		lastSuccess: make(map[string]time.Time),
+		lastFailure: make(map[string]time.Time),
+	}
+}
+
+// record 记录结束的运行，err 为执行返回的错误
+func (r *runRecorder) record(run *types.Run, err error) {
+	sample := runSample{
+		end:        run.EndTime,
+		taskID:     run.TaskID,
+		executorID: run.ExecutorID,
+		strategy:   run.Strategy,
+		status:     run.Status,
+		timedOut:   errors.Is(err, context.DeadlineExceeded),
+	}
+	if !run.StartTime.IsZero() {
+		sample.duration = run.EndTime.Sub(run.StartTime)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	switch run.Status {
+	case types.RunStatusSucceeded:
+		r.lastSuccess[run.TaskID] = run.EndTime
+	case types.RunStatusFailed:
+		r.lastFailure[run.TaskID] = run.EndTime
+	}
+
+	// 丢弃超出最大窗口的样本
+	cutoff := run.EndTime.Add(-r.retention)
+	drop := 0
+	for drop < len(r.samples) && r.samples[drop].end.Before(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		r.samples = append(r.samples[:0], r.samples[drop:]...)
+	}
+	r.samples = append(r.samples, sample)
+}
+
+// since 返回结束时间不早于 from 的样本副本
+func (r *runRecorder) since(from time.Time) []runSample {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	samples := make([]runSample, 0, len(r.samples))
+	for _, sample := range r.samples {
+		if !sample.end.Before(from) {
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+// lastOutcome 返回进程内记录的最近成功和失败时间
+func (r *runRecorder) lastOutcome(taskID string) (success, failure time.Time, known bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	success, hasSuccess := r.lastSuccess[taskID]
+	failure, hasFailure := r.lastFailure[taskID]
+	return success, failure, hasSuccess || hasFailure
+}
+
+// statsWindows 配置的统计窗口
+func (ts *TaskScheduler) statsWindows() []time.Duration {
+	if len(ts.config.StatsWindows) > 0 {
+		return ts.config.StatsWindows
+	}
+	return types.DefaultStatsWindows
+}
+
 // GetTaskStats 获取任务统计信息
-func (ts *TaskScheduler) GetTaskStats() map[string]interface{} {
-	ts.taskMutex.RLock()
-	defer ts.taskMutex.RUnlock()
+// 窗口统计来自进程内记录的运行；最近成功、失败时间在进程内没有记录时从运行历史中查询
+func (ts *TaskScheduler) GetTaskStats() *types.TaskStatsReport {
+	tasks := ts.GetTasks()
+	report := &types.TaskStatsReport{
+		TotalTasks:           len(tasks),
+		StatusDistribution:   make(map[string]int),
+		StrategyDistribution: make(map[string]int),
+		TotalExecutors:       len(ts.executorManager.ListExecutors()),
+		AvailableExecutors:   len(ts.executorManager.GetExecutors()),
+		Tasks:                make([]*types.TaskStats, 0, len(tasks)),
+	}
+
+	now := time.Now()
+	windows := ts.statsWindows()
+	samplesByWindow := make([][]runSample, len(windows))
+	for i, window := range windows {
+		samplesByWindow[i] = ts.runStats.since(now.Add(-window))
+	}
 
-	stats := make(map[string]interface{})
-	statusCount := make(map[types.TaskStatus]int)
-	strategyCount := make(map[types.RouteStrategy]int)
+	for _, task := range tasks {
+		report.StatusDistribution[task.Status.String()]++
+		report.StrategyDistribution[task.Strategy.String()]++
 
-	for _, task := range ts.tasks {
-		statusCount[task.Status]++
-		strategyCount[task.Strategy]++
+		stats := &types.TaskStats{
+			TaskID:   task.ID,
+			Status:   task.Status,
+			Strategy: task.Strategy,
+			Windows:  make([]types.TaskWindowStats, len(windows)),
+		}
+		stats.LastSuccessTime, stats.LastFailureTime = ts.lastOutcome(task.ID)
+		for i, window := range windows {
+			stats.Windows[i] = taskWindowStats(window, task.ID, samplesByWindow[i])
+		}
+		report.Tasks = append(report.Tasks, stats)
 	}
+	return report
+}
 
-	stats["total_tasks"] = len(ts.tasks)
-	stats["status_distribution"] = statusCount
-	stats["strategy_distribution"] = strategyCount
-	stats["total_executors"] = len(ts.executorManager.GetExecutors())
+// lastOutcome 任务最近一次成功和失败的结束时间
+func (ts *TaskScheduler) lastOutcome(taskID string) (success, failure time.Time) {
+	if success, failure, known := ts.runStats.lastOutcome(taskID); known {
+		return success, failure
+	}
+	latest := func(status types.RunStatus) time.Time {
+		page, err := ts.store.QueryRuns(types.RunQuery{TaskID: taskID, Statuses: []types.RunStatus{status}, Limit: 1})
+		if err != nil || len(page.Runs) == 0 {
+			return time.Time{}
+		}
+		return page.Runs[0].EndTime
+	}
+	return latest(types.RunStatusSucceeded), latest(types.RunStatusFailed)
+}
 
+// taskWindowStats 计算任务在窗口内的统计
+func taskWindowStats(window time.Duration, taskID string, samples []runSample) types.TaskWindowStats {
+	stats := types.TaskWindowStats{Window: window}
+	var durations []time.Duration
+	for _, sample := range samples {
+		if sample.taskID != taskID {
+			continue
+		}
+		stats.Runs++
+		switch sample.status {
+		case types.RunStatusSucceeded:
+			stats.Succeeded++
+		case types.RunStatusFailed:
+			stats.Failed++
+			if sample.timedOut {
+				stats.TimedOut++
+			}
+		case types.RunStatusCancelled:
+			stats.Cancelled++
+		}
+		if sample.executorID != "" {
+			durations = append(durations, sample.duration)
+		}
+	}
+	if finished := stats.Succeeded + stats.Failed; finished > 0 {
+		stats.SuccessRate = float64(stats.Succeeded) / float64(finished)
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.P50 = percentile(durations, 0.50)
+	stats.P95 = percentile(durations, 0.95)
+	stats.P99 = percentile(durations, 0.99)
 	return stats
 }
 
-// GetExecutorStats 获取执行器统计信息
+// percentile 已排序耗时的分位数（最近秩法），为空时返回0
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// GetExecutorStats 获取所有执行器的统计信息，包括不健康和排空中的执行器
 func (ts *TaskScheduler) GetExecutorStats() []*types.ExecutorStats {
-	executors := ts.executorManager.GetExecutors()
-	stats := make([]*types.ExecutorStats, 0, len(executors))
+	executors := ts.executorManager.ListExecutors()
+	now := time.Now()
+	windows := ts.statsWindows()
 
+	stats := make([]*types.ExecutorStats, 0, len(executors))
+	byID := make(map[string]*types.ExecutorStats, len(executors))
 	for _, exec := range executors {
-		if simpleExec, ok := exec.(*executor.SimpleExecutor); ok {
-			stats = append(stats, simpleExec.GetStats())
+		stat := &types.ExecutorStats{
+			ID:           exec.GetID(),
+			Address:      exec.GetAddress(),
+			UsageCount:   exec.GetUsageCount(),
+			LastUsedTime: exec.GetLastUsedTime(),
+			IsHealthy:    exec.IsHealthy(),
+			Draining:     ts.executorManager.IsDraining(exec.GetID()),
+			InFlight:     ts.router.InFlight().Count(exec.GetID()),
+			Windows:      make([]types.ExecutorWindowStats, len(windows)),
+		}
+		for i, window := range windows {
+			stat.Windows[i].Window = window
 		}
+		stats = append(stats, stat)
+		byID[stat.ID] = stat
 	}
 
+	for i, window := range windows {
+		total := 0
+		cancelled := make(map[string]int)
+		for _, sample := range ts.runStats.since(now.Add(-window)) {
+			if sample.executorID == "" {
+				continue
+			}
+			total++
+			stat, exists := byID[sample.executorID]
+			if !exists {
+				continue
+			}
+			windowStats := &stat.Windows[i]
+			windowStats.Runs++
+			windowStats.BusyTime += sample.duration
+			switch sample.status {
+			case types.RunStatusFailed:
+				windowStats.Failed++
+			case types.RunStatusCancelled:
+				cancelled[sample.executorID]++
+			}
+		}
+		for _, stat := range stats {
+			windowStats := &stat.Windows[i]
+			if total > 0 {
+				windowStats.LoadShare = float64(windowStats.Runs) / float64(total)
+			}
+			if counted := windowStats.Runs - cancelled[stat.ID]; counted > 0 {
+				windowStats.ErrorRate = float64(windowStats.Failed) / float64(counted)
+			}
+		}
+	}
 	return stats
 }
 
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"task_scheduler/pkg/logging"
+	"task_scheduler/pkg/types"
+)
+
+// loadTasks 从存储加载尚未注册的任务
+func (ts *TaskScheduler) loadTasks() error {
+	tasks, err := ts.store.ListTasks()
+	if err != nil {
+		return fmt.Errorf("failed to load tasks from store: %v", err)
+	}
+
+	ts.taskMutex.Lock()
+	defer ts.taskMutex.Unlock()
+
+	loaded := 0
+	for _, task := range tasks {
+		if _, exists := ts.tasks[task.ID]; exists {
+			continue
+		}
+		// 上次退出时仍在运行的任务重置为待执行
+		if task.Status == types.TaskStatusRunning {
+			task.Status = types.TaskStatusPending
+		}
+		if err := ts.registerTask(task); err != nil {
+			ts.taskLogger(task).Error("Failed to restore task", logging.Err(err))
+			continue
+		}
+		loaded++
+	}
+
+	if loaded > 0 {
+		ts.logger.Info("Restored tasks from store", "count", loaded)
+	}
+	return nil
+}
+
+// LoadTasks 从存储加载任务但不启动调度，用于回填等离线操作；Start 时会自动加载
+func (ts *TaskScheduler) LoadTasks() error {
+	return ts.loadTasks()
+}
+
+// GetRunHistory 按任务、状态、执行器、时间范围分页查询运行历史
+func (ts *TaskScheduler) GetRunHistory(query types.RunQuery) (*types.RunPage, error) {
+	return ts.store.QueryRuns(query)
+}
+
+// updateTaskState 在taskMutex保护下修改当前注册的任务的运行状态，并将修改后的副本写入存储
+// 按ID查找任务：运行期间任务定义可能被更新或移除
+func (ts *TaskScheduler) updateTaskState(taskID string, update func(task *types.Task)) {
+	ts.taskMutex.Lock()
+	task, exists := ts.tasks[taskID]
+	if !exists {
+		ts.taskMutex.Unlock()
+		return
+	}
+	update(task)
+	snapshot := *task
+	ts.taskMutex.Unlock()
+	ts.saveTask(&snapshot)
+}
+
+// taskStatus 读取任务的运行状态
+func (ts *TaskScheduler) taskStatus(task *types.Task) types.TaskStatus {
+	ts.taskMutex.RLock()
+	defer ts.taskMutex.RUnlock()
+	return task.Status
+}
+
+// saveTask 将任务的最新状态写入存储
+func (ts *TaskScheduler) saveTask(task *types.Task) {
+	err := ts.store.UpdateTask(task)
+	if errors.Is(err, types.ErrTaskNotFound) {
+		// 任务在运行期间被移除
+		return
+	}
+	if err != nil {
+		ts.taskLogger(task).Error("Failed to persist task", logging.Err(err))
+	}
+}
+
+// saveRun 将运行记录写入存储
+func (ts *TaskScheduler) saveRun(run *types.Run, create bool) {
+	var err error
+	if create {
+		err = ts.store.CreateRun(run)
+	} else {
+		err = ts.store.UpdateRun(run)
+	}
+	if errors.Is(err, types.ErrRunNotFound) {
+		// 运行记录随任务一起被移除
+		return
+	}
+	if err != nil {
+		ts.runLogger(context.Background(), run).Error("Failed to persist run", logging.Err(err))
+	}
+}
+
+// newRunID 生成运行ID，前缀为时间戳便于按时间排序
+func newRunID() string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%d-%s", time.Now().UnixNano(), hex.EncodeToString(suffix))
+}
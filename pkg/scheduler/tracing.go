@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"task_scheduler/pkg/tracing"
+	"task_scheduler/pkg/types"
+)
+
+// 运行追踪的跨度名称
+// 一次运行是一条追踪：根跨度 task.run 覆盖从进入派发到结束，
+// 子跨度依次为等待并发名额的调度延迟、路由、派发和执行；
+// 执行跨度放在传给执行器的上下文中，远程执行器据此传播 traceparent
+const (
+	spanRun           = "task.run"
+	spanScheduleDelay = "task.schedule_delay"
+	spanRoute         = "task.route"
+	spanDispatch      = "task.dispatch"
+	spanExecute       = "task.execute"
+)
+
+// tracer 配置的追踪器，未配置时为nil（不生成跨度）
+func (ts *TaskScheduler) tracer() *tracing.Tracer {
+	return ts.tracing
+}
+
+// startRunSpan 创建运行的根跨度，queuedAt 为运行开始等待并发名额的时间
+// 同时记录从 queuedAt 到现在的调度延迟子跨度
+func (ts *TaskScheduler) startRunSpan(task *types.Task, run *types.Run, queuedAt time.Time) (context.Context, *tracing.Span) {
+	ctx, span := ts.tracer().Start(context.Background(), spanRun,
+		tracing.WithStartTime(queuedAt),
+		tracing.WithAttributes(
+			"task.id", task.ID,
+			"task.handler", task.Handler,
+			"run.id", run.ID,
+			"run.trigger", string(run.Trigger),
+			"run.attempt", strconv.Itoa(run.Attempt),
+			"run.fire_time", run.FireTime.Format(time.RFC3339Nano),
+			"route.strategy", run.Strategy.String(),
+		))
+
+	now := time.Now()
+	_, delay := ts.tracer().Start(ctx, spanScheduleDelay,
+		tracing.WithStartTime(queuedAt),
+		tracing.WithAttributes("schedule.lag_ms", strconv.FormatInt(now.Sub(run.FireTime).Milliseconds(), 10)))
+	delay.EndAt(now)
+	return ctx, span
+}
+
+// endRunSpan 以运行的最终状态结束根跨度
+func endRunSpan(span *tracing.Span, run *types.Run) {
+	span.SetAttribute("run.status", run.Status.String())
+	if run.ExecutorID != "" {
+		span.SetAttribute("executor.id", run.ExecutorID)
+	}
+	if run.Status == types.RunStatusSucceeded {
+		span.SetStatus(nil)
+	} else {
+		span.SetStatus(errors.New(run.Error))
+	}
+	span.EndAt(run.EndTime)
+}
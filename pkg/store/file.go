@@ -0,0 +1,345 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"task_scheduler/pkg/types"
+)
+
+const (
+	snapshotFileName = "snapshot.json"
+	logFileName      = "store.log"
+	lockFileName     = "store.lock"
+)
+
+// ErrLocked 存储目录已被其他进程打开
+var ErrLocked = errors.New("store directory is locked by another process")
+
+// 日志记录的操作类型
+const (
+	opTaskPut    = "task_put"
+	opTaskDelete = "task_delete"
+	opRunPut     = "run_put"
+)
+
+// logRecord 追加日志中的一条记录
+type logRecord struct {
+	Op     string      `json:"op"`
+	TaskID string      `json:"task_id,omitempty"`
+	Task   *types.Task `json:"task,omitempty"`
+	Run    *types.Run  `json:"run,omitempty"`
+}
+
+// snapshot 存储快照
+type snapshot struct {
+	Tasks []*types.Task `json:"tasks"`
+	Runs  []*types.Run  `json:"runs"`
+}
+
+// FileStore 基于文件的持久化任务存储
+// 每次变更先应用到内存，再追加到日志文件；日志条数达到阈值时生成快照并截断日志，
+// 启动时加载快照并重放日志恢复状态。打开期间持有目录的排他锁，同一目录只能被一个进程打开
+type FileStore struct {
+	*MemoryStore
+	dir        string
+	lockFile   *os.File
+	logFile    *os.File
+	logEntries int
+	mutex      sync.Mutex // 保证内存变更与日志追加的顺序一致
+}
+
+// NewFileStore 打开（或创建）目录下的文件存储
+func NewFileStore(dir string, opts ...Option) (*FileStore, error) {
+	o := newOptions(opts)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory %s: %v", dir, err)
+	}
+
+	lockFile, err := lockDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	fs := &FileStore{
+		MemoryStore: newMemoryStore(o),
+		dir:         dir,
+		lockFile:    lockFile,
+	}
+	if err := fs.loadSnapshot(); err != nil {
+		lockFile.Close()
+		return nil, err
+	}
+	if err := fs.replayLog(); err != nil {
+		lockFile.Close()
+		return nil, err
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		lockFile.Close()
+		return nil, fmt.Errorf("failed to open store log: %v", err)
+	}
+	fs.logFile = logFile
+	return fs, nil
+}
+
+// CreateTask 创建任务并追加日志
+func (fs *FileStore) CreateTask(task *types.Task) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if err := fs.MemoryStore.CreateTask(task); err != nil {
+		return err
+	}
+	return fs.append(logRecord{Op: opTaskPut, Task: task})
+}
+
+// UpdateTask 更新任务并追加日志
+func (fs *FileStore) UpdateTask(task *types.Task) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if err := fs.MemoryStore.UpdateTask(task); err != nil {
+		return err
+	}
+	return fs.append(logRecord{Op: opTaskPut, Task: task})
+}
+
+// DeleteTask 删除任务并追加日志
+func (fs *FileStore) DeleteTask(taskID string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if err := fs.MemoryStore.DeleteTask(taskID); err != nil {
+		return err
+	}
+	return fs.append(logRecord{Op: opTaskDelete, TaskID: taskID})
+}
+
+// CreateRun 创建运行记录并追加日志
+func (fs *FileStore) CreateRun(run *types.Run) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if err := fs.MemoryStore.CreateRun(run); err != nil {
+		return err
+	}
+	return fs.append(logRecord{Op: opRunPut, Run: run})
+}
+
+// UpdateRun 更新运行记录并追加日志
+func (fs *FileStore) UpdateRun(run *types.Run) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if err := fs.MemoryStore.UpdateRun(run); err != nil {
+		return err
+	}
+	return fs.append(logRecord{Op: opRunPut, Run: run})
+}
+
+// Snapshot 立即生成快照并截断日志
+func (fs *FileStore) Snapshot() error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	return fs.writeSnapshot()
+}
+
+// Close 生成最终快照，关闭日志文件并释放目录锁
+func (fs *FileStore) Close() error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if fs.logFile == nil {
+		return nil
+	}
+	err := fs.writeSnapshot()
+	if closeErr := fs.logFile.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	fs.logFile = nil
+	fs.lockFile.Close()
+	fs.MemoryStore.Close()
+	return err
+}
+
+// append 追加一条日志记录，达到阈值时生成快照（调用方持有fs.mutex）
+func (fs *FileStore) append(record logRecord) error {
+	if fs.logFile == nil {
+		return fmt.Errorf("store is closed")
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode store record: %v", err)
+	}
+	data = append(data, '\n')
+	if _, err := fs.logFile.Write(data); err != nil {
+		return fmt.Errorf("failed to append store log: %v", err)
+	}
+	if fs.MemoryStore.opts.syncWrites {
+		if err := fs.logFile.Sync(); err != nil {
+			return fmt.Errorf("failed to sync store log: %v", err)
+		}
+	}
+
+	fs.logEntries++
+	if fs.MemoryStore.opts.snapshotEvery > 0 && fs.logEntries >= fs.MemoryStore.opts.snapshotEvery {
+		return fs.writeSnapshot()
+	}
+	return nil
+}
+
+// writeSnapshot 写入快照并截断日志（调用方持有fs.mutex）
+// 快照先写临时文件并fsync，重命名后fsync目录，确认快照落盘后才截断日志；
+// 若在截断日志前崩溃，重放日志是幂等的
+func (fs *FileStore) writeSnapshot() error {
+	fs.MemoryStore.mutex.RLock()
+	snap := snapshot{
+		Tasks: make([]*types.Task, 0, len(fs.MemoryStore.tasks)),
+		Runs:  make([]*types.Run, 0, len(fs.MemoryStore.runs)),
+	}
+	for _, task := range fs.MemoryStore.tasks {
+		snap.Tasks = append(snap.Tasks, task)
+	}
+	for _, runIDs := range fs.MemoryStore.taskRuns {
+		for _, runID := range runIDs {
+			snap.Runs = append(snap.Runs, fs.MemoryStore.runs[runID])
+		}
+	}
+	data, err := json.Marshal(snap)
+	fs.MemoryStore.mutex.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode store snapshot: %v", err)
+	}
+
+	path := filepath.Join(fs.dir, snapshotFileName)
+	tmpPath := path + ".tmp"
+	if err := writeFileSync(tmpPath, data); err != nil {
+		return fmt.Errorf("failed to write store snapshot: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace store snapshot: %v", err)
+	}
+	if err := syncDir(fs.dir); err != nil {
+		return fmt.Errorf("failed to sync store directory: %v", err)
+	}
+
+	if fs.logFile != nil {
+		if err := fs.logFile.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate store log: %v", err)
+		}
+		if err := fs.logFile.Sync(); err != nil {
+			return fmt.Errorf("failed to sync store log: %v", err)
+		}
+	}
+	fs.logEntries = 0
+	return nil
+}
+
+// writeFileSync 写入文件并fsync，保证返回时内容已落盘
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// syncDir fsync目录，使目录内的创建和重命名落盘
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
+
+// loadSnapshot 加载快照
+func (fs *FileStore) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(fs.dir, snapshotFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read store snapshot: %v", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode store snapshot: %v", err)
+	}
+	for _, task := range snap.Tasks {
+		fs.MemoryStore.putTask(task)
+	}
+	for _, run := range snap.Runs {
+		fs.MemoryStore.putRun(run)
+	}
+	return nil
+}
+
+// replayLog 重放快照之后追加的日志
+// 进程崩溃可能留下写了一半的最后一行，遇到无法解析的记录时截断到最后一条完整记录
+func (fs *FileStore) replayLog() error {
+	path := filepath.Join(fs.dir, logFileName)
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open store log: %v", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			break
+		}
+		var record logRecord
+		if err != nil || json.Unmarshal(bytes.TrimSpace(line), &record) != nil {
+			log.Printf("Store log %s has a torn record at offset %d, truncating", path, offset)
+			return os.Truncate(path, offset)
+		}
+		fs.apply(record)
+		fs.logEntries++
+		offset += int64(len(line))
+	}
+	return nil
+}
+
+// apply 将日志记录应用到内存状态，重复应用结果不变
+func (fs *FileStore) apply(record logRecord) {
+	switch record.Op {
+	case opTaskPut:
+		if record.Task != nil {
+			fs.MemoryStore.putTask(record.Task)
+		}
+	case opTaskDelete:
+		fs.MemoryStore.removeTask(record.TaskID)
+	case opRunPut:
+		if record.Run != nil {
+			fs.MemoryStore.putRun(record.Run)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockDir 不支持 flock 的平台上只创建锁文件，不提供跨进程互斥
+func lockDir(dir string) (*os.File, error) {
+	file, err := os.OpenFile(filepath.Join(dir, lockFileName), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store lock: %v", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockDir 以非阻塞的排他锁锁定存储目录，目录已被其他进程锁定时返回 ErrLocked
+// 锁随文件描述符释放，进程崩溃后不会残留
+func lockDir(dir string) (*os.File, error) {
+	file, err := os.OpenFile(filepath.Join(dir, lockFileName), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store lock: %v", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("store %s: %w", dir, ErrLocked)
+		}
+		return nil, fmt.Errorf("failed to lock store directory %s: %v", dir, err)
+	}
+	return file, nil
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package store
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFileStoreLocksDirectory(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	if _, err := NewFileStore(dir); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second open of the same directory should fail with ErrLocked, got %v", err)
+	}
+
+	s.Close()
+	reopened, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("reopen after Close failed: %v", err)
+	}
+	reopened.Close()
+}
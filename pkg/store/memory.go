@@ -0,0 +1,382 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+const (
+	// watchBuffer 每个订阅者的事件缓冲大小，缓冲满时丢弃事件，避免慢订阅者阻塞写入
+	watchBuffer = 64
+	// ageSweepInterval 按保留时长清理全部任务运行历史的最小间隔
+	ageSweepInterval = time.Minute
+)
+
+// MemoryStore 内存任务存储
+// 运行历史按保留策略裁剪，默认每个任务最多保留DefaultMaxRunsPerTask条
+type MemoryStore struct {
+	tasks       map[string]*types.Task
+	runs        map[string]*types.Run
+	taskRuns    map[string][]string // taskID -> 按创建顺序排列的runID
+	watchers    map[int]chan types.StoreEvent
+	nextWatcher int
+	opts        options
+	lastSweep   time.Time
+	now         func() time.Time
+	mutex       sync.RWMutex
+}
+
+// NewMemoryStore 创建内存任务存储
+func NewMemoryStore(opts ...Option) *MemoryStore {
+	return newMemoryStore(newOptions(opts))
+}
+
+// newMemoryStore 根据配置创建内存任务存储
+func newMemoryStore(o options) *MemoryStore {
+	return &MemoryStore{
+		tasks:    make(map[string]*types.Task),
+		runs:     make(map[string]*types.Run),
+		taskRuns: make(map[string][]string),
+		watchers: make(map[int]chan types.StoreEvent),
+		opts:     o,
+		now:      time.Now,
+	}
+}
+
+// CreateTask 创建任务
+func (s *MemoryStore) CreateTask(task *types.Task) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.tasks[task.ID]; exists {
+		return fmt.Errorf("task %s: %w", task.ID, types.ErrTaskExists)
+	}
+	s.putTask(task)
+	s.notify(types.StoreEvent{Type: types.StoreEventTaskCreated, TaskID: task.ID, Task: copyTask(task)})
+	return nil
+}
+
+// UpdateTask 更新任务
+func (s *MemoryStore) UpdateTask(task *types.Task) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.tasks[task.ID]; !exists {
+		return fmt.Errorf("task %s: %w", task.ID, types.ErrTaskNotFound)
+	}
+	s.putTask(task)
+	s.notify(types.StoreEvent{Type: types.StoreEventTaskUpdated, TaskID: task.ID, Task: copyTask(task)})
+	return nil
+}
+
+// DeleteTask 删除任务，运行记录默认保留（见 WithPurgeRunsOnDelete）
+func (s *MemoryStore) DeleteTask(taskID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.tasks[taskID]; !exists {
+		return fmt.Errorf("task %s: %w", taskID, types.ErrTaskNotFound)
+	}
+	s.removeTask(taskID)
+	s.notify(types.StoreEvent{Type: types.StoreEventTaskDeleted, TaskID: taskID})
+	return nil
+}
+
+// GetTask 获取任务
+func (s *MemoryStore) GetTask(taskID string) (*types.Task, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	task, exists := s.tasks[taskID]
+	if !exists {
+		return nil, fmt.Errorf("task %s: %w", taskID, types.ErrTaskNotFound)
+	}
+	return copyTask(task), nil
+}
+
+// ListTasks 获取所有任务（按ID排序）
+func (s *MemoryStore) ListTasks() ([]*types.Task, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	tasks := make([]*types.Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, copyTask(task))
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks, nil
+}
+
+// CreateRun 创建运行记录
+func (s *MemoryStore) CreateRun(run *types.Run) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.runs[run.ID]; exists {
+		return fmt.Errorf("run %s already exists", run.ID)
+	}
+	s.putRun(run)
+	s.notify(types.StoreEvent{Type: types.StoreEventRunCreated, TaskID: run.TaskID, Run: copyRun(run)})
+	return nil
+}
+
+// UpdateRun 更新运行记录
+func (s *MemoryStore) UpdateRun(run *types.Run) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.runs[run.ID]; !exists {
+		return fmt.Errorf("run %s: %w", run.ID, types.ErrRunNotFound)
+	}
+	s.putRun(run)
+	s.notify(types.StoreEvent{Type: types.StoreEventRunUpdated, TaskID: run.TaskID, Run: copyRun(run)})
+	return nil
+}
+
+// GetRun 获取运行记录
+func (s *MemoryStore) GetRun(runID string) (*types.Run, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	run, exists := s.runs[runID]
+	if !exists {
+		return nil, fmt.Errorf("run %s: %w", runID, types.ErrRunNotFound)
+	}
+	return copyRun(run), nil
+}
+
+// ListRuns 获取任务的运行记录，taskID为空时返回所有运行记录（按触发时间排序）
+func (s *MemoryStore) ListRuns(taskID string) ([]*types.Run, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var runs []*types.Run
+	if taskID != "" {
+		for _, runID := range s.taskRuns[taskID] {
+			runs = append(runs, copyRun(s.runs[runID]))
+		}
+		return runs, nil
+	}
+
+	runs = make([]*types.Run, 0, len(s.runs))
+	for _, run := range s.runs {
+		runs = append(runs, copyRun(run))
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].FireTime.Equal(runs[j].FireTime) {
+			return runs[i].ID < runs[j].ID
+		}
+		return runs[i].FireTime.Before(runs[j].FireTime)
+	})
+	return runs, nil
+}
+
+// QueryRuns 按条件分页查询运行历史，结果按触发时间倒序排列
+func (s *MemoryStore) QueryRuns(query types.RunQuery) (*types.RunPage, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var candidates []*types.Run
+	if query.TaskID != "" {
+		for _, runID := range s.taskRuns[query.TaskID] {
+			candidates = append(candidates, s.runs[runID])
+		}
+	} else {
+		candidates = make([]*types.Run, 0, len(s.runs))
+		for _, run := range s.runs {
+			candidates = append(candidates, run)
+		}
+	}
+
+	matched := make([]*types.Run, 0, len(candidates))
+	for _, run := range candidates {
+		if matchRun(run, query) {
+			matched = append(matched, run)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].FireTime.Equal(matched[j].FireTime) {
+			return matched[i].ID > matched[j].ID
+		}
+		return matched[i].FireTime.After(matched[j].FireTime)
+	})
+
+	page := &types.RunPage{Total: len(matched), Runs: []*types.Run{}}
+	start := query.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start >= len(matched) {
+		return page, nil
+	}
+	end := len(matched)
+	if query.Limit > 0 && start+query.Limit < end {
+		end = start + query.Limit
+	}
+	for _, run := range matched[start:end] {
+		page.Runs = append(page.Runs, copyRun(run))
+	}
+	return page, nil
+}
+
+// matchRun 判断运行记录是否满足查询条件
+func matchRun(run *types.Run, query types.RunQuery) bool {
+	if query.TaskID != "" && run.TaskID != query.TaskID {
+		return false
+	}
+	if query.ExecutorID != "" && run.ExecutorID != query.ExecutorID {
+		return false
+	}
+	if len(query.Statuses) > 0 {
+		matched := false
+		for _, status := range query.Statuses {
+			if run.Status == status {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if !query.From.IsZero() && run.FireTime.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && !run.FireTime.Before(query.To) {
+		return false
+	}
+	return true
+}
+
+// Watch 订阅变更事件
+func (s *MemoryStore) Watch() (<-chan types.StoreEvent, func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := s.nextWatcher
+	s.nextWatcher++
+	ch := make(chan types.StoreEvent, watchBuffer)
+	s.watchers[id] = ch
+
+	cancel := func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		// 存储关闭时通道已被关闭，避免重复关闭
+		if _, exists := s.watchers[id]; exists {
+			delete(s.watchers, id)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
+
+// Close 关闭存储，结束所有订阅
+func (s *MemoryStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, ch := range s.watchers {
+		close(ch)
+		delete(s.watchers, id)
+	}
+	return nil
+}
+
+// putTask 写入任务副本（调用方持有写锁）
+func (s *MemoryStore) putTask(task *types.Task) {
+	s.tasks[task.ID] = copyTask(task)
+}
+
+// removeTask 删除任务，配置了 WithPurgeRunsOnDelete 时同时删除其运行记录（调用方持有写锁）
+func (s *MemoryStore) removeTask(taskID string) {
+	delete(s.tasks, taskID)
+	if !s.opts.purgeRuns {
+		return
+	}
+	for _, runID := range s.taskRuns[taskID] {
+		delete(s.runs, runID)
+	}
+	delete(s.taskRuns, taskID)
+}
+
+// putRun 写入运行记录副本，新增记录时按保留策略裁剪历史（调用方持有写锁）
+func (s *MemoryStore) putRun(run *types.Run) {
+	_, exists := s.runs[run.ID]
+	s.runs[run.ID] = copyRun(run)
+	if exists {
+		return
+	}
+
+	s.taskRuns[run.TaskID] = append(s.taskRuns[run.TaskID], run.ID)
+	now := s.now()
+	s.pruneTask(run.TaskID, now)
+	if now.Sub(s.lastSweep) >= ageSweepInterval {
+		s.lastSweep = now
+		for taskID := range s.taskRuns {
+			s.pruneTask(taskID, now)
+		}
+	}
+}
+
+// pruneTask 按保留策略裁剪任务的运行历史（调用方持有写锁）
+// 只裁剪已结束的运行，避免在途运行的记录被提前删除
+func (s *MemoryStore) pruneTask(taskID string, now time.Time) {
+	retention := s.opts.retentionFor(taskID)
+	if retention.MaxRunsPerTask <= 0 && retention.MaxAge <= 0 {
+		return
+	}
+
+	runIDs := s.taskRuns[taskID]
+	excess := 0
+	if retention.MaxRunsPerTask > 0 && len(runIDs) > retention.MaxRunsPerTask {
+		excess = len(runIDs) - retention.MaxRunsPerTask
+	}
+
+	kept := runIDs[:0]
+	for _, runID := range runIDs {
+		run := s.runs[runID]
+		finished := run.Status.Finished()
+		expired := retention.MaxAge > 0 && now.Sub(run.FireTime) > retention.MaxAge
+		if finished && (excess > 0 || expired) {
+			delete(s.runs, runID)
+			if excess > 0 {
+				excess--
+			}
+			continue
+		}
+		kept = append(kept, runID)
+	}
+
+	if len(kept) == 0 {
+		delete(s.taskRuns, taskID)
+		return
+	}
+	s.taskRuns[taskID] = kept
+}
+
+// notify 向所有订阅者广播事件（调用方持有写锁）
+func (s *MemoryStore) notify(event types.StoreEvent) {
+	for _, ch := range s.watchers {
+		select {
+		case ch <- event:
+		default:
+			// 订阅者处理过慢，丢弃事件
+		}
+	}
+}
+
+// copyTask 复制任务
+func copyTask(task *types.Task) *types.Task {
+	copied := *task
+	return &copied
+}
+
+// copyRun 复制运行记录
+func copyRun(run *types.Run) *types.Run {
+	copied := *run
+	return &copied
+}
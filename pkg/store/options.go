@@ -0,0 +1,87 @@
+package store
+
+import "time"
+
+const (
+	// DefaultSnapshotEvery 默认每追加多少条日志生成一次快照
+	DefaultSnapshotEvery = 1000
+	// DefaultMaxRunsPerTask 默认每个任务保留的运行记录数
+	DefaultMaxRunsPerTask = 1000
+)
+
+// Retention 运行历史保留策略，零值字段表示不限制
+type Retention struct {
+	MaxRunsPerTask int           `json:"max_runs_per_task"`
+	MaxAge         time.Duration `json:"max_age"`
+}
+
+// Option 存储配置项
+type Option func(*options)
+
+// options 存储配置
+type options struct {
+	snapshotEvery int
+	retention     Retention
+	taskRetention map[string]Retention // taskID -> 单独配置的保留策略
+	syncWrites    bool                 // 每次追加日志后是否fsync
+	purgeRuns     bool                 // 删除任务时是否同时删除其运行历史
+}
+
+// newOptions 应用配置项
+func newOptions(opts []Option) options {
+	o := options{
+		snapshotEvery: DefaultSnapshotEvery,
+		retention:     Retention{MaxRunsPerTask: DefaultMaxRunsPerTask},
+		taskRetention: make(map[string]Retention),
+		syncWrites:    true,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// retentionFor 获取任务的保留策略
+func (o *options) retentionFor(taskID string) Retention {
+	if retention, exists := o.taskRetention[taskID]; exists {
+		return retention
+	}
+	return o.retention
+}
+
+// WithSnapshotEvery 设置每追加多少条日志生成一次快照
+func WithSnapshotEvery(n int) Option {
+	return func(o *options) {
+		o.snapshotEvery = n
+	}
+}
+
+// WithRetention 设置默认的运行历史保留策略
+func WithRetention(retention Retention) Option {
+	return func(o *options) {
+		o.retention = retention
+	}
+}
+
+// WithTaskRetention 为单个任务设置运行历史保留策略
+func WithTaskRetention(taskID string, retention Retention) Option {
+	return func(o *options) {
+		o.taskRetention[taskID] = retention
+	}
+}
+
+// WithSyncWrites 设置每次追加日志后是否fsync，默认开启
+// 关闭后写入更快，但机器掉电时可能丢失最近确认的变更（进程崩溃不受影响）
+func WithSyncWrites(sync bool) Option {
+	return func(o *options) {
+		o.syncWrites = sync
+	}
+}
+
+// WithPurgeRunsOnDelete 设置删除任务时是否同时删除其运行历史
+// 默认保留，已删除任务的运行历史仍按保留策略裁剪
+func WithPurgeRunsOnDelete(purge bool) Option {
+	return func(o *options) {
+		o.purgeRuns = purge
+	}
+}
@@ -0,0 +1,308 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+// 创建测试任务
+func createTestTask(id string) *types.Task {
+	return &types.Task{
+		ID:       id,
+		Name:     "Test Task",
+		Cron:     "0 */1 * * * *",
+		Handler:  "testHandler",
+		Strategy: types.LFU,
+	}
+}
+
+// 创建测试运行记录
+func createTestRun(id, taskID string, fireTime time.Time) *types.Run {
+	return &types.Run{
+		ID:         id,
+		TaskID:     taskID,
+		ExecutorID: "exec-1",
+		Status:     types.RunStatusSucceeded,
+		FireTime:   fireTime,
+	}
+}
+
+func TestMemoryStoreTasks(t *testing.T) {
+	s := NewMemoryStore()
+	task := createTestTask("task-1")
+
+	if err := s.CreateTask(task); err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if err := s.CreateTask(task); !errors.Is(err, types.ErrTaskExists) {
+		t.Errorf("expected ErrTaskExists, got %v", err)
+	}
+
+	// 存储保存的是副本，调用方修改不影响存储内容
+	task.Name = "changed"
+	stored, err := s.GetTask("task-1")
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if stored.Name != "Test Task" {
+		t.Errorf("store should keep its own copy, got name %q", stored.Name)
+	}
+
+	if err := s.UpdateTask(task); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	if err := s.UpdateTask(createTestTask("missing")); !errors.Is(err, types.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+
+	s.CreateRun(createTestRun("run-1", "task-1", time.Now()))
+	if err := s.DeleteTask("task-1"); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+	if _, err := s.GetRun("run-1"); err != nil {
+		t.Errorf("runs should outlive their task by default, got %v", err)
+	}
+	if tasks, _ := s.ListTasks(); len(tasks) != 0 {
+		t.Errorf("expected no tasks, got %d", len(tasks))
+	}
+
+	purging := NewMemoryStore(WithPurgeRunsOnDelete(true))
+	purging.CreateTask(createTestTask("task-1"))
+	purging.CreateRun(createTestRun("run-1", "task-1", time.Now()))
+	purging.DeleteTask("task-1")
+	if _, err := purging.GetRun("run-1"); !errors.Is(err, types.ErrRunNotFound) {
+		t.Errorf("runs should be deleted together with their task when purging, got %v", err)
+	}
+}
+
+func TestMemoryStoreWatch(t *testing.T) {
+	s := NewMemoryStore()
+	events, cancel := s.Watch()
+
+	s.CreateTask(createTestTask("task-1"))
+	s.CreateRun(createTestRun("run-1", "task-1", time.Now()))
+	s.DeleteTask("task-1")
+
+	expected := []types.StoreEventType{
+		types.StoreEventTaskCreated,
+		types.StoreEventRunCreated,
+		types.StoreEventTaskDeleted,
+	}
+	for _, want := range expected {
+		select {
+		case event := <-events:
+			if event.Type != want || event.TaskID != "task-1" {
+				t.Errorf("unexpected event %+v, want type %v", event, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %v", want)
+		}
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("event channel should be closed after cancel")
+	}
+	// 重复取消和关闭存储都应当是安全的
+	cancel()
+	s.Close()
+}
+
+func TestFileStoreRecoversAfterReopen(t *testing.T) {
+	dir := t.TempDir()
+	fireTime := time.Now().Truncate(time.Second)
+
+	s, err := NewFileStore(dir, WithSnapshotEvery(3))
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	s.CreateTask(createTestTask("task-1"))
+	s.CreateTask(createTestTask("task-2"))
+	s.CreateRun(createTestRun("run-1", "task-1", fireTime)) // 触发快照
+	updated := createTestTask("task-2")
+	updated.Name = "Updated"
+	s.UpdateTask(updated)
+	s.DeleteTask("task-1")
+	s.CreateTask(createTestTask("task-3"))
+
+	// 不调用Close模拟进程崩溃：状态由快照加日志重放恢复，目录锁随进程退出释放
+	s.lockFile.Close()
+	reopened, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	tasks, _ := reopened.ListTasks()
+	if len(tasks) != 2 || tasks[0].ID != "task-2" || tasks[1].ID != "task-3" {
+		t.Fatalf("unexpected tasks after recovery: %+v", tasks)
+	}
+	if tasks[0].Name != "Updated" {
+		t.Errorf("update should be replayed, got name %q", tasks[0].Name)
+	}
+	if _, err := reopened.GetRun("run-1"); err != nil {
+		t.Errorf("run history of a deleted task should be recovered, got %v", err)
+	}
+}
+
+func TestFileStoreTruncatesTornRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	s.CreateTask(createTestTask("task-1"))
+	s.logFile.Close()
+	s.lockFile.Close()
+
+	// 模拟写到一半时崩溃
+	logPath := filepath.Join(dir, logFileName)
+	file, _ := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0o644)
+	file.WriteString(`{"op":"task_put","task":{"id":"task-2"`)
+	file.Close()
+
+	reopened, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	tasks, _ := reopened.ListTasks()
+	if len(tasks) != 1 || tasks[0].ID != "task-1" {
+		t.Fatalf("expected only the complete record to survive, got %+v", tasks)
+	}
+
+	// 截断后可以继续追加
+	if err := reopened.CreateTask(createTestTask("task-2")); err != nil {
+		t.Fatalf("CreateTask after recovery failed: %v", err)
+	}
+	reopened.Close()
+
+	final, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer final.Close()
+	if tasks, _ := final.ListTasks(); len(tasks) != 2 {
+		t.Errorf("expected 2 tasks, got %d", len(tasks))
+	}
+}
+
+func TestQueryRuns(t *testing.T) {
+	s := NewMemoryStore()
+	base := time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)
+
+	// 连续三天凌晨2点的 data-sync 以及每小时的 cache-cleanup
+	for day := 0; day < 3; day++ {
+		run := createTestRun(fmt.Sprintf("sync-%d", day), "data-sync", base.AddDate(0, 0, day).Add(2*time.Hour))
+		run.ExecutorID = fmt.Sprintf("exec-%d", day%2+1)
+		if day == 1 {
+			run.Status = types.RunStatusFailed
+		}
+		s.CreateRun(run)
+	}
+	for hour := 0; hour < 24; hour++ {
+		s.CreateRun(createTestRun(fmt.Sprintf("cleanup-%d", hour), "cache-cleanup", base.Add(time.Duration(hour)*time.Hour)))
+	}
+
+	// 昨晚02:00的 data-sync 是否运行、在哪个执行器上
+	page, err := s.QueryRuns(types.RunQuery{
+		TaskID: "data-sync",
+		From:   base.AddDate(0, 0, 2),
+		To:     base.AddDate(0, 0, 2).Add(3 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("QueryRuns failed: %v", err)
+	}
+	if page.Total != 1 || page.Runs[0].ID != "sync-2" || page.Runs[0].ExecutorID != "exec-1" {
+		t.Fatalf("unexpected result: %+v", page)
+	}
+
+	page, _ = s.QueryRuns(types.RunQuery{Statuses: []types.RunStatus{types.RunStatusFailed}})
+	if page.Total != 1 || page.Runs[0].ID != "sync-1" {
+		t.Errorf("status filter failed: %+v", page)
+	}
+	page, _ = s.QueryRuns(types.RunQuery{ExecutorID: "exec-2"})
+	if page.Total != 1 {
+		t.Errorf("executor filter failed: %+v", page)
+	}
+
+	// 分页按触发时间倒序
+	page, _ = s.QueryRuns(types.RunQuery{TaskID: "cache-cleanup", Offset: 5, Limit: 10})
+	if page.Total != 24 || len(page.Runs) != 10 {
+		t.Fatalf("unexpected page size: total=%d len=%d", page.Total, len(page.Runs))
+	}
+	if page.Runs[0].ID != "cleanup-18" || page.Runs[9].ID != "cleanup-9" {
+		t.Errorf("unexpected page order: first=%s last=%s", page.Runs[0].ID, page.Runs[9].ID)
+	}
+	page, _ = s.QueryRuns(types.RunQuery{TaskID: "cache-cleanup", Offset: 30})
+	if page.Total != 24 || len(page.Runs) != 0 {
+		t.Errorf("offset beyond total should return an empty page: %+v", page)
+	}
+}
+
+func TestRunRetention(t *testing.T) {
+	s := NewMemoryStore(
+		WithRetention(Retention{MaxRunsPerTask: 5}),
+		WithTaskRetention("hourly", Retention{MaxAge: 24 * time.Hour}),
+	)
+	now := time.Now()
+
+	for i := 0; i < 20; i++ {
+		s.CreateRun(createTestRun(fmt.Sprintf("minutely-%d", i), "minutely", now.Add(time.Duration(i)*time.Minute)))
+	}
+	// 在途运行不会因数量限制被裁剪
+	running := createTestRun("running", "minutely", now.Add(-time.Hour))
+	running.Status = types.RunStatusRunning
+	s.CreateRun(running)
+	s.CreateRun(createTestRun("minutely-20", "minutely", now.Add(20*time.Minute)))
+
+	runs, _ := s.ListRuns("minutely")
+	if len(runs) != 5 {
+		t.Fatalf("expected 5 runs to be kept, got %d", len(runs))
+	}
+	if _, err := s.GetRun("running"); err != nil {
+		t.Errorf("running run should be kept: %v", err)
+	}
+	if _, err := s.GetRun("minutely-16"); err == nil {
+		t.Error("oldest finished runs should be pruned")
+	}
+	if _, err := s.GetRun("minutely-17"); err != nil {
+		t.Errorf("newest finished runs should be kept: %v", err)
+	}
+
+	// 单独配置按时长保留的任务
+	s.CreateRun(createTestRun("hourly-old", "hourly", now.Add(-48*time.Hour)))
+	s.CreateRun(createTestRun("hourly-new", "hourly", now))
+	runs, _ = s.ListRuns("hourly")
+	if len(runs) != 1 || runs[0].ID != "hourly-new" {
+		t.Errorf("runs older than max age should be pruned, got %+v", runs)
+	}
+}
+
+func TestFileStoreRunHistorySurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileStore(dir, WithRetention(Retention{MaxRunsPerTask: 3}))
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		s.CreateRun(createTestRun(fmt.Sprintf("run-%d", i), "task-1", time.Now().Add(time.Duration(i)*time.Second)))
+	}
+	s.Close()
+
+	reopened, err := NewFileStore(dir, WithRetention(Retention{MaxRunsPerTask: 3}))
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	page, _ := reopened.QueryRuns(types.RunQuery{TaskID: "task-1"})
+	if page.Total != 3 || page.Runs[0].ID != "run-4" {
+		t.Errorf("expected the 3 newest runs to survive, got %+v", page.Runs)
+	}
+}
@@ -0,0 +1,207 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StdoutExporter 每个跨度输出一行 JSON
+type StdoutExporter struct {
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+// NewStdoutExporter 创建 JSON 行导出器，w 通常为 os.Stdout
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{writer: w}
+}
+
+// Export 输出跨度
+func (e *StdoutExporter) Export(ctx context.Context, spans []*Span) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	encoder := json.NewEncoder(e.writer)
+	for _, span := range spans {
+		if err := encoder.Encode(span); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OTLPExporter 通过 OTLP/HTTP（JSON 编码）导出跨度
+type OTLPExporter struct {
+	endpoint    string
+	serviceName string
+	headers     map[string]string
+	client      *http.Client
+}
+
+// OTLPOption OTLP 导出器配置项
+type OTLPOption func(*OTLPExporter)
+
+// WithServiceName 设置 service.name 资源属性
+func WithServiceName(name string) OTLPOption {
+	return func(e *OTLPExporter) {
+		e.serviceName = name
+	}
+}
+
+// WithHeader 为每个导出请求添加请求头（如鉴权）
+func WithHeader(key, value string) OTLPOption {
+	return func(e *OTLPExporter) {
+		e.headers[key] = value
+	}
+}
+
+// WithHTTPClient 使用自定义的 HTTP 客户端
+func WithHTTPClient(client *http.Client) OTLPOption {
+	return func(e *OTLPExporter) {
+		e.client = client
+	}
+}
+
+// NewOTLPExporter 创建 OTLP/HTTP 导出器
+// endpoint 为收集器地址（如 http://localhost:4318），未包含路径时追加 /v1/traces
+func NewOTLPExporter(endpoint string, opts ...OTLPOption) *OTLPExporter {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+	e := &OTLPExporter{
+		endpoint:    endpoint,
+		serviceName: "task_scheduler",
+		headers:     make(map[string]string),
+		client:      http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Export 发送一个 ExportTraceServiceRequest
+func (e *OTLPExporter) Export(ctx context.Context, spans []*Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("otlp export to %s: %s: %s", e.endpoint, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// OTLP/HTTP JSON 编码的请求结构，字段名遵循 protobuf 的 JSON 映射
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpSpanKindInternal SPAN_KIND_INTERNAL
+const otlpSpanKindInternal = 1
+
+// request 构造导出请求
+func (e *OTLPExporter) request(spans []*Span) otlpRequest {
+	converted := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		converted = append(converted, otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            otlpStatus{Code: int(span.Status), Message: span.StatusMessage},
+		})
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttribute{{Key: "service.name", Value: otlpValue{StringValue: e.serviceName}}}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "task_scheduler/pkg/tracing"},
+			Spans: converted,
+		}},
+	}}}
+}
+
+// otlpAttributes 按键排序转换属性
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	converted := make([]otlpAttribute, 0, len(keys))
+	for _, key := range keys {
+		converted = append(converted, otlpAttribute{Key: key, Value: otlpValue{StringValue: attributes[key]}})
+	}
+	return converted
+}
@@ -0,0 +1,394 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID W3C trace-id
+type TraceID [16]byte
+
+// SpanID W3C parent-id / span-id
+type SpanID [8]byte
+
+// String 十六进制表示
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// IsValid 全零的ID无效
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// String 十六进制表示
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsValid 全零的ID无效
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// SpanContext 跨进程传递的追踪上下文
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid 追踪ID和跨度ID都有效
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent 按 W3C Trace Context 格式编码，无效时返回空字符串
+func (sc SpanContext) Traceparent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent 解析 W3C traceparent 头
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, fmt.Errorf("invalid traceparent %q", value)
+	}
+	traceID, err1 := hex.DecodeString(parts[1])
+	spanID, err2 := hex.DecodeString(parts[2])
+	flags, err3 := hex.DecodeString(parts[3])
+	if err1 != nil || err2 != nil || err3 != nil || len(traceID) != 16 || len(spanID) != 8 || len(flags) != 1 {
+		return sc, fmt.Errorf("invalid traceparent %q", value)
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Sampled = flags[0]&0x01 == 1
+	if !sc.IsValid() {
+		return sc, fmt.Errorf("invalid traceparent %q: zero id", value)
+	}
+	return sc, nil
+}
+
+// SpanStatus 跨度的结束状态
+type SpanStatus int
+
+const (
+	// StatusUnset 未设置
+	StatusUnset SpanStatus = iota
+	// StatusOK 成功
+	StatusOK
+	// StatusError 失败
+	StatusError
+)
+
+// Span 一段被追踪的操作，导出后不再修改
+// nil *Span 的所有方法都是空操作，未配置追踪时调用方无需判断
+type Span struct {
+	Name          string            `json:"name"`
+	TraceID       string            `json:"trace_id"`
+	SpanID        string            `json:"span_id"`
+	ParentSpanID  string            `json:"parent_span_id,omitempty"`
+	StartTime     time.Time         `json:"start_time"`
+	EndTime       time.Time         `json:"end_time"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	Status        SpanStatus        `json:"status"`
+	StatusMessage string            `json:"status_message,omitempty"`
+
+	context SpanContext
+	tracer  *Tracer
+	ended   bool
+	mutex   sync.Mutex
+}
+
+// SpanContext 返回跨度的追踪上下文
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// SetAttribute 设置属性
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.ended {
+		return
+	}
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]string)
+	}
+	s.Attributes[key] = value
+}
+
+// SetStatus 设置结束状态，err 非空时为失败
+func (s *Span) SetStatus(err error) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.ended {
+		return
+	}
+	if err != nil {
+		s.Status = StatusError
+		s.StatusMessage = err.Error()
+	} else {
+		s.Status = StatusOK
+	}
+}
+
+// End 结束跨度并交给导出器
+func (s *Span) End() {
+	s.EndAt(time.Now())
+}
+
+// EndAt 以指定时间结束跨度，重复调用无效
+func (s *Span) EndAt(end time.Time) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = end
+	s.mutex.Unlock()
+	s.tracer.enqueue(s)
+}
+
+// spanKey 上下文中保存当前跨度的键
+type spanKey struct{}
+
+// ContextWithSpan 返回携带跨度的上下文
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext 返回上下文中的当前跨度，没有时返回nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// remoteKey 上下文中保存远程父跨度的键
+type remoteKey struct{}
+
+// ContextWithRemoteParent 返回以远程追踪上下文为父跨度的上下文，用于执行器端接续追踪
+func ContextWithRemoteParent(ctx context.Context, parent SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, parent)
+}
+
+// Exporter 跨度导出器
+type Exporter interface {
+	Export(ctx context.Context, spans []*Span) error
+}
+
+// StartOption 创建跨度的配置项
+type StartOption func(*Span)
+
+// WithStartTime 指定开始时间
+func WithStartTime(start time.Time) StartOption {
+	return func(s *Span) {
+		s.StartTime = start
+	}
+}
+
+// WithAttributes 设置初始属性，参数为键值交替
+func WithAttributes(keyValues ...string) StartOption {
+	return func(s *Span) {
+		if s.Attributes == nil {
+			s.Attributes = make(map[string]string)
+		}
+		for i := 0; i+1 < len(keyValues); i += 2 {
+			s.Attributes[keyValues[i]] = keyValues[i+1]
+		}
+	}
+}
+
+// Option 追踪器配置项
+type Option func(*Tracer)
+
+// WithBatchSize 每批导出的最大跨度数
+func WithBatchSize(size int) Option {
+	return func(t *Tracer) {
+		if size > 0 {
+			t.batchSize = size
+		}
+	}
+}
+
+// WithFlushInterval 未攒满一批时的导出间隔
+func WithFlushInterval(interval time.Duration) Option {
+	return func(t *Tracer) {
+		if interval > 0 {
+			t.flushInterval = interval
+		}
+	}
+}
+
+const (
+	// defaultBatchSize 默认每批导出的跨度数
+	defaultBatchSize = 128
+	// defaultFlushInterval 默认导出间隔
+	defaultFlushInterval = 5 * time.Second
+	// queueSize 待导出队列长度，队列满时丢弃跨度，避免阻塞调度
+	queueSize = 2048
+)
+
+// Tracer 创建跨度并在后台批量导出
+// nil *Tracer 创建的跨度为nil，即关闭追踪
+type Tracer struct {
+	exporter      Exporter
+	batchSize     int
+	flushInterval time.Duration
+	queue         chan *Span
+	flush         chan chan struct{}
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+// NewTracer 创建追踪器并启动后台导出
+func NewTracer(exporter Exporter, opts ...Option) *Tracer {
+	t := &Tracer{
+		exporter:      exporter,
+		batchSize:     defaultBatchSize,
+		flushInterval: defaultFlushInterval,
+		queue:         make(chan *Span, queueSize),
+		flush:         make(chan chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	go t.run()
+	return t
+}
+
+// Start 创建跨度；ctx 中有当前跨度时作为其子跨度，有远程父跨度时接续远程追踪，否则开始新的追踪
+func (t *Tracer) Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{Name: name, StartTime: time.Now(), tracer: t}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.context.TraceID = parent.context.TraceID
+		span.ParentSpanID = parent.context.SpanID.String()
+	} else if remote, ok := ctx.Value(remoteKey{}).(SpanContext); ok && remote.IsValid() {
+		span.context.TraceID = remote.TraceID
+		span.ParentSpanID = remote.SpanID.String()
+	} else {
+		rand.Read(span.context.TraceID[:])
+	}
+	rand.Read(span.context.SpanID[:])
+	span.context.Sampled = true
+	span.TraceID = span.context.TraceID.String()
+	span.SpanID = span.context.SpanID.String()
+
+	for _, opt := range opts {
+		opt(span)
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// Flush 导出所有已结束的跨度
+func (t *Tracer) Flush() {
+	if t == nil {
+		return
+	}
+	done := make(chan struct{})
+	select {
+	case t.flush <- done:
+		<-done
+	case <-t.done:
+	}
+}
+
+// Shutdown 导出剩余跨度并停止后台导出，之后结束的跨度被丢弃
+func (t *Tracer) Shutdown() {
+	if t == nil {
+		return
+	}
+	t.Flush()
+	t.closeOnce.Do(func() { close(t.done) })
+}
+
+// enqueue 将结束的跨度放入待导出队列
+func (t *Tracer) enqueue(span *Span) {
+	select {
+	case <-t.done:
+		return
+	default:
+	}
+	select {
+	case t.queue <- span:
+	default:
+		log.Printf("Trace queue is full, dropping span %s", span.Name)
+	}
+}
+
+// run 后台批量导出
+func (t *Tracer) run() {
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, t.batchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := t.exporter.Export(ctx, batch); err != nil {
+			log.Printf("Failed to export %d spans: %v", len(batch), err)
+		}
+		cancel()
+		batch = make([]*Span, 0, t.batchSize)
+	}
+
+	for {
+		select {
+		case span := <-t.queue:
+			batch = append(batch, span)
+			if len(batch) >= t.batchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case done := <-t.flush:
+			// 取出队列中已有的跨度后导出
+			for drained := false; !drained; {
+				select {
+				case span := <-t.queue:
+					batch = append(batch, span)
+				default:
+					drained = true
+				}
+			}
+			export()
+			close(done)
+		case <-t.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceparent(t *testing.T) {
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(header)
+	if err != nil {
+		t.Fatalf("ParseTraceparent failed: %v", err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Errorf("unexpected span context: %+v", sc)
+	}
+	if sc.Traceparent() != header {
+		t.Errorf("round trip mismatch: %s", sc.Traceparent())
+	}
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-xyz-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceparent(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestSpanTree(t *testing.T) {
+	var out strings.Builder
+	tracer := NewTracer(NewStdoutExporter(&out))
+
+	ctx, root := tracer.Start(context.Background(), "root", WithAttributes("task.id", "report"))
+	_, child := tracer.Start(ctx, "child")
+	child.SetStatus(errors.New("boom"))
+	child.End()
+	root.End()
+	root.SetAttribute("ignored", "after end")
+	tracer.Shutdown()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 exported spans, got %d: %s", len(lines), out.String())
+	}
+	var exportedChild, exportedRoot Span
+	json.Unmarshal([]byte(lines[0]), &exportedChild)
+	json.Unmarshal([]byte(lines[1]), &exportedRoot)
+	if exportedChild.TraceID != exportedRoot.TraceID || exportedChild.ParentSpanID != exportedRoot.SpanID {
+		t.Errorf("child should belong to the root trace: %+v %+v", &exportedChild, &exportedRoot)
+	}
+	if exportedChild.Status != StatusError || exportedChild.StatusMessage != "boom" {
+		t.Errorf("unexpected child status: %+v", &exportedChild)
+	}
+	if exportedRoot.Attributes["task.id"] != "report" || exportedRoot.Attributes["ignored"] != "" {
+		t.Errorf("unexpected root attributes: %+v", exportedRoot.Attributes)
+	}
+
+	// 远程父跨度：执行器端接续调度器的追踪
+	remote := ContextWithRemoteParent(context.Background(), root.SpanContext())
+	_, continued := NewTracer(NewStdoutExporter(&strings.Builder{})).Start(remote, "worker")
+	if continued.TraceID != root.TraceID || continued.ParentSpanID != root.SpanID {
+		t.Errorf("remote parent not continued: %+v", continued)
+	}
+
+	// 未配置追踪器时为空操作
+	var disabled *Tracer
+	_, span := disabled.Start(context.Background(), "noop")
+	span.SetAttribute("k", "v")
+	span.End()
+	if span != nil || span.SpanContext().Traceparent() != "" {
+		t.Errorf("nil tracer should not create spans")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var received otlpRequest
+	var path, contentType, auth string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType, auth = r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer collector.Close()
+
+	tracer := NewTracer(NewOTLPExporter(collector.URL, WithServiceName("scheduler-test"), WithHeader("Authorization", "Bearer token")))
+	_, span := tracer.Start(context.Background(), "task.run", WithAttributes("task.id", "report"))
+	span.SetStatus(nil)
+	span.End()
+	tracer.Shutdown()
+
+	if path != "/v1/traces" || contentType != "application/json" || auth != "Bearer token" {
+		t.Fatalf("unexpected request: path=%s content-type=%s auth=%s", path, contentType, auth)
+	}
+	if len(received.ResourceSpans) != 1 || len(received.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected payload: %+v", received)
+	}
+	if attr := received.ResourceSpans[0].Resource.Attributes[0]; attr.Key != "service.name" || attr.Value.StringValue != "scheduler-test" {
+		t.Errorf("unexpected resource: %+v", attr)
+	}
+	spans := received.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 || spans[0].Name != "task.run" || spans[0].TraceID != span.TraceID || spans[0].Status.Code != int(StatusOK) {
+		t.Fatalf("unexpected spans: %+v", spans)
+	}
+	if len(spans[0].Attributes) != 1 || spans[0].Attributes[0].Value.StringValue != "report" {
+		t.Errorf("unexpected attributes: %+v", spans[0].Attributes)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer failing.Close()
+	err := NewOTLPExporter(failing.URL+"/v1/traces").Export(context.Background(), []*Span{span})
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("expected collector error, got %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package types
+
+import "errors"
+
+var (
+	// ErrInvalidTask 任务定义不合法
+	ErrInvalidTask = errors.New("invalid task")
+	// ErrInvalidBackfill 回填请求不合法（任务没有cron表达式、时间段为空或触发次数超过上限）
+	ErrInvalidBackfill = errors.New("invalid backfill")
+	// ErrTaskBusy 任务已达到并发上限或不再接受新的运行
+	ErrTaskBusy = errors.New("task is busy")
+	// ErrRunFinished 运行已经结束，无法取消
+	ErrRunFinished = errors.New("run already finished")
+	// ErrExecutorExists 执行器已存在
+	ErrExecutorExists = errors.New("executor already exists")
+	// ErrExecutorNotFound 执行器不存在
+	ErrExecutorNotFound = errors.New("executor not found")
+)
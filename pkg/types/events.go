@@ -0,0 +1,98 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType 调度器事件类型
+type EventType string
+
+const (
+	// EventTaskAdded 任务添加
+	EventTaskAdded EventType = "task_added"
+	// EventTaskUpdated 任务定义更新
+	EventTaskUpdated EventType = "task_updated"
+	// EventTaskRemoved 任务移除
+	EventTaskRemoved EventType = "task_removed"
+	// EventTaskPaused 任务暂停
+	EventTaskPaused EventType = "task_paused"
+	// EventTaskResumed 任务恢复
+	EventTaskResumed EventType = "task_resumed"
+	// EventRunScheduled 运行已路由到执行器，等待开始
+	EventRunScheduled EventType = "run_scheduled"
+	// EventRunStarted 运行开始执行
+	EventRunStarted EventType = "run_started"
+	// EventRunFinished 运行结束，包括成功、失败和取消
+	EventRunFinished EventType = "run_finished"
+	// EventExecutorJoined 执行器加入
+	EventExecutorJoined EventType = "executor_joined"
+	// EventExecutorRemoved 执行器移除
+	EventExecutorRemoved EventType = "executor_removed"
+	// EventExecutorDraining 执行器开始排空
+	EventExecutorDraining EventType = "executor_draining"
+	// EventExecutorUnhealthy 健康检查发现执行器不健康
+	EventExecutorUnhealthy EventType = "executor_unhealthy"
+	// EventExecutorRecovered 不健康的执行器恢复
+	EventExecutorRecovered EventType = "executor_recovered"
+)
+
+// EventTypes 所有事件类型
+var EventTypes = []EventType{
+	EventTaskAdded, EventTaskUpdated, EventTaskRemoved, EventTaskPaused, EventTaskResumed,
+	EventRunScheduled, EventRunStarted, EventRunFinished,
+	EventExecutorJoined, EventExecutorRemoved, EventExecutorDraining, EventExecutorUnhealthy, EventExecutorRecovered,
+}
+
+// ParseEventType 解析事件类型名称
+func ParseEventType(value string) (EventType, error) {
+	for _, eventType := range EventTypes {
+		if string(eventType) == value {
+			return eventType, nil
+		}
+	}
+	return "", fmt.Errorf("unknown event type %q", value)
+}
+
+// Event 调度器事件，任务和运行为发布时的快照
+type Event struct {
+	ID         uint64    `json:"id"` // 单调递增的序号
+	Type       EventType `json:"type"`
+	Time       time.Time `json:"time"`
+	TaskID     string    `json:"task_id,omitempty"`
+	ExecutorID string    `json:"executor_id,omitempty"`
+	Task       *Task     `json:"task,omitempty"`
+	Run        *Run      `json:"run,omitempty"`
+}
+
+// EventFilter 事件订阅条件，零值字段表示不过滤
+type EventFilter struct {
+	TaskIDs []string    // 只接收这些任务的事件，执行器事件不属于任何任务
+	Types   []EventType // 只接收这些类型的事件
+}
+
+// Match 事件是否满足订阅条件
+func (f EventFilter) Match(event *Event) bool {
+	if len(f.TaskIDs) > 0 && !containsString(f.TaskIDs, event.TaskID) {
+		return false
+	}
+	if len(f.Types) > 0 {
+		for _, eventType := range f.Types {
+			if eventType == event.Type {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// containsString 切片中是否包含字符串
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+)
+
+var strategyNames = map[RouteStrategy]string{
+	RoundRobinTask:    "round_robin_task",
+	RoundRobinApp:     "round_robin_app",
+	Random:            "random",
+	LFU:               "lfu",
+	LRU:               "lru",
+	LeastOutstanding:  "least_outstanding",
+	PowerOfTwoChoices: "power_of_two_choices",
+	LatencyAware:      "latency_aware",
+	GlobalLFU:         "global_lfu",
+	GlobalLRU:         "global_lru",
+}
+
+var taskStatusNames = map[TaskStatus]string{
+	TaskStatusPending:   "pending",
+	TaskStatusRunning:   "running",
+	TaskStatusCompleted: "completed",
+	TaskStatusFailed:    "failed",
+	TaskStatusStopped:   "stopped",
+	TaskStatusPaused:    "paused",
+}
+
+var runStatusNames = map[RunStatus]string{
+	RunStatusScheduled: "scheduled",
+	RunStatusRunning:   "running",
+	RunStatusSucceeded: "succeeded",
+	RunStatusFailed:    "failed",
+	RunStatusLost:      "lost",
+	RunStatusCancelled: "cancelled",
+}
+
+var misfirePolicyNames = map[MisfirePolicy]string{
+	MisfireIgnore:   "ignore",
+	MisfireFireOnce: "fire_once",
+	MisfireFireAll:  "fire_all",
+}
+
+// String 策略名称
+func (s RouteStrategy) String() string {
+	if name, ok := strategyNames[s]; ok {
+		return name
+	}
+	return strconv.Itoa(int(s))
+}
+
+// ParseRouteStrategy 解析策略名称或数值
+func ParseRouteStrategy(value string) (RouteStrategy, error) {
+	for strategy, name := range strategyNames {
+		if name == value {
+			return strategy, nil
+		}
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		if _, ok := strategyNames[RouteStrategy(n)]; ok {
+			return RouteStrategy(n), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown route strategy %q", value)
+}
+
+// Valid 是否为已定义的策略
+func (s RouteStrategy) Valid() bool {
+	_, ok := strategyNames[s]
+	return ok
+}
+
+// String 任务状态名称
+func (s TaskStatus) String() string {
+	if name, ok := taskStatusNames[s]; ok {
+		return name
+	}
+	return strconv.Itoa(int(s))
+}
+
+// String 运行状态名称
+func (s RunStatus) String() string {
+	if name, ok := runStatusNames[s]; ok {
+		return name
+	}
+	return strconv.Itoa(int(s))
+}
+
+// ParseRunStatus 解析运行状态名称或数值
+func ParseRunStatus(value string) (RunStatus, error) {
+	for status, name := range runStatusNames {
+		if name == value {
+			return status, nil
+		}
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		if _, ok := runStatusNames[RunStatus(n)]; ok {
+			return RunStatus(n), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown run status %q", value)
+}
+
+// String 补偿策略名称
+func (p MisfirePolicy) String() string {
+	if name, ok := misfirePolicyNames[p]; ok {
+		return name
+	}
+	return strconv.Itoa(int(p))
+}
+
+// Valid 是否为已定义的补偿策略
+func (p MisfirePolicy) Valid() bool {
+	_, ok := misfirePolicyNames[p]
+	return ok
+}
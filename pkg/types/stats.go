@@ -0,0 +1,89 @@
+package types
+
+import "time"
+
+// DefaultStatsWindows 默认的统计滚动窗口
+var DefaultStatsWindows = []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour}
+
+// TaskStatsReport 任务统计汇总
+type TaskStatsReport struct {
+	TotalTasks           int            `json:"total_tasks"`
+	StatusDistribution   map[string]int `json:"status_distribution"`   // 状态名称 -> 任务数
+	StrategyDistribution map[string]int `json:"strategy_distribution"` // 策略名称 -> 任务数
+	TotalExecutors       int            `json:"total_executors"`
+	AvailableExecutors   int            `json:"available_executors"` // 健康且未在排空的执行器数
+	Tasks                []*TaskStats   `json:"tasks"`
+}
+
+// TaskStats 单个任务的统计信息
+type TaskStats struct {
+	TaskID          string            `json:"task_id"`
+	Status          TaskStatus        `json:"status"`
+	Strategy        RouteStrategy     `json:"strategy"`
+	LastSuccessTime time.Time         `json:"last_success_time"`
+	LastFailureTime time.Time         `json:"last_failure_time"`
+	Windows         []TaskWindowStats `json:"windows"`
+}
+
+// TaskWindowStats 任务在一个滚动窗口内（按运行结束时间）的统计
+type TaskWindowStats struct {
+	Window    time.Duration `json:"window"`
+	Runs      int           `json:"runs"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`    // 包括超时和派发失败
+	TimedOut  int           `json:"timed_out"` // 执行返回 context.DeadlineExceeded 的运行
+	Cancelled int           `json:"cancelled"`
+	// SuccessRate 成功数 / (成功数 + 失败数)，没有结束的运行时为0
+	SuccessRate float64 `json:"success_rate"`
+	// 已执行运行的耗时分位数
+	P50 time.Duration `json:"p50"`
+	P95 time.Duration `json:"p95"`
+	P99 time.Duration `json:"p99"`
+}
+
+// ExecutorStats 执行器统计信息，包括不健康和排空中的执行器
+type ExecutorStats struct {
+	ID           string                `json:"id"`
+	Address      string                `json:"address"`
+	UsageCount   int64                 `json:"usage_count"`
+	LastUsedTime time.Time             `json:"last_used_time"`
+	IsHealthy    bool                  `json:"is_healthy"`
+	Draining     bool                  `json:"draining"`
+	InFlight     int64                 `json:"in_flight"`
+	Windows      []ExecutorWindowStats `json:"windows"`
+}
+
+// ExecutorWindowStats 执行器在一个滚动窗口内的统计
+type ExecutorWindowStats struct {
+	Window    time.Duration `json:"window"`
+	Runs      int           `json:"runs"`
+	Failed    int           `json:"failed"`
+	ErrorRate float64       `json:"error_rate"` // 失败数 / 运行数，取消的运行不计入
+	// LoadShare 该执行器的运行数占窗口内所有执行器运行数的比例
+	LoadShare float64       `json:"load_share"`
+	BusyTime  time.Duration `json:"busy_time"` // 执行耗时之和
+}
+
+// StrategyFairness 一个路由策略在一个滚动窗口内的负载均衡程度
+type StrategyFairness struct {
+	Strategy RouteStrategy `json:"strategy"`
+	Window   time.Duration `json:"window"`
+	Runs     int           `json:"runs"`
+	// RunsByExecutor 各执行器的运行数，包括窗口内没有分到运行的可用执行器
+	RunsByExecutor map[string]int  `json:"runs_by_executor"`
+	RunCount       FairnessMetrics `json:"run_count"` // 基于各执行器的运行数
+	BusyTime       FairnessMetrics `json:"busy_time"` // 基于各执行器的执行耗时（秒）
+}
+
+// FairnessMetrics 各执行器负载分布的离散程度，均为0表示完全均衡
+type FairnessMetrics struct {
+	Mean float64 `json:"mean"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	// CoefficientOfVariation 变异系数：总体标准差 / 均值
+	CoefficientOfVariation float64 `json:"coefficient_of_variation"`
+	// Gini 基尼系数，0为完全均衡，趋近1为集中在单个执行器
+	Gini float64 `json:"gini"`
+	// MaxMinRatio 最大值 / 最小值，最小值为0时为nil
+	MaxMinRatio *float64 `json:"max_min_ratio"`
+}
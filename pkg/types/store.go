@@ -0,0 +1,72 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTaskExists 任务已存在
+	ErrTaskExists = errors.New("task already exists")
+	// ErrTaskNotFound 任务不存在
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrRunNotFound 运行记录不存在
+	ErrRunNotFound = errors.New("run not found")
+)
+
+// StoreEventType 存储变更事件类型
+type StoreEventType int
+
+const (
+	StoreEventTaskCreated StoreEventType = iota
+	StoreEventTaskUpdated
+	StoreEventTaskDeleted
+	StoreEventRunCreated
+	StoreEventRunUpdated
+)
+
+// StoreEvent 存储变更事件
+type StoreEvent struct {
+	Type   StoreEventType `json:"type"`
+	TaskID string         `json:"task_id"`
+	Task   *Task          `json:"task,omitempty"`
+	Run    *Run           `json:"run,omitempty"`
+}
+
+// RunQuery 运行记录查询条件，零值字段表示不过滤
+type RunQuery struct {
+	TaskID     string      `json:"task_id,omitempty"`
+	ExecutorID string      `json:"executor_id,omitempty"`
+	Statuses   []RunStatus `json:"statuses,omitempty"`
+	From       time.Time   `json:"from,omitempty"` // 触发时间下界（包含）
+	To         time.Time   `json:"to,omitempty"`   // 触发时间上界（不包含）
+	Offset     int         `json:"offset,omitempty"`
+	Limit      int         `json:"limit,omitempty"` // 小于等于0表示不限制
+}
+
+// RunPage 运行记录分页结果，按触发时间倒序排列
+type RunPage struct {
+	Runs  []*Run `json:"runs"`
+	Total int    `json:"total"` // 分页前满足条件的总数
+}
+
+// TaskStore 任务存储接口
+// 实现需保证并发安全，读写的均为副本，调用方修改返回值不会影响存储内容
+type TaskStore interface {
+	CreateTask(task *Task) error
+	UpdateTask(task *Task) error
+	DeleteTask(taskID string) error
+	GetTask(taskID string) (*Task, error)
+	ListTasks() ([]*Task, error)
+
+	CreateRun(run *Run) error
+	UpdateRun(run *Run) error
+	GetRun(runID string) (*Run, error)
+	ListRuns(taskID string) ([]*Run, error)
+	// QueryRuns 按条件分页查询运行历史
+	QueryRuns(query RunQuery) (*RunPage, error)
+
+	// Watch 订阅变更事件，返回事件通道和取消订阅函数
+	Watch() (<-chan StoreEvent, func())
+	Close() error
+}
@@ -1,7 +1,8 @@
 package types
 
 import (
-	"sync"
+	"context"
+	"log/slog"
 	"time"
 )
 
@@ -19,6 +20,16 @@ const (
 	LFU
 	// LRU 最近最久未使用
 	LRU
+	// LeastOutstanding 最少在途运行
+	LeastOutstanding
+	// PowerOfTwoChoices 随机两选一（比较在途运行数）
+	PowerOfTwoChoices
+	// LatencyAware 延迟感知（EWMA耗时与错误率）
+	LatencyAware
+	// GlobalLFU 全局最少使用（基于执行器跨策略的使用次数）
+	GlobalLFU
+	// GlobalLRU 全局最久未使用（基于执行器跨策略的最后使用时间）
+	GlobalLRU
 )
 
 // Executor 执行器接口
@@ -32,6 +43,21 @@ type Executor interface {
 	IncrementUsage()
 }
 
+// RunExecutor 可以接收运行信息的执行器
+// 调度器优先调用 ExecuteRun，执行器可据此获得逻辑触发时间（补跑、回填时早于实际执行时间），
+// 并在运行被取消时通过ctx收到通知
+type RunExecutor interface {
+	Executor
+	ExecuteRun(ctx context.Context, task *Task, run *Run) error
+}
+
+// HealthProber 可以主动探测健康状态的执行器（如远程执行器）
+// 健康检查优先调用 Probe，探测结果同时更新 IsHealthy；未实现时以 IsHealthy 为准
+type HealthProber interface {
+	Executor
+	Probe(ctx context.Context) error
+}
+
 // Task 任务定义
 type Task struct {
 	ID          string        `json:"id"`
@@ -44,8 +70,31 @@ type Task struct {
 	LastRunTime time.Time     `json:"last_run_time"`
 	NextRunTime time.Time     `json:"next_run_time"`
 	Status      TaskStatus    `json:"status"`
+	// MisfirePolicy 调度器停机期间错过触发时的补偿策略
+	MisfirePolicy MisfirePolicy `json:"misfire_policy"`
+	// MaxMisfires MisfireFireAll 时最多补跑的次数，0使用默认值
+	MaxMisfires int `json:"max_misfires,omitempty"`
+	// MaxConcurrency 同一任务最多同时进行的运行数，0表示1（不与自身并发）
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
+	// Timeout 单次运行的超时时间，超时后取消执行并记为失败，0表示不限制
+	Timeout time.Duration `json:"timeout,omitempty"`
 }
 
+// MisfirePolicy 错过触发的补偿策略
+type MisfirePolicy int
+
+const (
+	// MisfireIgnore 忽略错过的触发，等待下一次正常触发
+	MisfireIgnore MisfirePolicy = iota
+	// MisfireFireOnce 启动后立即补跑一次（以最近一次错过的触发时间为准）
+	MisfireFireOnce
+	// MisfireFireAll 按顺序补跑每一次错过的触发，最多 MaxMisfires 次
+	MisfireFireAll
+)
+
+// DefaultMaxMisfires MisfireFireAll 默认最多补跑的次数
+const DefaultMaxMisfires = 24
+
 // TaskStatus 任务状态
 type TaskStatus int
 
@@ -55,12 +104,70 @@ const (
 	TaskStatusCompleted
 	TaskStatusFailed
 	TaskStatusStopped
+	// TaskStatusPaused 已暂停，不再按cron触发，可以手动触发
+	TaskStatusPaused
 )
 
+// RunStatus 运行状态
+type RunStatus int
+
+const (
+	RunStatusScheduled RunStatus = iota
+	RunStatusRunning
+	RunStatusSucceeded
+	RunStatusFailed
+	// RunStatusLost 调度器在运行结束前退出，运行结果未知，需要人工核对
+	RunStatusLost
+	// RunStatusCancelled 运行被取消
+	RunStatusCancelled
+)
+
+// Finished 运行是否已经结束
+func (s RunStatus) Finished() bool {
+	switch s {
+	case RunStatusSucceeded, RunStatusFailed, RunStatusLost, RunStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// RunTrigger 运行的触发来源
+type RunTrigger string
+
+const (
+	// RunTriggerCron cron按计划触发
+	RunTriggerCron RunTrigger = "cron"
+	// RunTriggerMisfire 补跑停机期间错过的触发
+	RunTriggerMisfire RunTrigger = "misfire"
+	// RunTriggerBackfill 回填历史时间段
+	RunTriggerBackfill RunTrigger = "backfill"
+	// RunTriggerManual 手动触发
+	RunTriggerManual RunTrigger = "manual"
+)
+
+// Run 任务的一次运行记录
+type Run struct {
+	ID         string        `json:"id"`
+	TaskID     string        `json:"task_id"`
+	ExecutorID string        `json:"executor_id"`
+	Strategy   RouteStrategy `json:"strategy"`
+	Attempt    int           `json:"attempt"`
+	Status     RunStatus     `json:"status"`
+	Trigger    RunTrigger    `json:"trigger,omitempty"`
+	FireTime   time.Time     `json:"fire_time"` // 逻辑触发时间
+	StartTime  time.Time     `json:"start_time"`
+	EndTime    time.Time     `json:"end_time"`
+	Error      string        `json:"error,omitempty"`
+}
+
 // Router 路由器接口
 type Router interface {
 	Route(task *Task, executors []Executor) (Executor, error)
 	GetStrategy() RouteStrategy
+	// OnTaskRemoved 任务移除时回调，用于释放该任务的路由状态
+	OnTaskRemoved(taskID string)
+	// OnExecutorRemoved 执行器移除时回调，用于释放该执行器的路由状态
+	OnExecutorRemoved(executorID string)
 }
 
 // Scheduler 调度器接口
@@ -75,19 +182,37 @@ type Scheduler interface {
 	GetExecutors() []Executor
 }
 
-// ExecutorStats 执行器统计信息
-type ExecutorStats struct {
-	ID           string    `json:"id"`
-	Address      string    `json:"address"`
-	UsageCount   int64     `json:"usage_count"`
-	LastUsedTime time.Time `json:"last_used_time"`
-	IsHealthy    bool      `json:"is_healthy"`
-	mutex        sync.RWMutex
-}
-
 // SchedulerConfig 调度器配置
 type SchedulerConfig struct {
 	MaxConcurrentTasks  int           `json:"max_concurrent_tasks"`
 	HealthCheckInterval time.Duration `json:"health_check_interval"`
 	DefaultStrategy     RouteStrategy `json:"default_strategy"`
+	// RouterStateTTL 任务级路由状态的空闲淘汰时间，0表示仅在移除任务时释放
+	RouterStateTTL time.Duration `json:"router_state_ttl"`
+	// RouterStatePath 路由器状态快照文件路径，为空时不持久化
+	RouterStatePath string `json:"router_state_path"`
+	// RouterStateSaveInterval 路由器状态定期保存间隔，0表示仅在Stop时保存
+	RouterStateSaveInterval time.Duration `json:"router_state_save_interval"`
+	// RouterGlobalLFUHalfLife 全局LFU路由器使用频率衰减的半衰期，0表示不衰减
+	RouterGlobalLFUHalfLife time.Duration `json:"router_global_lfu_half_life"`
+	// RouterSeed 随机化路由器的种子，0表示自动生成；使用日志中记录的种子可回放路由序列
+	RouterSeed int64 `json:"router_seed"`
+	// LatencyAlpha 延迟感知路由EWMA的平滑系数，0使用默认值
+	LatencyAlpha float64 `json:"latency_alpha"`
+	// LatencyExploration 延迟感知路由的探索概率，0使用默认值，小于0表示不探索
+	LatencyExploration float64 `json:"latency_exploration"`
+	// LatencyErrorPenalty 延迟感知路由的错误率惩罚（错误率为1时得分增加的耗时），0使用默认值
+	LatencyErrorPenalty time.Duration `json:"latency_error_penalty"`
+	// LatencyMaxErrorRate 错误率超过该值的执行器只在没有其他执行器时被选中，0使用默认值，小于0表示不设上限
+	LatencyMaxErrorRate float64 `json:"latency_max_error_rate"`
+	// Store 任务存储，为空时使用内存存储（重启后任务丢失）
+	Store TaskStore `json:"-"`
+	// WALPath 预写日志路径，为空时不记录；重启时据此恢复任务并标记丢失的运行
+	WALPath string `json:"wal_path"`
+	// WALSyncInterval 预写日志fsync批量间隔，0使用默认值
+	WALSyncInterval time.Duration `json:"wal_sync_interval"`
+	// StatsWindows 统计信息的滚动窗口，为空时使用 DefaultStatsWindows
+	StatsWindows []time.Duration `json:"stats_windows"`
+	// Logger 结构化日志记录器，为空时使用 slog.Default()；级别和输出格式由记录器决定（见 pkg/logging）
+	Logger *slog.Logger `json:"-"`
 }
@@ -0,0 +1,89 @@
+package wal
+
+import (
+	"sort"
+
+	"task_scheduler/pkg/types"
+)
+
+// State 由日志重放得到的调度器状态
+type State struct {
+	Seq     uint64                 // 已应用的最后一条记录序号
+	Tasks   map[string]*types.Task // 现存任务
+	Removed map[string]bool        // 上次压缩后被删除的任务
+	Runs    map[string]*types.Run  // 尚未结束的运行
+}
+
+// NewState 创建空状态
+func NewState() *State {
+	return &State{
+		Tasks:   make(map[string]*types.Task),
+		Removed: make(map[string]bool),
+		Runs:    make(map[string]*types.Run),
+	}
+}
+
+// Apply 应用一条记录
+func (s *State) Apply(record Record) {
+	if record.Seq > s.Seq {
+		s.Seq = record.Seq
+	}
+
+	switch record.Type {
+	case TaskAdded, TaskUpdated:
+		if record.Task != nil {
+			task := *record.Task
+			s.Tasks[task.ID] = &task
+			delete(s.Removed, task.ID)
+		}
+	case TaskRemoved:
+		delete(s.Tasks, record.TaskID)
+		s.Removed[record.TaskID] = true
+	case RunScheduled, RunDispatched:
+		if record.Run != nil {
+			run := *record.Run
+			s.Runs[run.ID] = &run
+		}
+	case RunFinished:
+		if record.Run != nil {
+			delete(s.Runs, record.Run.ID)
+		}
+	}
+}
+
+// InFlightRuns 获取日志中已调度或已派发但没有结束记录的运行（按触发时间排序）
+func (s *State) InFlightRuns() []*types.Run {
+	runs := make([]*types.Run, 0, len(s.Runs))
+	for _, run := range s.Runs {
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].FireTime.Before(runs[j].FireTime) })
+	return runs
+}
+
+// Records 将状态转换为最少的记录集合，用于压缩
+// 删除标记不再保留：压缩发生时删除已经同步到任务存储
+func (s *State) Records() []Record {
+	records := make([]Record, 0, len(s.Tasks)+len(s.Runs))
+	seq := uint64(0)
+
+	taskIDs := make([]string, 0, len(s.Tasks))
+	for taskID := range s.Tasks {
+		taskIDs = append(taskIDs, taskID)
+	}
+	sort.Strings(taskIDs)
+	for _, taskID := range taskIDs {
+		seq++
+		records = append(records, Record{Seq: seq, Type: TaskAdded, TaskID: taskID, Task: s.Tasks[taskID]})
+	}
+
+	for _, run := range s.InFlightRuns() {
+		seq++
+		recordType := RunScheduled
+		if run.ExecutorID != "" {
+			recordType = RunDispatched
+		}
+		records = append(records, Record{Seq: seq, Type: recordType, TaskID: run.TaskID, Run: run})
+	}
+	return records
+}
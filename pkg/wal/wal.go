@@ -0,0 +1,395 @@
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+const (
+	// DefaultSyncInterval 默认的fsync批量间隔
+	DefaultSyncInterval = 10 * time.Millisecond
+	// DefaultMaxBatch 默认单批最多合并的记录数，达到后立即fsync
+	DefaultMaxBatch = 128
+	// DefaultCompactEvery 默认每追加多少条记录自动压缩一次
+	DefaultCompactEvery = 10000
+)
+
+// ErrClosed 日志已关闭
+var ErrClosed = errors.New("write-ahead log is closed")
+
+// RecordType 状态变更类型
+type RecordType string
+
+const (
+	TaskAdded     RecordType = "task_added"
+	TaskUpdated   RecordType = "task_updated"
+	TaskRemoved   RecordType = "task_removed"
+	RunScheduled  RecordType = "run_scheduled"
+	RunDispatched RecordType = "run_dispatched"
+	RunFinished   RecordType = "run_finished"
+)
+
+// Record 一条状态变更记录
+type Record struct {
+	Seq    uint64      `json:"seq"`
+	Type   RecordType  `json:"type"`
+	Time   time.Time   `json:"time"`
+	TaskID string      `json:"task_id"`
+	Task   *types.Task `json:"task,omitempty"`
+	Run    *types.Run  `json:"run,omitempty"`
+}
+
+// Option 预写日志配置项
+type Option func(*Log)
+
+// WithSyncInterval 设置fsync批量间隔，间隔内的追加合并为一次fsync
+func WithSyncInterval(interval time.Duration) Option {
+	return func(l *Log) {
+		l.syncInterval = interval
+	}
+}
+
+// WithMaxBatch 设置单批最多合并的记录数
+func WithMaxBatch(n int) Option {
+	return func(l *Log) {
+		l.maxBatch = n
+	}
+}
+
+// WithCompactEvery 设置每追加多少条记录自动压缩一次，0表示不自动压缩
+func WithCompactEvery(n int) Option {
+	return func(l *Log) {
+		l.compactEvery = n
+	}
+}
+
+// Log 预写日志
+// Append在记录被fsync到磁盘后才返回；并发的追加按批合并，一次fsync确认整批记录
+type Log struct {
+	path         string
+	file         *os.File
+	writer       *bufio.Writer
+	seq          uint64
+	appended     int // 上次压缩后追加的记录数（打开时为已有记录数）
+	waiters      []chan error
+	syncInterval time.Duration
+	maxBatch     int
+	compactEvery int
+	flushCh      chan struct{}
+	done         chan struct{}
+	wg           sync.WaitGroup
+	closed       bool
+	mutex        sync.Mutex
+}
+
+// Open 打开（或创建）预写日志，返回前会截断崩溃留下的不完整记录
+func Open(path string, opts ...Option) (*Log, error) {
+	l := &Log{
+		path:         path,
+		syncInterval: DefaultSyncInterval,
+		maxBatch:     DefaultMaxBatch,
+		compactEvery: DefaultCompactEvery,
+		flushCh:      make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	records, validSize, err := readRecords(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 {
+		l.seq = records[len(records)-1].Seq
+	}
+	l.appended = len(records)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open write-ahead log %s: %v", path, err)
+	}
+	if err := file.Truncate(validSize); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate write-ahead log %s: %v", path, err)
+	}
+	if _, err := file.Seek(validSize, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek write-ahead log %s: %v", path, err)
+	}
+	l.file = file
+	l.writer = bufio.NewWriter(file)
+
+	l.wg.Add(1)
+	go l.syncLoop()
+	return l, nil
+}
+
+// Append 追加记录并等待其落盘
+func (l *Log) Append(record Record) error {
+	return l.AppendAsync(record)()
+}
+
+// AppendAsync 将记录写入缓冲后立即返回，返回的函数等待记录落盘
+// 记录的顺序在调用时确定，调用方可以在持有自己的锁时追加，解锁后再等待；返回的函数只能调用一次
+func (l *Log) AppendAsync(record Record) func() error {
+	failed := func(err error) func() error {
+		return func() error { return err }
+	}
+
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return failed(ErrClosed)
+	}
+
+	l.seq++
+	record.Seq = l.seq
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		l.mutex.Unlock()
+		return failed(fmt.Errorf("failed to encode wal record: %v", err))
+	}
+	data = append(data, '\n')
+	if _, err := l.writer.Write(data); err != nil {
+		l.mutex.Unlock()
+		return failed(fmt.Errorf("failed to write wal record: %v", err))
+	}
+
+	waiter := make(chan error, 1)
+	l.waiters = append(l.waiters, waiter)
+	l.appended++
+	if len(l.waiters) >= l.maxBatch {
+		l.signalFlush()
+	}
+	l.mutex.Unlock()
+
+	return func() error { return <-waiter }
+}
+
+// Replay 按顺序重放日志中的所有记录
+func (l *Log) Replay(fn func(record Record) error) error {
+	l.mutex.Lock()
+	err := l.flushLocked()
+	l.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	records, _, err := readRecords(l.path)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// State 重放日志得到的调度器状态
+func (l *Log) State() (*State, error) {
+	state := NewState()
+	if err := l.Replay(func(record Record) error {
+		state.Apply(record)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Compact 压缩日志：只保留现存任务和未结束的运行，丢弃已结束运行与已删除任务的历史
+func (l *Log) Compact() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+
+	state, err := l.stateLocked()
+	if err != nil {
+		return err
+	}
+	return l.compactLocked(state)
+}
+
+// compactLocked 将状态重写为新的日志文件（调用方持有锁）
+// 先写临时文件并fsync，再原子替换，保证任意时刻崩溃都能读到完整的日志
+func (l *Log) compactLocked(state *State) error {
+	tmpPath := l.path + ".compact"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted wal: %v", err)
+	}
+	writer := bufio.NewWriter(tmpFile)
+	compacted := state.Records()
+	for _, record := range compacted {
+		data, err := json.Marshal(record)
+		if err != nil {
+			tmpFile.Close()
+			return fmt.Errorf("failed to encode wal record: %v", err)
+		}
+		writer.Write(append(data, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write compacted wal: %v", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to sync compacted wal: %v", err)
+	}
+	tmpFile.Close()
+
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		return fmt.Errorf("failed to replace wal with compacted log: %v", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen compacted wal: %v", err)
+	}
+	l.file.Close()
+	l.file = file
+	l.writer = bufio.NewWriter(file)
+	l.appended = 0
+	return nil
+}
+
+// Close 落盘所有待写记录并关闭日志
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mutex.Unlock()
+
+	close(l.done)
+	l.wg.Wait()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	err := l.flushLocked()
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// signalFlush 通知后台协程立即落盘（调用方持有锁）
+func (l *Log) signalFlush() {
+	select {
+	case l.flushCh <- struct{}{}:
+	default:
+	}
+}
+
+// syncLoop 后台批量落盘
+func (l *Log) syncLoop() {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(l.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+		case <-l.flushCh:
+		}
+
+		l.mutex.Lock()
+		if len(l.waiters) > 0 {
+			l.flushLocked()
+		}
+		if l.compactEvery > 0 && l.appended >= l.compactEvery {
+			if state, err := l.stateLocked(); err != nil {
+				log.Printf("Failed to read write-ahead log for compaction: %v", err)
+			} else if err := l.compactLocked(state); err != nil {
+				log.Printf("Failed to compact write-ahead log: %v", err)
+			}
+		}
+		l.mutex.Unlock()
+	}
+}
+
+// stateLocked 读取当前日志文件构建状态（调用方持有锁）
+func (l *Log) stateLocked() (*State, error) {
+	if err := l.flushLocked(); err != nil {
+		return nil, err
+	}
+	records, _, err := readRecords(l.path)
+	if err != nil {
+		return nil, err
+	}
+	state := NewState()
+	for _, record := range records {
+		state.Apply(record)
+	}
+	return state, nil
+}
+
+// flushLocked 刷新缓冲并fsync，然后唤醒等待的追加者（调用方持有锁）
+func (l *Log) flushLocked() error {
+	err := l.writer.Flush()
+	if err == nil {
+		err = l.file.Sync()
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to sync write-ahead log: %v", err)
+	}
+	for _, waiter := range l.waiters {
+		waiter <- err
+	}
+	l.waiters = nil
+	return err
+}
+
+// readRecords 读取日志文件中的完整记录，返回记录和有效部分的字节数
+// 崩溃可能留下写了一半的最后一条记录，遇到无法解析的记录时停止读取
+func readRecords(path string) ([]Record, int64, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open write-ahead log %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var records []Record
+	var offset int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			break
+		}
+		var record Record
+		if err != nil || json.Unmarshal(bytes.TrimSpace(line), &record) != nil {
+			log.Printf("Write-ahead log %s has a torn record at offset %d, ignoring the tail", path, offset)
+			break
+		}
+		records = append(records, record)
+		offset += int64(len(line))
+	}
+	return records, offset, nil
+}
@@ -0,0 +1,187 @@
+package wal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"task_scheduler/pkg/types"
+)
+
+// 创建测试任务
+func createTestTask(id string) *types.Task {
+	return &types.Task{ID: id, Cron: "0 */1 * * * *", Handler: "testHandler"}
+}
+
+// 创建测试运行记录
+func createTestRun(id, taskID, executorID string) *types.Run {
+	return &types.Run{ID: id, TaskID: taskID, ExecutorID: executorID, FireTime: time.Now()}
+}
+
+func TestReplayRecoversState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scheduler.wal")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	records := []Record{
+		{Type: TaskAdded, TaskID: "task-1", Task: createTestTask("task-1")},
+		{Type: TaskAdded, TaskID: "task-2", Task: createTestTask("task-2")},
+		{Type: TaskRemoved, TaskID: "task-1"},
+		{Type: RunScheduled, TaskID: "task-2", Run: createTestRun("run-1", "task-2", "")},
+		{Type: RunDispatched, TaskID: "task-2", Run: createTestRun("run-1", "task-2", "exec-1")},
+		{Type: RunDispatched, TaskID: "task-2", Run: createTestRun("run-2", "task-2", "exec-2")},
+		{Type: RunFinished, TaskID: "task-2", Run: createTestRun("run-2", "task-2", "exec-2")},
+	}
+	for _, record := range records {
+		if err := l.Append(record); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	// 不调用Close模拟崩溃：Append返回时记录已经落盘
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	defer l.Close()
+
+	state, err := reopened.State()
+	if err != nil {
+		t.Fatalf("State failed: %v", err)
+	}
+	if state.Seq != uint64(len(records)) {
+		t.Errorf("expected seq %d, got %d", len(records), state.Seq)
+	}
+	if len(state.Tasks) != 1 || state.Tasks["task-2"] == nil {
+		t.Errorf("expected only task-2 to survive, got %+v", state.Tasks)
+	}
+	if !state.Removed["task-1"] {
+		t.Error("removal of task-1 should be recorded")
+	}
+	inFlight := state.InFlightRuns()
+	if len(inFlight) != 1 || inFlight[0].ID != "run-1" || inFlight[0].ExecutorID != "exec-1" {
+		t.Errorf("expected run-1 dispatched to exec-1 to be in flight, got %+v", inFlight)
+	}
+}
+
+func TestOpenTruncatesTornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scheduler.wal")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	l.Append(Record{Type: TaskAdded, TaskID: "task-1", Task: createTestTask("task-1")})
+	l.Close()
+
+	// 模拟写到一半时崩溃
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	file.WriteString(`{"seq":2,"type":"task_added","task_id":"task-2","task":{"id":`)
+	file.Close()
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if err := reopened.Append(Record{Type: TaskAdded, TaskID: "task-3", Task: createTestTask("task-3")}); err != nil {
+		t.Fatalf("Append after recovery failed: %v", err)
+	}
+	reopened.Close()
+
+	final, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer final.Close()
+	state, _ := final.State()
+	if len(state.Tasks) != 2 || state.Tasks["task-1"] == nil || state.Tasks["task-3"] == nil {
+		t.Errorf("expected task-1 and task-3, got %+v", state.Tasks)
+	}
+	if state.Seq != 2 {
+		t.Errorf("sequence should continue after the last complete record, got %d", state.Seq)
+	}
+}
+
+func TestCompactKeepsLiveState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scheduler.wal")
+	l, err := Open(path, WithCompactEvery(0), WithSyncInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer l.Close()
+
+	l.Append(Record{Type: TaskAdded, TaskID: "task-1", Task: createTestTask("task-1")})
+	for i := 0; i < 50; i++ {
+		run := createTestRun(fmt.Sprintf("run-%d", i), "task-1", "exec-1")
+		l.Append(Record{Type: RunDispatched, TaskID: "task-1", Run: run})
+		l.Append(Record{Type: RunFinished, TaskID: "task-1", Run: run})
+	}
+	l.Append(Record{Type: RunDispatched, TaskID: "task-1", Run: createTestRun("pending", "task-1", "exec-2")})
+
+	before, _ := os.Stat(path)
+	if err := l.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() >= before.Size() {
+		t.Errorf("compaction should shrink the log: before=%d after=%d", before.Size(), after.Size())
+	}
+
+	// 压缩后继续追加的记录同样可以重放
+	l.Append(Record{Type: TaskAdded, TaskID: "task-2", Task: createTestTask("task-2")})
+	state, err := l.State()
+	if err != nil {
+		t.Fatalf("State failed: %v", err)
+	}
+	if len(state.Tasks) != 2 {
+		t.Errorf("expected 2 tasks after compaction, got %d", len(state.Tasks))
+	}
+	if runs := state.InFlightRuns(); len(runs) != 1 || runs[0].ID != "pending" {
+		t.Errorf("only the unfinished run should survive compaction, got %+v", runs)
+	}
+}
+
+func TestConcurrentAppendsAreBatched(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scheduler.wal")
+	l, err := Open(path, WithSyncInterval(5*time.Millisecond), WithMaxBatch(16))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	const writers, perWriter = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				taskID := fmt.Sprintf("task-%d-%d", w, i)
+				if err := l.Append(Record{Type: TaskAdded, TaskID: taskID, Task: createTestTask(taskID)}); err != nil {
+					t.Errorf("Append failed: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	l.Close()
+
+	if err := l.Append(Record{Type: TaskRemoved, TaskID: "task-0-0"}); err != ErrClosed {
+		t.Errorf("expected ErrClosed after Close, got %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	state, _ := reopened.State()
+	if len(state.Tasks) != writers*perWriter || state.Seq != writers*perWriter {
+		t.Errorf("expected %d records, got %d tasks (seq %d)", writers*perWriter, len(state.Tasks), state.Seq)
+	}
+}
@@ -0,0 +1,387 @@
+// 运维仪表盘：任务、运行历史与日志、执行器；数据全部来自 /api/v1 管理接口
+(function () {
+  'use strict';
+
+  var API = new URL('../api/v1/', location.href).pathname;
+  var TASK_STATUS = ['pending', 'running', 'completed', 'failed', 'stopped', 'paused'];
+  var RUN_STATUS = ['scheduled', 'running', 'succeeded', 'failed', 'lost', 'cancelled'];
+  var PAUSED = 5;
+  var RUN_LIMIT = 50;
+  var REFRESH_INTERVAL = 15000;
+
+  var state = {
+    view: 'tasks',
+    overview: null,
+    window: null,
+    runOffset: 0,
+    runTotal: 0,
+    logs: null // 正在跟随日志的 AbortController
+  };
+
+  function $(id) { return document.getElementById(id); }
+
+  // el 创建元素，children 为字符串或节点
+  function el(tag, attrs, children) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (key) {
+      if (key === 'onclick') {
+        node.addEventListener('click', attrs[key]);
+      } else {
+        node.setAttribute(key, attrs[key]);
+      }
+    });
+    [].concat(children === undefined ? [] : children).forEach(function (child) {
+      node.appendChild(typeof child === 'string' ? document.createTextNode(child) : child);
+    });
+    return node;
+  }
+
+  function request(method, path) {
+    return fetch(API + path, { method: method, headers: { Accept: 'application/json' } }).then(function (resp) {
+      return resp.json().catch(function () { return {}; }).then(function (body) {
+        if (!resp.ok) {
+          throw new Error(body.error || resp.status + ' ' + resp.statusText);
+        }
+        return body;
+      });
+    });
+  }
+
+  function showMessage(text, info) {
+    var box = $('message');
+    box.textContent = text;
+    box.className = info ? 'message info' : 'message';
+    clearTimeout(showMessage.timer);
+    showMessage.timer = setTimeout(function () { box.className = 'message hidden'; }, 5000);
+  }
+
+  // 零值时间（0001-01-01）显示为 -
+  function formatTime(value) {
+    if (!value || value.indexOf('0001-01-01') === 0) {
+      return '-';
+    }
+    var date = new Date(value);
+    var pad = function (n) { return (n < 10 ? '0' : '') + n; };
+    return date.getFullYear() + '-' + pad(date.getMonth() + 1) + '-' + pad(date.getDate()) + ' ' +
+      pad(date.getHours()) + ':' + pad(date.getMinutes()) + ':' + pad(date.getSeconds());
+  }
+
+  function formatDuration(ms) {
+    if (ms < 1000) {
+      return Math.round(ms) + 'ms';
+    }
+    var seconds = ms / 1000;
+    if (seconds < 60) {
+      return seconds.toFixed(1) + 's';
+    }
+    var minutes = Math.floor(seconds / 60);
+    if (minutes < 60) {
+      return minutes + 'm' + Math.round(seconds % 60) + 's';
+    }
+    return Math.floor(minutes / 60) + 'h' + (minutes % 60) + 'm';
+  }
+
+  function runDuration(run) {
+    if (!run.start_time || run.start_time.indexOf('0001-01-01') === 0) {
+      return '-';
+    }
+    var end = run.end_time && run.end_time.indexOf('0001-01-01') !== 0 ? new Date(run.end_time) : new Date();
+    return formatDuration(end - new Date(run.start_time));
+  }
+
+  function badge(name) {
+    return el('span', { class: 'status ' + name }, name);
+  }
+
+  function button(label, onclick, disabled) {
+    var node = el('button', { type: 'button', onclick: onclick }, label);
+    node.disabled = !!disabled;
+    return node;
+  }
+
+  function replaceRows(tbody, rows, columns, empty) {
+    tbody.textContent = '';
+    if (rows.length === 0) {
+      tbody.appendChild(el('tr', {}, el('td', { colspan: String(columns), class: 'muted' }, empty)));
+      return;
+    }
+    rows.forEach(function (row) { tbody.appendChild(row); });
+  }
+
+  // 任务
+
+  function taskAction(task, action) {
+    request('POST', 'tasks/' + encodeURIComponent(task.id) + '/' + action).then(function (result) {
+      if (action === 'trigger') {
+        showMessage('已触发 ' + task.id + '，运行 ' + result.id, true);
+      }
+      refresh();
+    }).catch(function (err) { showMessage(task.id + ': ' + err.message); });
+  }
+
+  function renderTasks() {
+    var tasks = state.overview.tasks || [];
+    replaceRows($('tasks'), tasks.map(function (task) {
+      var paused = task.status === PAUSED;
+      var last = task.last_run;
+      var result = last
+        ? el('span', {}, [badge(RUN_STATUS[last.status]), ' ', el('a', { href: '#', onclick: function (e) { e.preventDefault(); openLogs(last); } }, formatTime(last.end_time))])
+        : '-';
+      return el('tr', {}, [
+        el('td', {}, [el('strong', {}, task.id), task.name && task.name !== task.id ? el('div', { class: 'muted' }, task.name) : '']),
+        el('td', {}, el('code', {}, task.cron)),
+        el('td', {}, badge(TASK_STATUS[task.status])),
+        el('td', {}, paused ? '-' : formatTime(task.next_run_time)),
+        el('td', {}, formatTime(task.last_run_time)),
+        el('td', { class: last && last.error ? 'error' : '' }, last && last.error ? [result, el('div', {}, last.error)] : result),
+        el('td', { class: 'actions' }, [
+          button('触发', function () { taskAction(task, 'trigger'); }),
+          ' ',
+          paused
+            ? button('恢复', function () { taskAction(task, 'resume'); })
+            : button('暂停', function () { taskAction(task, 'pause'); }),
+          ' ',
+          button('运行历史', function () { showRuns(task.id); })
+        ])
+      ]);
+    }), 7, '没有任务');
+
+    var select = $('run-filter').elements.task;
+    var selected = select.value;
+    select.length = 1;
+    tasks.forEach(function (task) { select.appendChild(el('option', { value: task.id }, task.id)); });
+    select.value = selected;
+  }
+
+  // 运行历史
+
+  function showRuns(taskID) {
+    $('run-filter').elements.task.value = taskID;
+    state.runOffset = 0;
+    if (location.hash === '#runs') {
+      switchView();
+    } else {
+      location.hash = '#runs';
+    }
+  }
+
+  function loadRuns() {
+    var form = $('run-filter').elements;
+    var params = new URLSearchParams({ offset: state.runOffset, limit: RUN_LIMIT });
+    if (form.task.value) {
+      params.set('task', form.task.value);
+    }
+    if (form.status.value) {
+      params.set('status', form.status.value);
+    }
+    return request('GET', 'runs?' + params).then(function (page) {
+      state.runTotal = page.total;
+      var runs = page.runs || [];
+      replaceRows($('runs'), runs.map(function (run) {
+        var status = RUN_STATUS[run.status];
+        var finished = ['succeeded', 'failed', 'lost', 'cancelled'].indexOf(status) >= 0;
+        return el('tr', {}, [
+          el('td', {}, el('code', {}, run.id)),
+          el('td', {}, run.task_id),
+          el('td', {}, run.trigger || '-'),
+          el('td', {}, badge(status)),
+          el('td', {}, run.executor_id || '-'),
+          el('td', {}, formatTime(run.fire_time)),
+          el('td', {}, runDuration(run)),
+          el('td', { class: 'error' }, run.error || ''),
+          el('td', { class: 'actions' }, [
+            button('日志', function () { openLogs(run); }),
+            ' ',
+            button('取消', function () { cancelRun(run); }, finished)
+          ])
+        ]);
+      }), 9, '没有运行记录');
+      var last = Math.min(state.runOffset + runs.length, page.total);
+      $('run-total').textContent = page.total ? (state.runOffset + 1) + '-' + last + ' / ' + page.total : '';
+      $('runs-prev').disabled = state.runOffset === 0;
+      $('runs-next').disabled = last >= page.total;
+    });
+  }
+
+  function cancelRun(run) {
+    if (!confirm('取消运行 ' + run.id + '？')) {
+      return;
+    }
+    request('POST', 'runs/' + encodeURIComponent(run.id) + '/cancel').then(refresh).catch(function (err) {
+      showMessage(run.id + ': ' + err.message);
+    });
+  }
+
+  // 日志：运行结束前持续跟随
+
+  function openLogs(run) {
+    closeLogs();
+    var controller = new AbortController();
+    state.logs = controller;
+    var body = $('logs-body');
+    body.textContent = '';
+    $('logs-title').textContent = run.task_id + ' / ' + run.id;
+    $('logs-state').textContent = '加载中…';
+    $('logs').className = 'drawer';
+
+    fetch(API + 'runs/' + encodeURIComponent(run.id) + '/logs?follow=true', { signal: controller.signal }).then(function (resp) {
+      if (!resp.ok) {
+        return resp.json().then(function (err) { throw new Error(err.error || resp.statusText); });
+      }
+      var dropped = Number(resp.headers.get('X-Run-Log-Dropped') || 0);
+      if (dropped > 0) {
+        body.appendChild(document.createTextNode('… 最早的 ' + dropped + ' 行已丢弃\n'));
+      }
+      $('logs-state').textContent = '跟随中';
+      var reader = resp.body.getReader();
+      var decoder = new TextDecoder();
+      var read = function () {
+        return reader.read().then(function (chunk) {
+          if (chunk.done) {
+            $('logs-state').textContent = '运行已结束';
+            return;
+          }
+          var follow = body.scrollTop + body.clientHeight >= body.scrollHeight - 4;
+          body.appendChild(document.createTextNode(decoder.decode(chunk.value, { stream: true })));
+          if (follow) {
+            body.scrollTop = body.scrollHeight;
+          }
+          return read();
+        });
+      };
+      return read();
+    }).catch(function (err) {
+      if (err.name !== 'AbortError') {
+        $('logs-state').textContent = err.message;
+      }
+    });
+  }
+
+  function closeLogs() {
+    if (state.logs) {
+      state.logs.abort();
+      state.logs = null;
+    }
+    $('logs').className = 'drawer hidden';
+  }
+
+  // 执行器
+
+  function formatWindow(ns) {
+    var minutes = ns / 6e10;
+    return minutes >= 60 ? (minutes / 60) + 'h' : minutes + 'm';
+  }
+
+  function renderExecutors() {
+    var executors = state.overview.executors || [];
+    var select = $('window');
+    var windows = executors.length ? executors[0].windows.map(function (w) { return w.window; }) : [];
+    if (select.length !== windows.length) {
+      select.textContent = '';
+      windows.forEach(function (w) { select.appendChild(el('option', { value: String(w) }, formatWindow(w))); });
+      select.value = String(state.window || windows[0]);
+    }
+    var selected = Number(select.value);
+
+    replaceRows($('executors'), executors.map(function (exec) {
+      var stats = exec.windows.filter(function (w) { return w.window === selected; })[0] || { runs: 0, error_rate: 0, load_share: 0 };
+      var share = Math.round(stats.load_share * 100);
+      return el('tr', {}, [
+        el('td', {}, el('strong', {}, exec.id)),
+        el('td', {}, el('code', {}, exec.address)),
+        el('td', {}, [badge(exec.is_healthy ? 'healthy' : 'unhealthy'), exec.draining ? ' ' : '', exec.draining ? badge('draining') : '']),
+        el('td', {}, String(exec.in_flight)),
+        el('td', {}, String(stats.runs)),
+        el('td', {}, stats.runs ? (stats.error_rate * 100).toFixed(1) + '%' : '-'),
+        el('td', {}, [el('span', { class: 'bar' }, el('span', { style: 'width:' + share + '%' })), share + '%']),
+        el('td', {}, formatTime(exec.last_used_time))
+      ]);
+    }), 8, '没有执行器');
+  }
+
+  // 刷新与视图切换
+
+  function render() {
+    if (!state.overview) {
+      return;
+    }
+    renderTasks();
+    renderExecutors();
+    var alerts = $('alerts');
+    alerts.textContent = state.overview.firing_alerts + ' 个告警';
+    alerts.className = state.overview.firing_alerts > 0 ? 'badge' : 'badge hidden';
+  }
+
+  function refresh() {
+    var pending = [request('GET', 'overview').then(function (overview) {
+      state.overview = overview;
+      render();
+    })];
+    if (state.view === 'runs') {
+      pending.push(loadRuns());
+    }
+    return Promise.all(pending).catch(function (err) { showMessage('刷新失败：' + err.message); });
+  }
+
+  // scheduleRefresh 合并短时间内的多个事件
+  function scheduleRefresh() {
+    if (!scheduleRefresh.timer) {
+      scheduleRefresh.timer = setTimeout(function () {
+        scheduleRefresh.timer = null;
+        refresh();
+      }, 500);
+    }
+  }
+
+  function switchView() {
+    var view = location.hash.replace('#', '') || 'tasks';
+    if (!$('view-' + view)) {
+      view = 'tasks';
+    }
+    state.view = view;
+    document.querySelectorAll('.view').forEach(function (section) {
+      section.classList.toggle('hidden', section.id !== 'view-' + view);
+    });
+    document.querySelectorAll('nav a').forEach(function (link) {
+      link.classList.toggle('active', link.getAttribute('data-view') === view);
+    });
+    if (view === 'runs') {
+      loadRuns().catch(function (err) { showMessage(err.message); });
+    }
+  }
+
+  function watchEvents() {
+    var source = new EventSource(API + 'events');
+    source.onopen = function () { $('live').className = 'live on'; };
+    source.onerror = function () { $('live').className = 'live'; };
+    ['task_added', 'task_updated', 'task_removed', 'task_paused', 'task_resumed',
+      'run_scheduled', 'run_started', 'run_finished',
+      'executor_joined', 'executor_removed', 'executor_draining', 'executor_unhealthy', 'executor_recovered'
+    ].forEach(function (type) { source.addEventListener(type, scheduleRefresh); });
+  }
+
+  $('run-filter').addEventListener('submit', function (e) {
+    e.preventDefault();
+    state.runOffset = 0;
+    loadRuns().catch(function (err) { showMessage(err.message); });
+  });
+  $('runs-prev').addEventListener('click', function () {
+    state.runOffset = Math.max(0, state.runOffset - RUN_LIMIT);
+    loadRuns();
+  });
+  $('runs-next').addEventListener('click', function () {
+    state.runOffset += RUN_LIMIT;
+    loadRuns();
+  });
+  $('window').addEventListener('change', function (e) {
+    state.window = Number(e.target.value);
+    renderExecutors();
+  });
+  $('logs-close').addEventListener('click', closeLogs);
+  window.addEventListener('hashchange', switchView);
+
+  switchView();
+  refresh();
+  watchEvents();
+  setInterval(refresh, REFRESH_INTERVAL);
+})();
@@ -0,0 +1,28 @@
+// Package web 内嵌的运维仪表盘：单页静态资源，数据全部来自 /api/v1 管理接口
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// PathPrefix 仪表盘的挂载路径
+const PathPrefix = "/ui/"
+
+//go:embed static
+var static embed.FS
+
+// Handler 返回仪表盘静态资源的处理器，请求路径需已去掉 PathPrefix
+// 资源随二进制发布，升级后浏览器需重新校验，因此禁用缓存
+func Handler() http.Handler {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	files := http.FileServer(http.FS(assets))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		files.ServeHTTP(w, r)
+	})
+}